@@ -37,10 +37,16 @@ func main() {
 	})
 
 	// Initialize QR generator
-	generator := qrgen.NewGenerator(qrgen.DefaultConfig())
+	genConfig := qrgen.DefaultConfig()
+	if level, err := qrgen.ParseRecoveryLevel(cfg.QRDefaultRecovery); err == nil {
+		genConfig.DefaultRecovery = level
+	} else {
+		log.Printf("Invalid QR_DEFAULT_RECOVERY %q, using M: %v", cfg.QRDefaultRecovery, err)
+	}
+	generator := qrgen.NewGenerator(genConfig)
 
 	// Initialize handlers
-	qrHandler := handlers.NewQRHandler(generator, qrCache, cfg.CacheEnabled)
+	qrHandler := handlers.NewQRHandler(generator, qrCache, cfg.CacheEnabled, cfg.QRDefaultSize, cfg.QRMaxSize)
 	bankHandler := handlers.NewBankHandler()
 	healthHandler := handlers.NewHealthHandler(qrCache)
 
@@ -101,14 +107,24 @@ func main() {
 		v1.GET("/banks", bankHandler.ListBanks)
 		v1.GET("/banks/search", bankHandler.SearchBanks)
 		v1.GET("/banks/:identifier", bankHandler.GetBank)
+		v1.GET("/banks/:identifier/monogram", bankHandler.GetBankMonogram)
+
+		// Payment schemes (VietQR, PromptPay, KHQR)
+		v1.GET("/schemes", qrHandler.ListSchemes)
 
 		// QR generation endpoints
 		v1.POST("/generate", qrHandler.Generate)
 		v1.GET("/quick", qrHandler.QuickGenerate)
-		v1.GET("/qr/:bank_bin/:account_number", qrHandler.GenerateImage)
+		v1.GET("/qr/:bank_bin/:account_number", qrHandler.GenerateImage) // .png, .png8, .jpg, .webp or .svg
+
+		// Printable sheet with one QR per variant (e.g., per table)
+		v1.POST("/sheet", qrHandler.Sheet)
 
 		// QR decode endpoint
 		v1.POST("/decode", qrHandler.Decode)
+
+		// QR rewrite endpoint (edit amount/message of an existing payload)
+		v1.POST("/rewrite", qrHandler.Rewrite)
 	}
 
 	// Legacy/simple endpoints for compatibility
@@ -27,10 +27,8 @@ func (h *BankHandler) ListBanks(c *gin.Context) {
 	})
 }
 
-// GetBank handles GET /api/v1/banks/:identifier
-func (h *BankHandler) GetBank(c *gin.Context) {
-	identifier := c.Param("identifier")
-
+// findBank looks a bank up by BIN, code or short name
+func findBank(identifier string) *vietqr.Bank {
 	// Try to find by BIN first
 	bank := vietqr.GetBankByBIN(identifier)
 
@@ -44,6 +42,12 @@ func (h *BankHandler) GetBank(c *gin.Context) {
 		bank = vietqr.GetBankByShortName(identifier)
 	}
 
+	return bank
+}
+
+// GetBank handles GET /api/v1/banks/:identifier
+func (h *BankHandler) GetBank(c *gin.Context) {
+	bank := findBank(c.Param("identifier"))
 	if bank == nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
@@ -59,6 +63,32 @@ func (h *BankHandler) GetBank(c *gin.Context) {
 	})
 }
 
+// GetBankMonogram handles GET /api/v1/banks/:identifier/monogram
+func (h *BankHandler) GetBankMonogram(c *gin.Context) {
+	bank := findBank(c.Param("identifier"))
+	if bank == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "bank_not_found",
+			"message": "Bank not found. Use BIN code, bank code, or short name.",
+		})
+		return
+	}
+
+	monogram := vietqr.BankMonogram(bank.BIN)
+	if monogram == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "monogram_not_found",
+			"message": "No monogram is available for this bank",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, "image/png", monogram)
+}
+
 // SearchBanks handles GET /api/v1/banks/search
 func (h *BankHandler) SearchBanks(c *gin.Context) {
 	query := strings.ToLower(c.Query("q"))
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/qrgen"
+)
+
+// rasterFormats maps format parameter values and file extensions to raster
+// encodings
+var rasterFormats = map[string]qrgen.QRFormat{
+	"png":  qrgen.FormatPNG,
+	"png8": qrgen.FormatPNG8,
+	"jpeg": qrgen.FormatJPEG,
+	"jpg":  qrgen.FormatJPEG,
+	"webp": qrgen.FormatWebP,
+}
+
+// acceptFormats maps the Accept media types that select a raster encoding
+var acceptFormats = map[string]qrgen.QRFormat{
+	"image/png":  qrgen.FormatPNG,
+	"image/webp": qrgen.FormatWebP,
+	"image/jpeg": qrgen.FormatJPEG,
+}
+
+// rasterFormat resolves a format parameter to a raster encoding. An empty
+// value or "image" negotiates the encoding from the Accept header; ok is
+// false for non-raster formats (svg, pdf, json, ...).
+func rasterFormat(c *gin.Context, format string) (qrgen.QRFormat, bool) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" || format == "image" {
+		return acceptedRaster(c), true
+	}
+	f, ok := rasterFormats[format]
+	return f, ok
+}
+
+// acceptedRaster returns the raster encoding the client prefers among PNG,
+// WebP and JPEG, by q-value then order. Only explicitly named types count:
+// wildcards keep the PNG default, since */* clients expect PNG. The
+// response varies with Accept either way.
+func acceptedRaster(c *gin.Context) qrgen.QRFormat {
+	c.Header("Vary", "Accept")
+
+	best, bestQ := qrgen.FormatPNG, 0.0
+	for _, part := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		f, ok := acceptFormats[mediaType]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	return best
+}
+
+// serveImageData writes encoded image bytes with the browser cache headers
+func serveImageData(c *gin.Context, format qrgen.QRFormat, imgData []byte) {
+	contentType := format.ContentType()
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Length", strconv.Itoa(len(imgData)))
+	c.Header("Cache-Control", "public, max-age=300") // 5 minutes browser cache
+	c.Data(http.StatusOK, contentType, imgData)
+}
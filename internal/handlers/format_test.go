@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAcceptNegotiation(t *testing.T) {
+	const imageURL = "/api/v1/qr/970436/1234567890"
+	tests := []struct {
+		path   string
+		accept string
+		want   string
+		vary   bool
+	}{
+		{path: imageURL, want: "image/png", vary: true},
+		{path: imageURL, accept: "image/webp", want: "image/webp", vary: true},
+		{path: imageURL, accept: "image/jpeg", want: "image/jpeg", vary: true},
+		{path: imageURL, accept: "image/jpeg;q=0.9, image/webp;q=0.5", want: "image/jpeg", vary: true},
+		{path: imageURL, accept: "image/png;q=0.5, image/webp", want: "image/webp", vary: true},
+		{path: imageURL, accept: "image/webp, image/jpeg", want: "image/webp", vary: true},
+		{path: imageURL, accept: "image/avif,image/webp,image/apng,image/*,*/*;q=0.8", want: "image/webp", vary: true},
+		{path: imageURL, accept: "*/*", want: "image/png", vary: true},
+		{path: imageURL, accept: "image/*", want: "image/png", vary: true},
+		{path: imageURL, accept: "image/webp;q=0", want: "image/png", vary: true},
+		{path: imageURL, accept: "image/webp;q=abc, image/jpeg;q=0.1", want: "image/jpeg", vary: true},
+		{path: imageURL, accept: "text/html, image/avif", want: "image/png", vary: true},
+		{path: imageURL + "?format=image", accept: "image/webp", want: "image/webp", vary: true},
+		{path: "/api/v1/quick?bank=970436&account=1234567890", accept: "image/webp", want: "image/webp", vary: true},
+
+		// An extension or format parameter overrides Accept
+		{path: imageURL + ".png", accept: "image/webp", want: "image/png"},
+		{path: imageURL + ".jpg", accept: "image/webp", want: "image/jpeg"},
+		{path: imageURL + "?format=webp", accept: "image/jpeg", want: "image/webp"},
+		{path: "/api/v1/quick?bank=970436&account=1234567890&format=png", accept: "image/webp", want: "image/png"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := serve(req)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s (Accept %q): status %d: %s", tt.path, tt.accept, w.Code, w.Body)
+			continue
+		}
+		if got := w.Header().Get("Content-Type"); got != tt.want {
+			t.Errorf("%s (Accept %q): Content-Type %q, want %q", tt.path, tt.accept, got, tt.want)
+		}
+		if got := http.DetectContentType(w.Body.Bytes()); got != tt.want {
+			t.Errorf("%s (Accept %q): body is %s, want %s", tt.path, tt.accept, got, tt.want)
+		}
+		if vary := w.Header().Get("Vary") == "Accept"; vary != tt.vary {
+			t.Errorf("%s (Accept %q): Vary %q, want Accept = %v", tt.path, tt.accept, w.Header().Get("Vary"), tt.vary)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/qrgen"
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// Values of the logo parameter
+const (
+	logoNone     = "none"     // Plain code (default)
+	logoMonogram = "monogram" // The bank's embedded placeholder monogram
+	logoBank     = "bank"     // The bank's official logo, which is not bundled
+	logoCustom   = "custom"   // Uploaded PNG or SVG (POST /api/v1/generate only)
+)
+
+var (
+	errInvalidLogoMode   = errors.New("logo must be none, monogram or custom")
+	errBankLogoMissing   = errors.New("official bank logos are not bundled; use logo=monogram for the bank's placeholder monogram or logo=custom to upload the logo")
+	errLogoUploadMissing = errors.New("logo=custom requires a PNG or SVG upload (logo_file or logo_data) on POST /api/v1/generate")
+)
+
+// qrLogo is a logo overlaid on the centre of the QR code
+type qrLogo struct {
+	image image.Image
+	key   string // Cache key component; empty for uploads, which are not cached
+}
+
+// bankMonograms caches decoded bank monograms by BIN
+var bankMonograms sync.Map
+
+// bankMonogram returns the decoded embedded monogram of bank, or nil when it
+// has none
+func bankMonogram(bank *vietqr.Bank) image.Image {
+	if img, ok := bankMonograms.Load(bank.BIN); ok {
+		return img.(image.Image)
+	}
+	data := vietqr.BankMonogram(bank.BIN)
+	if data == nil {
+		return nil
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	bankMonograms.Store(bank.BIN, img)
+	return img
+}
+
+// resolveLogo returns the logo selected by mode: the bank's monogram, the
+// decoded upload (custom) or none. An upload without a mode selects custom;
+// banks without a monogram get the plain code. logo=bank is rejected, since
+// no official bank logos are bundled.
+func resolveLogo(mode string, bank *vietqr.Bank, upload []byte) (*qrLogo, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = logoNone
+		if upload != nil {
+			mode = logoCustom
+		}
+	}
+
+	switch mode {
+	case logoNone:
+		return nil, nil
+	case logoMonogram:
+		img := bankMonogram(bank)
+		if img == nil {
+			return nil, nil
+		}
+		return &qrLogo{image: img, key: "monogram:" + bank.BIN}, nil
+	case logoBank:
+		return nil, errBankLogoMissing
+	case logoCustom:
+		if upload == nil {
+			return nil, errLogoUploadMissing
+		}
+		img, err := qrgen.DecodeLogo(upload)
+		if err != nil {
+			return nil, err
+		}
+		return &qrLogo{image: img}, nil
+	}
+	return nil, errInvalidLogoMode
+}
+
+// readLogoUpload returns the logo uploaded in the logo_file multipart field
+// or the base64 logo_data field (a data: URL is accepted), or nil when the
+// request has neither
+func readLogoUpload(c *gin.Context, logoData string) ([]byte, error) {
+	if file, err := c.FormFile("logo_file"); err == nil {
+		if file.Size > qrgen.MaxLogoBytes {
+			return nil, qrgen.ErrLogoTooLarge
+		}
+		f, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(io.LimitReader(f, qrgen.MaxLogoBytes+1))
+	} else if !errors.Is(err, http.ErrMissingFile) && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, err
+	}
+
+	if logoData == "" {
+		return nil, nil
+	}
+	if _, data, ok := strings.Cut(logoData, ";base64,"); ok {
+		logoData = data
+	}
+	data, err := base64.StdEncoding.DecodeString(logoData)
+	if err != nil {
+		return nil, qrgen.ErrInvalidLogo
+	}
+	return data, nil
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLogoAndTemplateFormats(t *testing.T) {
+	const quick = "/api/v1/quick?bank=970436&account=1234567890"
+	tests := []struct {
+		path  string
+		field string // Error field; empty for success
+		want  string // Marker of the embedded logo on success
+	}{
+		{quick + "&logo=monogram&format=svg", "", "<image "},
+		{quick + "&logo=monogram&format=pdf", "", "/Subtype /Image"},
+		{"/api/v1/qr/970436/1234567890.svg?logo=monogram", "", "<image "},
+		{quick + "&logo=monogram&format=zpl", "logo", ""},
+		{quick + "&logo=monogram&format=escpos", "logo", ""},
+		{quick + "&template=card&format=svg", "template", ""},
+		{quick + "&template=compact&format=pdf", "template", ""},
+		{quick + "&template=card&format=zpl", "template", ""},
+		{"/api/v1/qr/970436/1234567890.svg?template=card", "template", ""},
+		{quick + "&logo=monogram", "", "\x89PNG"},
+		{quick + "&logo=bank", "logo", ""},
+		{"/api/v1/qr/970436/1234567890.png?logo=bank", "logo", ""},
+	}
+	for _, tt := range tests {
+		w := get(tt.path)
+		if tt.field == "" {
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("%s: status %d, logo marker %q missing", tt.path, w.Code, tt.want)
+			}
+			continue
+		}
+		var resp struct {
+			Field string `json:"field"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Field != tt.field {
+			t.Errorf("%s: status %d, body %s; want 400 on %s", tt.path, w.Code, w.Body, tt.field)
+		}
+	}
+
+	// POST /generate reports the same errors
+	for body, field := range map[string]string{
+		`{"bank_bin":"970436","account_number":"1234567890","template":"card","format":"svg"}`: "template",
+		`{"bank_bin":"970436","account_number":"1234567890","logo":"monogram","format":"zpl"}`: "logo",
+		`{"bank_bin":"970436","account_number":"1234567890","logo":"bank"}`:                    "logo",
+	} {
+		w := postJSON("/api/v1/generate", body)
+		var resp GenerateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Field != field {
+			t.Errorf("%s: status %d, body %s; want 400 on %s", body, w.Code, w.Body, field)
+		}
+	}
+	w := postJSON("/api/v1/generate", `{"bank_bin":"970436","account_number":"1234567890","logo":"monogram","format":"svg"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<image ") {
+		t.Errorf("POST logo=monogram svg: status %d, no embedded logo", w.Code)
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/qrgen"
+)
+
+// imageParams are the request parameters that shape the rendered code
+type imageParams struct {
+	FG, BG string
+	Border *int
+	Size   string
+	DPI    int
+	ECC    string
+
+	// Styled rendering
+	Shape, Eye, EyeColor string
+	Gradient, GradientTo string
+	GradientAngle        float64
+}
+
+// imageParams returns the image parameters of a generate request
+func (req *GenerateRequest) imageParams() imageParams {
+	return imageParams{
+		FG: req.FG, BG: req.BG, Border: req.Border, Size: req.Size, DPI: req.DPI, ECC: req.ECC,
+		Shape: req.Shape, Eye: req.Eye, EyeColor: req.EyeColor,
+		Gradient: req.Gradient, GradientTo: req.GradientTo, GradientAngle: req.GradientAngle,
+	}
+}
+
+// parseImageOptions applies the image parameters to the generator defaults
+// and rejects colour pairs and styles that do not scan. It returns the
+// offending field name on error.
+func (h *QRHandler) parseImageOptions(p imageParams) (qrgen.GenerateOptions, string, error) {
+	opts := h.generator.DefaultOptions("", h.defaultSize)
+	fg, bg, border, size, dpi := p.FG, p.BG, p.Border, p.Size, p.DPI
+
+	if p.ECC != "" {
+		level, err := qrgen.ParseRecoveryLevel(p.ECC)
+		if err != nil {
+			return opts, "ecc", err
+		}
+		opts.RecoveryLevel = level
+	}
+	if dpi != 0 && (dpi < qrgen.MinDPI || dpi > qrgen.MaxDPI) {
+		return opts, "dpi", qrgen.ErrInvalidDPI
+	}
+	if size != "" {
+		var physical bool
+		var err error
+		if opts.Size, physical, err = qrgen.ParseSizeDPI(size, dpi, h.maxSize); err != nil {
+			return opts, "size", err
+		}
+		if physical && dpi == 0 {
+			dpi = qrgen.DefaultDPI
+		}
+	}
+	// Physical sizes and explicit resolutions are recorded in the PNG
+	opts.DPI = dpi
+
+	var err error
+	if fg != "" {
+		if opts.ForegroundColor, err = qrgen.ParseColor(fg); err != nil {
+			return opts, "fg", err
+		}
+	}
+	if bg != "" {
+		if opts.BackgroundColor, err = qrgen.ParseColor(bg); err != nil {
+			return opts, "bg", err
+		}
+	}
+	if border != nil {
+		if *border < 0 || *border > qrgen.MaxQuietZone {
+			return opts, "border", qrgen.ErrInvalidQuietZone
+		}
+		opts.QuietZone = *border
+	}
+
+	if fg != "" || bg != "" {
+		if err := qrgen.CheckContrast(opts.ForegroundColor, opts.BackgroundColor); err != nil {
+			field := "fg"
+			if fg == "" {
+				field = "bg"
+			}
+			return opts, field, err
+		}
+	}
+
+	style, field, err := parseStyle(p)
+	if err != nil {
+		return opts, field, err
+	}
+	opts.Style = style
+	if err := opts.Style.Check(opts.BackgroundColor); err != nil {
+		switch {
+		case errors.Is(err, qrgen.ErrUnscannableEye):
+			return opts, "eye_color", err
+		case errors.Is(err, qrgen.ErrInvalidGradientAngle):
+			return opts, "gradient_angle", err
+		}
+		return opts, "gradient_to", err
+	}
+	return opts, "", nil
+}
+
+// parseStyle builds the module style from the styling parameters, or nil
+// when none are set
+func parseStyle(p imageParams) (*qrgen.Style, string, error) {
+	if p.Shape == "" && p.Eye == "" && p.EyeColor == "" && p.Gradient == "" && p.GradientTo == "" {
+		return nil, "", nil
+	}
+
+	var style qrgen.Style
+	var err error
+	if style.Shape, err = qrgen.ParseModuleShape(p.Shape); err != nil {
+		return nil, "shape", err
+	}
+	if style.Eye, err = qrgen.ParseEyeStyle(p.Eye); err != nil {
+		return nil, "eye", err
+	}
+	if p.EyeColor != "" {
+		if style.EyeColor, err = qrgen.ParseColor(p.EyeColor); err != nil {
+			return nil, "eye_color", err
+		}
+	}
+	if style.Gradient, err = qrgen.ParseGradient(p.Gradient); err != nil {
+		return nil, "gradient", err
+	}
+	if p.GradientTo != "" {
+		if style.GradientTo, err = qrgen.ParseColor(p.GradientTo); err != nil {
+			return nil, "gradient_to", err
+		}
+		// An end colour alone selects a linear gradient
+		if p.Gradient == "" {
+			style.Gradient = qrgen.GradientLinear
+		}
+	}
+	style.GradientAngle = p.GradientAngle
+	return &style, "", nil
+}
+
+// parseImageQuery reads the image parameters from the query string
+func (h *QRHandler) parseImageQuery(c *gin.Context) (qrgen.GenerateOptions, string, error) {
+	border, err := queryBorder(c)
+	if err != nil {
+		return qrgen.GenerateOptions{}, "border", err
+	}
+	dpi := 0
+	if s := c.Query("dpi"); s != "" {
+		if dpi, err = strconv.Atoi(s); err != nil {
+			return qrgen.GenerateOptions{}, "dpi", qrgen.ErrInvalidDPI
+		}
+	}
+	angle := 0.0
+	if s := c.Query("gradient_angle"); s != "" {
+		if angle, err = strconv.ParseFloat(s, 64); err != nil {
+			return qrgen.GenerateOptions{}, "gradient_angle", qrgen.ErrInvalidGradientAngle
+		}
+	}
+	return h.parseImageOptions(imageParams{
+		FG:            c.Query("fg"),
+		BG:            c.Query("bg"),
+		Border:        border,
+		Size:          c.Query("size"),
+		DPI:           dpi,
+		ECC:           c.Query("ecc"),
+		Shape:         c.Query("shape"),
+		Eye:           c.Query("eye"),
+		EyeColor:      c.Query("eye_color"),
+		Gradient:      c.Query("gradient"),
+		GradientTo:    c.Query("gradient_to"),
+		GradientAngle: angle,
+	})
+}
+
+// symbolInfo returns the error correction level and QR version an image is
+// rendered with, after any escalation for the logo
+func symbolInfo(opts qrgen.GenerateOptions, style *cardStyle) (string, int) {
+	if style != nil && style.logo != nil {
+		opts.Logo = style.logo.image
+	}
+	level := opts.Recovery()
+	version, _ := qrgen.Version(opts.Content, level)
+	return qrgen.RecoveryLevelName(level), version
+}
+
+// queryBorder parses the optional border query parameter
+func queryBorder(c *gin.Context) (*int, error) {
+	s := c.Query("border")
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, qrgen.ErrInvalidQuietZone
+	}
+	return &n, nil
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/cache"
+	"github.com/maxqr-api/internal/qrgen"
+	"github.com/skip2/go-qrcode"
+)
+
+func TestSymbolRecoveryLevel(t *testing.T) {
+	// cmd/server sets DefaultRecovery from QR_DEFAULT_RECOVERY
+	cfg := qrgen.DefaultConfig()
+	cfg.DefaultRecovery = qrcode.Low
+	h := NewQRHandler(qrgen.NewGenerator(cfg), cache.NewCache(cache.DefaultConfig()), false, 300, 2000)
+	router := gin.New()
+	router.GET("/api/v1/quick", h.QuickGenerate)
+
+	const quick = "/api/v1/quick?bank=970436&account=1234567890&format=json"
+	tests := []struct {
+		query string
+		ecc   string
+	}{
+		{"", "L"},
+		{"&ecc=q", "Q"},
+		{"&logo=monogram", "H"},
+		{"&logo=monogram&ecc=L", "H"},
+		{"&shape=dot", "Q"},
+		{"&gradient_to=%23003366", "Q"},
+		{"&shape=rounded&ecc=H", "H"},
+		{"&eye=circle", "L"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, quick+tt.query, nil))
+		var resp struct {
+			ECC     string `json:"ecc"`
+			Version int    `json:"version"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || resp.ECC != tt.ecc || resp.Version == 0 {
+			t.Errorf("%s: status %d, ecc %q version %d, want ecc %s", tt.query, w.Code, resp.ECC, resp.Version, tt.ecc)
+		}
+	}
+
+	// Printers encode at the configured level too
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/quick?bank=970436&account=1234567890&format=zpl", nil))
+	if !strings.Contains(w.Body.String(), "^FDLA,") {
+		t.Errorf("ZPL label is not at level L:\n%s", w.Body)
+	}
+
+	// The default router uses M
+	if w := get(quick); !strings.Contains(w.Body.String(), `"ecc":"M"`) {
+		t.Errorf("default ecc: %s", w.Body)
+	}
+}
+
+func TestStyleParams(t *testing.T) {
+	const quick = "/api/v1/quick?bank=970436&account=1234567890&format=json"
+	tests := []struct {
+		query string
+		field string // Empty when the style is accepted
+	}{
+		{"&shape=dot&eye=circle&eye_color=%23801020", ""},
+		{"&gradient=radial&gradient_to=%23003366", ""},
+		{"&shape=star", "shape"},
+		{"&eye=diamond", "eye"},
+		{"&gradient=conic&gradient_to=%23003366", "gradient"},
+		{"&gradient=linear", "gradient_to"},
+		{"&gradient_to=%23eeeeee", "gradient_to"},
+		{"&gradient_to=nope", "gradient_to"},
+		{"&gradient_to=%23003366&gradient_angle=abc", "gradient_angle"},
+		{"&eye_color=%23cccccc", "eye_color"},
+		{"&eye_color=%23003366&bg=%23000000&fg=%23000000", "fg"},
+		{"&eye_color=%23ffffff&bg=transparent", "eye_color"},
+	}
+	for _, tt := range tests {
+		w := get(quick + tt.query)
+		var resp struct {
+			Field string `json:"field"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if tt.field == "" && w.Code != http.StatusOK || tt.field != "" && (w.Code != http.StatusBadRequest || resp.Field != tt.field) {
+			t.Errorf("%s: status %d, body %s; want field %q", tt.query, w.Code, w.Body, tt.field)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/qrgen"
+	"github.com/maxqr-api/internal/scheme"
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// servePDF serves a print-ready PDF with the QR code, drawn with the image
+// options in opts, and its payment details. The file is named after the
+// scheme ID (e.g., "paynow.pdf").
+func (h *QRHandler) servePDF(c *gin.Context, opts qrgen.GenerateOptions, page, schemeID, title string, lines []qrgen.PDFLine) {
+	pageSize, err := qrgen.ParsePageSize(page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_page",
+			"field":   "page",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	pdf, err := h.generator.GeneratePDF(qrgen.PDFOptions{
+		QR:    opts,
+		Page:  pageSize,
+		Title: title,
+		Lines: lines,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "generation_failed",
+			"message": "Failed to generate PDF",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+schemeID+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// transferLines lays out the details of a VietQR transfer for printing
+func transferLines(bank *vietqr.Bank, details *TransferDetails) []qrgen.PDFLine {
+	lines := []qrgen.PDFLine{{Label: "Ngân hàng", Value: bank.Name}}
+	lines = append(lines, qrgen.PDFLine{Label: "Chủ tài khoản", Value: details.AccountName})
+	if details.CardNumber != "" {
+		lines = append(lines, qrgen.PDFLine{Label: "Số thẻ", Value: details.CardNumber})
+	} else {
+		lines = append(lines, qrgen.PDFLine{Label: "Số tài khoản", Value: details.AccountNumber})
+	}
+	if details.Amount > 0 {
+		lines = append(lines, qrgen.PDFLine{Label: "Số tiền", Value: formatVND(details.Amount)})
+	}
+	return append(lines, qrgen.PDFLine{Label: "Nội dung", Value: details.Message})
+}
+
+// paymentLines lays out the details of a non-VietQR payment for printing
+func paymentLines(p *scheme.Decoded) []qrgen.PDFLine {
+	lines := []qrgen.PDFLine{
+		{Label: "Merchant", Value: p.MerchantName},
+		{Label: "Account", Value: p.Account},
+		{Label: "Bank", Value: p.Bank},
+	}
+	if p.Amount > 0 {
+		lines = append(lines, qrgen.PDFLine{
+			Label: "Amount",
+			Value: strconv.FormatFloat(p.Amount, 'f', -1, 64) + " " + p.Currency,
+		})
+	}
+	return append(lines, qrgen.PDFLine{Label: "Reference", Value: p.Message})
+}
+
+// formatVND formats an amount with dot thousands separators (e.g., "100.000 VND")
+func formatVND(amount int64) string {
+	digits := strconv.FormatInt(amount, 10)
+	var sb strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteRune(d)
+	}
+	return sb.String() + " VND"
+}
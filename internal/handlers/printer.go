@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/qrgen"
+)
+
+// errInvalidPrinterMode is returned for printer_mode values other than native or raster
+var errInvalidPrinterMode = errors.New("printer_mode must be native or raster")
+
+// isPrinterFormat reports whether format selects raw printer output
+func isPrinterFormat(format string) bool {
+	return format == "escpos" || format == "zpl"
+}
+
+// servePrinter serves ESC/POS or ZPL bytes that can be sent to the printer
+// as-is. The file is named after the scheme ID (e.g., "paynow.zpl").
+func (h *QRHandler) servePrinter(c *gin.Context, qr qrgen.GenerateOptions, schemeID, format string, width float64, dpi int, mode string) {
+	opts := qrgen.PrinterOptions{
+		Content:       qr.Content,
+		RecoveryLevel: qr.RecoveryLevel,
+		PaperWidth:    width,
+		DPI:           dpi,
+	}
+	switch strings.ToLower(mode) {
+	case "", "native":
+	case "raster":
+		opts.Raster = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_printer_mode",
+			"field":   "printer_mode",
+			"message": errInvalidPrinterMode.Error(),
+		})
+		return
+	}
+
+	var data []byte
+	var err error
+	if format == "zpl" {
+		data, err = h.generator.GenerateZPL(opts)
+	} else {
+		data, err = h.generator.GenerateESCPOS(opts)
+	}
+	if errors.Is(err, qrgen.ErrInvalidPrinterDPI) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_printer_options",
+			"field":   "dpi",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, qrgen.ErrInvalidPaperWidth) || errors.Is(err, qrgen.ErrPrinterWidthTooSmall) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_printer_options",
+			"field":   "printer_width",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "generation_failed",
+			"message": "Failed to generate printer commands",
+		})
+		return
+	}
+
+	if format == "zpl" {
+		c.Header("Content-Disposition", `attachment; filename="`+schemeID+`.zpl"`)
+		c.Data(http.StatusOK, "text/plain; charset=us-ascii", data)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+schemeID+`.bin"`)
+	c.Data(http.StatusOK, "application/octet-stream", data)
+}
+
+// servePrinterQuery serves printer output configured by the printer_width,
+// dpi and printer_mode query parameters
+func (h *QRHandler) servePrinterQuery(c *gin.Context, opts qrgen.GenerateOptions, schemeID, format string) {
+	width, dpi, field, err := queryPrinter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_printer_options",
+			"field":   field,
+			"message": err.Error(),
+		})
+		return
+	}
+	h.servePrinter(c, opts, schemeID, format, width, dpi, c.Query("printer_mode"))
+}
+
+// queryPrinter parses the optional printer_width and dpi query parameters;
+// zero leaves the printer default
+func queryPrinter(c *gin.Context) (float64, int, string, error) {
+	var width float64
+	var dpi int
+	var err error
+	if s := c.Query("printer_width"); s != "" {
+		if width, err = strconv.ParseFloat(s, 64); err != nil {
+			return 0, 0, "printer_width", qrgen.ErrInvalidPaperWidth
+		}
+	}
+	if s := c.Query("dpi"); s != "" {
+		if dpi, err = strconv.Atoi(s); err != nil {
+			return 0, 0, "dpi", qrgen.ErrInvalidPrinterDPI
+		}
+	}
+	return width, dpi, "", nil
+}
@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"encoding/base64"
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,55 +10,126 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/maxqr-api/internal/cache"
 	"github.com/maxqr-api/internal/qrgen"
+	"github.com/maxqr-api/internal/scheme"
 	"github.com/maxqr-api/internal/vietqr"
 )
 
 // QRHandler handles QR code generation requests
 type QRHandler struct {
-	generator *qrgen.Generator
-	cache     *cache.Cache
-	useCache  bool
+	generator   *qrgen.Generator
+	cache       *cache.Cache
+	useCache    bool
+	defaultSize qrgen.QRSize // Image side when size is omitted (QR_DEFAULT_SIZE)
+	maxSize     int          // Largest image side in pixels (QR_MAX_SIZE)
 }
 
 // NewQRHandler creates a new QR handler
-func NewQRHandler(generator *qrgen.Generator, qrCache *cache.Cache, useCache bool) *QRHandler {
+func NewQRHandler(generator *qrgen.Generator, qrCache *cache.Cache, useCache bool, defaultSize, maxSize int) *QRHandler {
+	if defaultSize <= 0 {
+		defaultSize = int(qrgen.SizeMedium)
+	}
+	if maxSize > 0 && defaultSize > maxSize {
+		defaultSize = maxSize
+	}
 	return &QRHandler{
-		generator: generator,
-		cache:     qrCache,
-		useCache:  useCache,
+		generator:   generator,
+		cache:       qrCache,
+		useCache:    useCache,
+		defaultSize: qrgen.QRSize(defaultSize),
+		maxSize:     maxSize,
 	}
 }
 
 // GenerateRequest represents a QR code generation request
 type GenerateRequest struct {
-	BankBin       string `json:"bank_bin" form:"bank_bin"`             // Bank BIN code (6 digits)
-	BankCode      string `json:"bank_code" form:"bank_code"`           // Bank code (e.g., "VIETCOMBANK")
-	AccountNumber string `json:"account_number" form:"account_number"` // Account number
-	Amount        int64  `json:"amount" form:"amount"`                 // Amount in VND
-	Message       string `json:"message" form:"message"`               // Transfer description
-	AccountName   string `json:"account_name" form:"account_name"`     // Account holder name
-	Size          string `json:"size" form:"size"`                     // QR size: small, medium, large, xlarge
-	Format        string `json:"format" form:"format"`                 // Output format: png, base64, json
-	Editable      bool   `json:"editable" form:"editable"`             // Allow user to edit amount/message when scanning
+	BankBin       string  `json:"bank_bin" form:"bank_bin"`             // Bank BIN code (6 digits)
+	BankCode      string  `json:"bank_code" form:"bank_code"`           // Bank code (e.g., "VIETCOMBANK")
+	AccountNumber string  `json:"account_number" form:"account_number"` // Account number
+	CardNumber    string  `json:"card_number" form:"card_number"`       // NAPAS card number (instead of account_number)
+	Amount        int64   `json:"amount" form:"amount"`                 // Amount in whole units (VND for VietQR)
+	DecimalAmount string  `json:"decimal_amount" form:"decimal_amount"` // Other schemes: amount with minor units as a decimal string (e.g., "12.50")
+	Message       string  `json:"message" form:"message"`               // Transfer description
+	AccountName   string  `json:"account_name" form:"account_name"`     // Account holder name
+	MerchantCity  string  `json:"merchant_city" form:"merchant_city"`   // City (default: Ha Noi)
+	Size          string  `json:"size" form:"size"`                     // QR size: small, medium, large, xlarge, pixels (640) or physical (30mm, 1.5in)
+	Format        string  `json:"format" form:"format"`                 // Output format: png, png8, jpeg, webp, image (from Accept), svg, pdf, escpos, zpl, base64, json
+	Page          string  `json:"page" form:"page"`                     // PDF page: a4 (default), a5, a6 or WIDTHxHEIGHT in mm
+	PrinterWidth  float64 `json:"printer_width" form:"printer_width"`   // escpos/zpl: paper or label width in mm (default 80)
+	DPI           int     `json:"dpi" form:"dpi"`                       // Image resolution for physical sizes and PNG metadata (default 300); escpos/zpl: print head density (default 203)
+	PrinterMode   string  `json:"printer_mode" form:"printer_mode"`     // escpos/zpl: native (default) or raster
+	Template      string  `json:"template" form:"template"`             // png/base64: bare (default), card or compact
+	Theme         string  `json:"theme" form:"theme"`                   // Card theme: vietqr (default), light or dark
+	Logo          string  `json:"logo" form:"logo"`                     // Centre logo: none (default), monogram or custom
+	LogoData      string  `json:"logo_data" form:"logo_data"`           // logo=custom: base64 PNG/SVG (or upload the logo_file field)
+	FG            string  `json:"fg" form:"fg"`                         // png/svg/base64 module colour: hex (#RRGGBB[AA]) or rgba()
+	BG            string  `json:"bg" form:"bg"`                         // png/svg/base64 background colour, or transparent
+	Border        *int    `json:"border" form:"border"`                 // Quiet zone in modules (default 4)
+	ECC           string  `json:"ecc" form:"ecc"`                       // Error correction: L, M, Q or H (default QR_DEFAULT_RECOVERY; H with a logo)
+	Shape         string  `json:"shape" form:"shape"`                   // png/svg/base64 modules: square (default), rounded or dot
+	Eye           string  `json:"eye" form:"eye"`                       // Finder patterns: square (default), rounded or circle
+	EyeColor      string  `json:"eye_color" form:"eye_color"`           // Finder pattern colour (default: module fill)
+	Gradient      string  `json:"gradient" form:"gradient"`             // Module fill: none (default), linear or radial, from fg to gradient_to
+	GradientTo    string  `json:"gradient_to" form:"gradient_to"`       // Gradient end colour
+	GradientAngle float64 `json:"gradient_angle" form:"gradient_angle"` // Linear gradient direction in degrees (0 = left to right)
+	Editable      bool    `json:"editable" form:"editable"`             // Allow user to edit amount/message when scanning
+	TextPolicy    string  `json:"text_policy" form:"text_policy"`       // Unsupported characters: reject (default), replace, strip
+
+	// Additional data (tag 62) for reconciliation, max 25 characters each
+	BillNumber     string `json:"bill_number" form:"bill_number"`
+	MobileNumber   string `json:"mobile_number" form:"mobile_number"`
+	StoreLabel     string `json:"store_label" form:"store_label"`
+	LoyaltyNumber  string `json:"loyalty_number" form:"loyalty_number"`
+	ReferenceLabel string `json:"reference_label" form:"reference_label"`
+	CustomerLabel  string `json:"customer_label" form:"customer_label"`
+	TerminalLabel  string `json:"terminal_label" form:"terminal_label"`
+
+	// Merchant-presented mode (mode=merchant): MCC, tip/convenience fee, postal code
+	Mode       string  `json:"mode" form:"mode"`               // transfer (default) or merchant
+	MCC        string  `json:"mcc" form:"mcc"`                 // ISO 18245 merchant category code
+	Tip        string  `json:"tip" form:"tip"`                 // prompt, fixed or percentage
+	FeeFixed   int64   `json:"fee_fixed" form:"fee_fixed"`     // Fixed convenience fee in VND (tip=fixed)
+	FeePercent float64 `json:"fee_percent" form:"fee_percent"` // Convenience fee percentage (tip=percentage)
+	PostalCode string  `json:"postal_code" form:"postal_code"`
+
+	// Language template (tag 64): name/city with Vietnamese diacritics.
+	// Defaults to account_name/merchant_city when those contain diacritics.
+	AccountNameAlt  string `json:"account_name_alt" form:"account_name_alt"`
+	MerchantCityAlt string `json:"merchant_city_alt" form:"merchant_city_alt"`
+	Language        string `json:"language" form:"language"` // ISO 639-1 code (default: vi)
+
+	// Payment scheme (default vietqr). Other schemes use account_number for
+	// their account identifier and bank_code for the acquiring bank.
+	Scheme      string `json:"scheme" form:"scheme"`             // vietqr, promptpay, khqr, paynow, duitnow
+	Currency    string `json:"currency" form:"currency"`         // ISO 4217 alpha code (default: scheme currency)
+	AccountType string `json:"account_type" form:"account_type"` // Scheme-specific account kind (e.g., mobile, national_id)
+	MerchantID  string `json:"merchant_id" form:"merchant_id"`   // KHQR merchant ID (account_type=merchant)
+	Expiry      string `json:"expiry" form:"expiry"`             // PayNow expiry date, YYYY-MM-DD
 }
 
 // GenerateResponse represents the API response
 type GenerateResponse struct {
-	Success     bool                `json:"success"`
-	QRString    string              `json:"qr_string,omitempty"`
-	QRImageURL  string              `json:"qr_image_url,omitempty"`
-	Base64Image string              `json:"base64_image,omitempty"`
-	Bank        *vietqr.Bank        `json:"bank,omitempty"`
-	Transfer    *TransferDetails    `json:"transfer,omitempty"`
-	Error       string              `json:"error,omitempty"`
+	Success     bool             `json:"success"`
+	QRString    string           `json:"qr_string,omitempty"`
+	QRImageURL  string           `json:"qr_image_url,omitempty"`
+	Base64Image string           `json:"base64_image,omitempty"`
+	Scheme      string           `json:"scheme,omitempty"`
+	Bank        *vietqr.Bank     `json:"bank,omitempty"`
+	Transfer    *TransferDetails `json:"transfer,omitempty"`
+	Payment     *scheme.Decoded  `json:"payment,omitempty"` // Decoded payment for non-VietQR schemes
+	Error       string           `json:"error,omitempty"`
+	Field       string           `json:"field,omitempty"`   // Offending request field on validation errors
+	ECC         string           `json:"ecc,omitempty"`     // Error correction level the code is rendered with
+	Version     int              `json:"version,omitempty"` // QR version (1-40), i.e. the symbol's density
 }
 
 // TransferDetails contains transfer information
 type TransferDetails struct {
-	AccountNumber string `json:"account_number"`
-	AccountName   string `json:"account_name,omitempty"`
-	Amount        int64  `json:"amount"`
-	Message       string `json:"message,omitempty"`
+	TransferMethod string `json:"transfer_method"` // account or card
+	AccountNumber  string `json:"account_number,omitempty"`
+	CardNumber     string `json:"card_number,omitempty"` // Masked card number
+	AccountName    string `json:"account_name,omitempty"`
+	Amount         int64  `json:"amount"`
+	Message        string `json:"message,omitempty"`
 }
 
 // Generate handles POST /api/v1/generate
@@ -74,53 +145,153 @@ func (h *QRHandler) Generate(c *gin.Context) {
 		return
 	}
 
+	// PromptPay, KHQR and other schemes have their own account model
+	if !isVietQR(req.Scheme) {
+		h.generateScheme(c, &req)
+		return
+	}
+
 	// Validate and get bank info
 	bank, err := h.resolveBank(req.BankBin, req.BankCode)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, GenerateResponse{
 			Success: false,
 			Error:   err.Error(),
+			Field:   errorField(err),
+		})
+		return
+	}
+
+	// Validate account or card number
+	number, method, err := resolveTransferTarget(bank.BIN, req.AccountNumber, req.CardNumber)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   errorField(err),
+		})
+		return
+	}
+
+	textPolicy, err := vietqr.ParseTextPolicy(req.TextPolicy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   "text_policy",
+		})
+		return
+	}
+
+	style, field, err := parseCardStyle(req.Template, req.Theme)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   field,
+		})
+		return
+	}
+
+	// Centre logo: the bank's placeholder monogram or an uploaded PNG/SVG
+	upload, err := readLogoUpload(c, req.LogoData)
+	var logo *qrLogo
+	if err == nil {
+		logo, err = resolveLogo(req.Logo, bank, upload)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   "logo",
 		})
 		return
 	}
+	style = style.withLogo(logo)
 
-	// Validate account number
-	if req.AccountNumber == "" {
+	opts, field, err := h.parseImageOptions(req.imageParams())
+	if err != nil {
 		c.JSON(http.StatusBadRequest, GenerateResponse{
 			Success: false,
-			Error:   "Account number is required",
+			Error:   err.Error(),
+			Field:   field,
 		})
 		return
 	}
 
-	// Validate amount (must be non-negative)
+	// Validate amount (non-negative whole VND)
 	if req.Amount < 0 {
 		c.JSON(http.StatusBadRequest, GenerateResponse{
 			Success: false,
-			Error:   "Amount must be non-negative",
+			Error:   "Amount must be a non-negative whole number of VND",
+			Field:   "amount",
 		})
 		return
 	}
+	if req.DecimalAmount != "" {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   "VND has no minor unit; send the whole amount in amount",
+			Field:   "decimal_amount",
+		})
+		return
+	}
+	amount := req.Amount
+
+	info := vietqr.TransferInfo{
+		BankBin:        bank.BIN,
+		AccountNumber:  number,
+		Amount:         amount,
+		Message:        req.Message,
+		MerchantName:   req.AccountName,
+		MerchantCity:   req.MerchantCity,
+		IsDynamic:      amount > 0 && !req.Editable,
+		Editable:       req.Editable,
+		TransferMethod: method,
+		BillNumber:     req.BillNumber,
+		MobileNumber:   req.MobileNumber,
+		StoreLabel:     req.StoreLabel,
+		LoyaltyNumber:  req.LoyaltyNumber,
+		ReferenceLabel: req.ReferenceLabel,
+		CustomerLabel:  req.CustomerLabel,
+		TerminalLabel:  req.TerminalLabel,
+
+		LanguagePreference: req.Language,
+		MerchantNameAlt:    req.AccountNameAlt,
+		MerchantCityAlt:    req.MerchantCityAlt,
+		TextPolicy:         textPolicy,
+	}
 
-	// Validate message length
-	if len(req.Message) > 50 {
+	// Keep diacritics in tag 64 when the ASCII tag 59/60 values lose them
+	if info.MerchantNameAlt == "" && vietqr.HasDiacritics(req.AccountName) {
+		info.MerchantNameAlt = req.AccountName
+	}
+	if info.MerchantCityAlt == "" && vietqr.HasDiacritics(req.MerchantCity) {
+		info.MerchantCityAlt = req.MerchantCity
+	}
+
+	// Merchant-presented mode
+	merchant, err := buildMerchantPayment(&req)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, GenerateResponse{
 			Success: false,
-			Error:   "Message must not exceed 50 characters",
+			Error:   err.Error(),
+			Field:   errorField(err),
 		})
 		return
 	}
+	info.Merchant = merchant
 
-	// Generate VietQR string
-	qrString := vietqr.Encode(vietqr.TransferInfo{
-		BankBin:       bank.BIN,
-		AccountNumber: req.AccountNumber,
-		Amount:        req.Amount,
-		Message:       req.Message,
-		MerchantName:  req.AccountName,
-		IsDynamic:     req.Amount > 0 && !req.Editable,
-		Editable:      req.Editable,
-	})
+	// Generate VietQR string (every field checked against NAPAS/EMVCo limits)
+	qrString, err := encodeTransfer(info)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   errorField(err),
+		})
+		return
+	}
 
 	// Determine output format
 	format := strings.ToLower(req.Format)
@@ -128,27 +299,44 @@ func (h *QRHandler) Generate(c *gin.Context) {
 		format = "json"
 	}
 
-	// Parse size
-	size := qrgen.ParseSize(req.Size)
+	opts.Content = qrString
 
 	response := GenerateResponse{
 		Success:  true,
 		QRString: qrString,
 		Bank:     bank,
-		Transfer: &TransferDetails{
-			AccountNumber: req.AccountNumber,
-			AccountName:   req.AccountName,
-			Amount:        req.Amount,
-			Message:       req.Message,
-		},
+		Transfer: newTransferDetails(number, method, req.AccountName, amount, req.Message),
+	}
+
+	if raster, ok := rasterFormat(c, format); ok {
+		opts.Format = raster
+		h.serveCard(c, opts, style, cardInfo(bank, response.Transfer))
+		return
+	}
+
+	if format == "svg" || format == "pdf" || isPrinterFormat(format) {
+		if field, err := style.applyTo(format, &opts); err != nil {
+			c.JSON(http.StatusBadRequest, GenerateResponse{
+				Success: false,
+				Error:   err.Error(),
+				Field:   field,
+			})
+			return
+		}
 	}
 
 	switch format {
-	case "png":
-		h.servePNG(c, qrString, size)
+	case "svg":
+		h.serveSVG(c, opts)
+		return
+	case "pdf":
+		h.servePDF(c, opts, req.Page, scheme.VietQR, "VietQR", transferLines(bank, response.Transfer))
+		return
+	case "escpos", "zpl":
+		h.servePrinter(c, opts, scheme.VietQR, format, req.PrinterWidth, req.DPI, req.PrinterMode)
 		return
 	case "base64":
-		imgData, err := h.getOrGenerateQR(qrString, size)
+		imgData, err := h.getOrGenerateCard(opts, style, cardInfo(bank, response.Transfer))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, GenerateResponse{
 				Success: false,
@@ -159,32 +347,87 @@ func (h *QRHandler) Generate(c *gin.Context) {
 		response.Base64Image = "data:image/png;base64," + encodeBase64(imgData)
 	}
 
+	response.ECC, response.Version = symbolInfo(opts, style)
 	c.JSON(http.StatusOK, response)
 }
 
 // GenerateImage handles GET /api/v1/qr/:bank_bin/:account_number.png
+// (also .svg, .png8, .jpg, .jpeg and .webp)
 func (h *QRHandler) GenerateImage(c *gin.Context) {
 	bankBin := c.Param("bank_bin")
 	accountNumber := c.Param("account_number")
 
-	// Remove .png extension if present
-	accountNumber = strings.TrimSuffix(accountNumber, ".png")
+	// The extension selects the image format, then the format parameter,
+	// then the Accept header (PNG by default)
+	format := c.Query("format")
+	if i := strings.LastIndexByte(accountNumber, '.'); i >= 0 {
+		if ext := strings.ToLower(accountNumber[i+1:]); ext == "svg" || rasterFormats[ext] != "" {
+			format, accountNumber = ext, accountNumber[:i]
+		}
+	}
+	svg := strings.EqualFold(format, "svg")
+	raster, ok := rasterFormat(c, format)
+	if !svg && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_format",
+			"field":   "format",
+			"message": "format must be png, png8, jpeg, webp or svg",
+		})
+		return
+	}
 
 	// Get query parameters
 	amountStr := c.DefaultQuery("amount", "0")
 	message := c.DefaultQuery("message", "")
-	sizeStr := c.DefaultQuery("size", "medium")
 	editableStr := c.DefaultQuery("editable", "false")
 
+	// method=card treats the path segment as a card number
+	var cardNumber string
+	pathMethod, err := vietqr.ParseTransferMethod(c.Query("method"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_method",
+			"message": err.Error(),
+		})
+		return
+	}
+	if pathMethod == vietqr.TransferToCard {
+		cardNumber, accountNumber = accountNumber, ""
+	}
+
 	// Parse amount
 	amount, err := strconv.ParseInt(amountStr, 10, 64)
-	if err != nil {
-		amount = 0
+	if err != nil || amount < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_amount",
+			"field":   "amount",
+			"message": "amount must be a non-negative whole number of VND",
+		})
+		return
 	}
 
 	// Parse editable
 	editable := editableStr == "true" || editableStr == "1"
 
+	textPolicy, err := vietqr.ParseTextPolicy(c.Query("text_policy"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_text_policy",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	style, field, err := parseCardStyle(c.Query("template"), c.Query("theme"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_template",
+			"field":   field,
+			"message": err.Error(),
+		})
+		return
+	}
+
 	// Validate bank
 	bank := vietqr.GetBankByBIN(bankBin)
 	if bank == nil {
@@ -199,46 +442,131 @@ func (h *QRHandler) GenerateImage(c *gin.Context) {
 		return
 	}
 
+	logo, err := resolveLogo(c.Query("logo"), bank, nil)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_logo",
+			"field":   "logo",
+			"message": err.Error(),
+		})
+		return
+	}
+	style = style.withLogo(logo)
+
+	opts, field, err := h.parseImageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_image_options",
+			"field":   field,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	number, method, err := resolveTransferTarget(bank.BIN, accountNumber, cardNumber)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_account",
+			"field":   errorField(err),
+			"message": err.Error(),
+		})
+		return
+	}
+
 	// Generate QR string
-	qrString := vietqr.Encode(vietqr.TransferInfo{
-		BankBin:       bank.BIN,
-		AccountNumber: accountNumber,
-		Amount:        amount,
-		Message:       message,
-		IsDynamic:     amount > 0 && !editable,
-		Editable:      editable,
+	qrString, err := encodeTransfer(vietqr.TransferInfo{
+		BankBin:        bank.BIN,
+		AccountNumber:  number,
+		Amount:         amount,
+		Message:        message,
+		IsDynamic:      amount > 0 && !editable,
+		Editable:       editable,
+		TransferMethod: method,
+		TextPolicy:     textPolicy,
 	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_field",
+			"field":   errorField(err),
+			"message": err.Error(),
+		})
+		return
+	}
 
-	// Parse size
-	size := qrgen.ParseSize(sizeStr)
+	opts.Content = qrString
+	opts.Format = raster
 
-	h.servePNG(c, qrString, size)
+	if svg {
+		if field, err := style.applyTo(format, &opts); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_" + field,
+				"field":   field,
+				"message": err.Error(),
+			})
+			return
+		}
+		h.serveSVG(c, opts)
+		return
+	}
+	h.serveCard(c, opts, style, cardInfo(bank, newTransferDetails(number, method, "", amount, message)))
 }
 
 // QuickGenerate handles GET /api/v1/quick with query params
 func (h *QRHandler) QuickGenerate(c *gin.Context) {
 	bankBin := c.Query("bank")
 	accountNumber := c.Query("account")
+	cardNumber := c.Query("card")
 	amountStr := c.DefaultQuery("amount", "0")
 	message := c.DefaultQuery("message", "")
-	format := c.DefaultQuery("format", "png")
-	sizeStr := c.DefaultQuery("size", "medium")
+	format := c.Query("format") // Empty negotiates the image format from Accept
 	editableStr := c.DefaultQuery("editable", "false")
 
-	if bankBin == "" || accountNumber == "" {
+	if !isVietQR(c.Query("scheme")) {
+		h.quickScheme(c)
+		return
+	}
+
+	if bankBin == "" || (accountNumber == "" && cardNumber == "") {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "missing_params",
-			"message": "bank and account parameters are required",
+			"message": "bank and account (or card) parameters are required",
 		})
 		return
 	}
 
 	// Parse amount
-	amount, _ := strconv.ParseInt(amountStr, 10, 64)
+	amount, err := strconv.ParseInt(amountStr, 10, 64)
+	if err != nil || amount < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_amount",
+			"field":   "amount",
+			"message": "amount must be a non-negative whole number of VND",
+		})
+		return
+	}
 
 	// Parse editable
 	editable := editableStr == "true" || editableStr == "1"
 
+	textPolicy, err := vietqr.ParseTextPolicy(c.Query("text_policy"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_text_policy",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	style, field, err := parseCardStyle(c.Query("template"), c.Query("theme"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_template",
+			"field":   field,
+			"message": err.Error(),
+		})
+		return
+	}
+
 	// Resolve bank
 	bank := vietqr.GetBankByBIN(bankBin)
 	if bank == nil {
@@ -255,25 +583,90 @@ func (h *QRHandler) QuickGenerate(c *gin.Context) {
 		return
 	}
 
+	logo, err := resolveLogo(c.Query("logo"), bank, nil)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_logo",
+			"field":   "logo",
+			"message": err.Error(),
+		})
+		return
+	}
+	style = style.withLogo(logo)
+
+	opts, field, err := h.parseImageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_image_options",
+			"field":   field,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	number, method, err := resolveTransferTarget(bank.BIN, accountNumber, cardNumber)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_account",
+			"field":   errorField(err),
+			"message": err.Error(),
+		})
+		return
+	}
+
 	// Generate QR string
-	qrString := vietqr.Encode(vietqr.TransferInfo{
-		BankBin:       bank.BIN,
-		AccountNumber: accountNumber,
-		Amount:        amount,
-		Message:       message,
-		IsDynamic:     amount > 0 && !editable,
-		Editable:      editable,
+	qrString, err := encodeTransfer(vietqr.TransferInfo{
+		BankBin:        bank.BIN,
+		AccountNumber:  number,
+		Amount:         amount,
+		Message:        message,
+		IsDynamic:      amount > 0 && !editable,
+		Editable:       editable,
+		TransferMethod: method,
+		TextPolicy:     textPolicy,
 	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_field",
+			"field":   errorField(err),
+			"message": err.Error(),
+		})
+		return
+	}
 
-	size := qrgen.ParseSize(sizeStr)
+	opts.Content = qrString
+	details := newTransferDetails(number, method, "", amount, message)
 
-	if format == "png" || format == "image" {
-		h.servePNG(c, qrString, size)
+	if raster, ok := rasterFormat(c, format); ok {
+		opts.Format = raster
+		h.serveCard(c, opts, style, cardInfo(bank, details))
+		return
+	}
+	if format == "svg" || format == "pdf" || isPrinterFormat(format) {
+		if field, err := style.applyTo(format, &opts); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_" + field,
+				"field":   field,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+	if format == "svg" {
+		h.serveSVG(c, opts)
+		return
+	}
+	if format == "pdf" {
+		h.servePDF(c, opts, c.Query("page"), scheme.VietQR, "VietQR", transferLines(bank, details))
+		return
+	}
+	if isPrinterFormat(format) {
+		h.servePrinterQuery(c, opts, scheme.VietQR, format)
 		return
 	}
 
 	// Return JSON with base64
-	imgData, err := h.getOrGenerateQR(qrString, size)
+	imgData, err := h.getOrGenerateCard(opts, style, cardInfo(bank, details))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "generation_failed",
@@ -282,11 +675,15 @@ func (h *QRHandler) QuickGenerate(c *gin.Context) {
 		return
 	}
 
+	ecc, version := symbolInfo(opts, style)
 	c.JSON(http.StatusOK, gin.H{
 		"success":   true,
 		"qr_string": qrString,
 		"base64":    "data:image/png;base64," + encodeBase64(imgData),
 		"bank":      bank,
+		"transfer":  details,
+		"ecc":       ecc,
+		"version":   version,
 	})
 }
 
@@ -294,6 +691,8 @@ func (h *QRHandler) QuickGenerate(c *gin.Context) {
 func (h *QRHandler) Decode(c *gin.Context) {
 	var req struct {
 		QRString string `json:"qr_string" form:"qr_string"`
+		Strict   bool   `json:"strict" form:"strict"` // Reject payloads with any structural error
+		Scheme   string `json:"scheme" form:"scheme"` // vietqr, promptpay, khqr, paynow, duitnow, qris; detected when empty
 	}
 
 	if err := c.ShouldBind(&req); err != nil || req.QRString == "" {
@@ -304,11 +703,32 @@ func (h *QRHandler) Decode(c *gin.Context) {
 		return
 	}
 
+	if strings.TrimSpace(req.Scheme) == "" {
+		req.Scheme = scheme.Detect(req.QRString)
+	}
+	if !isVietQR(req.Scheme) {
+		h.decodeScheme(c, req.Scheme, req.QRString, req.Strict)
+		return
+	}
+
+	// Structural diagnostics are always computed so support staff can see
+	// why a payload is rejected; strict mode turns them into a failure
+	diagnostics := vietqr.Validate(req.QRString)
+	if req.Strict && diagnostics != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation_failed",
+			"message": diagnostics.Error(),
+			"errors":  diagnostics,
+		})
+		return
+	}
+
 	decoded, err := vietqr.Decode(req.QRString)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "decode_failed",
 			"message": err.Error(),
+			"errors":  diagnostics,
 		})
 		return
 	}
@@ -316,26 +736,181 @@ func (h *QRHandler) Decode(c *gin.Context) {
 	// Get bank info
 	bank := vietqr.GetBankByBIN(decoded.BankBin)
 
+	// Card numbers are never echoed back in full, including in the TLV tree
+	decoded.MaskCard()
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
-			"bank_bin":       decoded.BankBin,
-			"bank":           bank,
-			"account_number": decoded.AccountNumber,
-			"amount":         decoded.Amount,
-			"message":        decoded.Message,
-			"currency":       decoded.Currency,
-			"country":        decoded.Country,
-			"merchant_name":  decoded.MerchantName,
-			"merchant_city":  decoded.MerchantCity,
-			"is_valid":       decoded.IsValid,
+			"network":           decoded.Network,
+			"network_name":      decoded.NetworkName,
+			"merchant_accounts": decoded.MerchantAccounts,
+			"bank_bin":          decoded.BankBin,
+			"bank":              bank,
+			"account_number":    decoded.AccountNumber,
+			"transfer_method":   vietqr.TransferMethodName(decoded.TransferMethod),
+			"service_code":      decoded.TransferMethod,
+			"amount":            decoded.Amount,
+			"message":           decoded.Message,
+			"bill_number":       decoded.BillNumber,
+			"mobile_number":     decoded.MobileNumber,
+			"store_label":       decoded.StoreLabel,
+			"loyalty_number":    decoded.LoyaltyNumber,
+			"reference_label":   decoded.ReferenceLabel,
+			"customer_label":    decoded.CustomerLabel,
+			"terminal_label":    decoded.TerminalLabel,
+			"merchant":          decoded.Merchant,
+			"currency":          decoded.Currency,
+			"country":           decoded.Country,
+			"merchant_name":     decoded.MerchantName,
+			"merchant_city":     decoded.MerchantCity,
+			"merchant_name_alt": decoded.MerchantNameAlt,
+			"merchant_city_alt": decoded.MerchantCityAlt,
+			"language":          decoded.LanguagePreference,
+			"is_valid":          decoded.IsValid,
+			"structure_error":   decoded.StructureError,
+			"tlv":               decoded.Tree.Nodes,
+			"diagnostics":       diagnostics,
 		},
 	})
 }
 
-// servePNG serves a PNG image response
-func (h *QRHandler) servePNG(c *gin.Context, qrString string, size qrgen.QRSize) {
-	imgData, err := h.getOrGenerateQR(qrString, size)
+// RewriteRequest represents a request to edit an existing VietQR payload.
+// Omitted fields leave the corresponding tag untouched.
+type RewriteRequest struct {
+	QRString   string  `json:"qr_string" form:"qr_string"`     // Payload to edit
+	Amount     *int64  `json:"amount" form:"amount"`           // New amount; 0 removes it
+	Message    *string `json:"message" form:"message"`         // New purpose (tag 62.08); "" removes it
+	Dynamic    *bool   `json:"dynamic" form:"dynamic"`         // true for single-use (12), false for static (11)
+	Size       string  `json:"size" form:"size"`               // QR size: preset, pixels or physical (see GenerateRequest)
+	ECC        string  `json:"ecc" form:"ecc"`                 // Error correction: L, M, Q or H
+	Format     string  `json:"format" form:"format"`           // Output format: json (default), png, png8, jpeg, webp or svg
+	TextPolicy string  `json:"text_policy" form:"text_policy"` // Unsupported characters: reject (default), replace, strip
+}
+
+// Rewrite handles POST /api/v1/rewrite
+func (h *QRHandler) Rewrite(c *gin.Context) {
+	var req RewriteRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	qrString := strings.TrimSpace(req.QRString)
+	if qrString == "" {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   "qr_string is required",
+			Field:   "qr_string",
+		})
+		return
+	}
+
+	textPolicy, err := vietqr.ParseTextPolicy(req.TextPolicy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   "text_policy",
+		})
+		return
+	}
+
+	// Edit the TLV tree in place so bank-specific fields survive untouched
+	rewritten, err := vietqr.Rewrite(qrString, vietqr.RewriteOptions{
+		Amount:     req.Amount,
+		Message:    req.Message,
+		Dynamic:    req.Dynamic,
+		TextPolicy: textPolicy,
+	})
+	if err != nil {
+		field := errorField(err)
+		if field == "" {
+			field = "qr_string"
+		}
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   field,
+		})
+		return
+	}
+
+	opts, field, err := h.parseImageOptions(imageParams{Size: req.Size, ECC: req.ECC})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   field,
+		})
+		return
+	}
+	opts.Content = rewritten
+
+	switch format := strings.ToLower(strings.TrimSpace(req.Format)); format {
+	case "", "json":
+	case "svg":
+		h.serveSVG(c, opts)
+		return
+	default:
+		raster, ok := rasterFormat(c, format)
+		if !ok {
+			c.JSON(http.StatusBadRequest, GenerateResponse{
+				Success: false,
+				Error:   "format must be json, png, png8, jpeg, webp or svg",
+				Field:   "format",
+			})
+			return
+		}
+		opts.Format = raster
+		h.servePNG(c, opts)
+		return
+	}
+
+	response := GenerateResponse{
+		Success:  true,
+		QRString: rewritten,
+	}
+
+	if decoded, err := vietqr.Decode(rewritten); err == nil {
+		response.Bank = vietqr.GetBankByBIN(decoded.BankBin)
+		response.Transfer = newTransferDetails(decoded.AccountNumber, decoded.TransferMethod, decoded.MerchantName, decoded.Amount, decoded.Message)
+	}
+
+	imgData, err := h.getOrGenerateQR(opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GenerateResponse{
+			Success: false,
+			Error:   "Failed to generate QR image",
+		})
+		return
+	}
+	response.Base64Image = "data:image/png;base64," + encodeBase64(imgData)
+	response.ECC, response.Version = symbolInfo(opts, nil)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// servePNG serves a raster image response in opts.Format (PNG by default)
+func (h *QRHandler) servePNG(c *gin.Context, opts qrgen.GenerateOptions) {
+	imgData, err := h.getOrGenerateQR(opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "generation_failed",
+			"message": "Failed to generate QR code",
+		})
+		return
+	}
+
+	serveImageData(c, opts.Format, imgData)
+}
+
+// serveSVG serves an SVG image response
+func (h *QRHandler) serveSVG(c *gin.Context, opts qrgen.GenerateOptions) {
+	svg, err := h.generator.GenerateSVGWithOptions(opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "generation_failed",
@@ -344,15 +919,13 @@ func (h *QRHandler) servePNG(c *gin.Context, qrString string, size qrgen.QRSize)
 		return
 	}
 
-	c.Header("Content-Type", "image/png")
-	c.Header("Content-Length", strconv.Itoa(len(imgData)))
-	c.Header("Cache-Control", "public, max-age=300") // 5 minutes browser cache
-	c.Data(http.StatusOK, "image/png", imgData)
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", []byte(svg))
 }
 
 // getOrGenerateQR gets from cache or generates new QR
-func (h *QRHandler) getOrGenerateQR(qrString string, size qrgen.QRSize) ([]byte, error) {
-	cacheKey := qrgen.ContentHash(qrString, size)
+func (h *QRHandler) getOrGenerateQR(opts qrgen.GenerateOptions) ([]byte, error) {
+	cacheKey := opts.CacheKey()
 
 	// Try cache first
 	if h.useCache {
@@ -362,7 +935,7 @@ func (h *QRHandler) getOrGenerateQR(qrString string, size qrgen.QRSize) ([]byte,
 	}
 
 	// Generate new QR
-	data, err := h.generator.GeneratePNG(qrString, size)
+	data, err := h.generator.GenerateRasterWithOptions(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -375,6 +948,13 @@ func (h *QRHandler) getOrGenerateQR(qrString string, size qrgen.QRSize) ([]byte,
 	return data, nil
 }
 
+var (
+	errUnknownBank     = errors.New("unknown bank")
+	errBankRequired    = errors.New("required unless bank_code is set")
+	errAccountRequired = errors.New("required unless card_number is set")
+	errAccountAndCard  = errors.New("cannot be combined with account_number")
+)
+
 // resolveBank resolves bank from BIN or code
 func (h *QRHandler) resolveBank(bankBin, bankCode string) (*vietqr.Bank, error) {
 	var bank *vietqr.Bank
@@ -382,7 +962,7 @@ func (h *QRHandler) resolveBank(bankBin, bankCode string) (*vietqr.Bank, error)
 	if bankBin != "" {
 		bank = vietqr.GetBankByBIN(bankBin)
 		if bank == nil {
-			return nil, fmt.Errorf("invalid bank BIN: %s", bankBin)
+			return nil, &vietqr.FieldError{Field: "bank_bin", Tag: "38.01.00", Err: errUnknownBank}
 		}
 	} else if bankCode != "" {
 		bank = vietqr.GetBankByCode(strings.ToUpper(bankCode))
@@ -390,15 +970,144 @@ func (h *QRHandler) resolveBank(bankBin, bankCode string) (*vietqr.Bank, error)
 			bank = vietqr.GetBankByShortName(bankCode)
 		}
 		if bank == nil {
-			return nil, fmt.Errorf("invalid bank code: %s", bankCode)
+			return nil, &vietqr.FieldError{Field: "bank_code", Tag: "38.01.00", Err: errUnknownBank}
 		}
 	} else {
-		return nil, fmt.Errorf("bank_bin or bank_code is required")
+		return nil, &vietqr.FieldError{Field: "bank_bin", Tag: "38.01.00", Err: errBankRequired}
 	}
 
 	return bank, nil
 }
 
+// apiFieldNames maps vietqr field names to the request parameter that feeds them
+var apiFieldNames = map[string]string{
+	"merchant_name":     "account_name",
+	"merchant_name_alt": "account_name_alt",
+}
+
+// encodeTransfer generates the VietQR string with strict field validation.
+// Field errors are reported under the request parameter names.
+func encodeTransfer(info vietqr.TransferInfo) (string, error) {
+	qrString, err := vietqr.EncodeStrict(info)
+	if err != nil {
+		return "", mapFieldError(err)
+	}
+	return qrString, nil
+}
+
+// mapFieldError renames the field of a *vietqr.FieldError to the request
+// parameter that feeds it
+func mapFieldError(err error) error {
+	var fieldErr *vietqr.FieldError
+	if errors.As(err, &fieldErr) {
+		if name, ok := apiFieldNames[fieldErr.Field]; ok {
+			mapped := *fieldErr
+			mapped.Field = name
+			return &mapped
+		}
+	}
+	return err
+}
+
+// errorField returns the request field a validation error refers to, if any
+func errorField(err error) string {
+	var fieldErr *vietqr.FieldError
+	if errors.As(err, &fieldErr) {
+		return fieldErr.Field
+	}
+	return ""
+}
+
+// resolveTransferTarget picks the account or card number to encode and
+// returns it together with the matching NAPAS service code. Account numbers
+// are normalized and checked against the bank's account rule.
+func resolveTransferTarget(bin, accountNumber, cardNumber string) (string, string, error) {
+	if cardNumber == "" {
+		if accountNumber == "" {
+			return "", "", &vietqr.FieldError{Field: "account_number", Tag: "38.01.01", Err: errAccountRequired}
+		}
+		account := vietqr.NormalizeAccountNumber(bin, accountNumber)
+		if err := vietqr.ValidateAccountNumber(bin, account); err != nil {
+			return "", "", &vietqr.FieldError{Field: "account_number", Tag: "38.01.01", Err: err}
+		}
+		return account, vietqr.TransferToAccount, nil
+	}
+
+	if accountNumber != "" {
+		return "", "", &vietqr.FieldError{Field: "card_number", Tag: "38.01.01", Err: errAccountAndCard}
+	}
+
+	card := vietqr.NormalizeCardNumber(cardNumber)
+	if err := vietqr.ValidateCardNumber(card); err != nil {
+		return "", "", &vietqr.FieldError{Field: "card_number", Tag: "38.01.01", Err: err}
+	}
+	return card, vietqr.TransferToCard, nil
+}
+
+var (
+	errInvalidMode  = errors.New("mode must be transfer or merchant")
+	errMerchantOnly = errors.New("requires mode=merchant")
+)
+
+// buildMerchantPayment validates the merchant-mode fields of a generate request.
+// It returns nil for a plain transfer QR.
+func buildMerchantPayment(req *GenerateRequest) (*vietqr.MerchantPayment, error) {
+	switch strings.ToLower(req.Mode) {
+	case "", "transfer":
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"mcc", req.MCC != ""},
+			{"tip", req.Tip != ""},
+			{"fee_fixed", req.FeeFixed != 0},
+			{"fee_percent", req.FeePercent != 0},
+			{"postal_code", req.PostalCode != ""},
+		} {
+			if f.set {
+				return nil, &vietqr.FieldError{Field: f.name, Err: errMerchantOnly}
+			}
+		}
+		return nil, nil
+	case "merchant":
+	default:
+		return nil, &vietqr.FieldError{Field: "mode", Err: errInvalidMode}
+	}
+
+	tip, err := vietqr.ParseTipIndicator(req.Tip)
+	if err != nil {
+		return nil, &vietqr.FieldError{Field: "tip", Tag: vietqr.TagTipIndicator, Err: err}
+	}
+
+	merchant := &vietqr.MerchantPayment{
+		CategoryCode:       req.MCC,
+		TipIndicator:       tip,
+		ConvenienceFee:     req.FeeFixed,
+		ConveniencePercent: req.FeePercent,
+		PostalCode:         req.PostalCode,
+	}
+	if err := vietqr.ValidateMerchant(*merchant); err != nil {
+		return nil, err
+	}
+	return merchant, nil
+}
+
+// newTransferDetails builds the transfer section of a response, masking card numbers
+func newTransferDetails(number, method, accountName string, amount int64, message string) *TransferDetails {
+	details := &TransferDetails{
+		TransferMethod: vietqr.TransferMethodName(method),
+		AccountName:    accountName,
+		Amount:         amount,
+		Message:        message,
+	}
+	if method == vietqr.TransferToCard {
+		details.CardNumber = vietqr.MaskCardNumber(number)
+	} else {
+		details.AccountNumber = number
+	}
+	return details
+}
+
 // encodeBase64 encodes bytes to base64 string using optimized standard library
 func encodeBase64(data []byte) string {
 	return base64.StdEncoding.EncodeToString(data)
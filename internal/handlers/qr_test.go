@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/cache"
+	"github.com/maxqr-api/internal/qrgen"
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// newTestRouter wires the QR routes the way cmd/server does
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewQRHandler(qrgen.NewGenerator(qrgen.DefaultConfig()), cache.NewCache(cache.DefaultConfig()), false, 300, 2000)
+
+	router := gin.New()
+	v1 := router.Group("/api/v1")
+	v1.POST("/generate", h.Generate)
+	v1.GET("/quick", h.QuickGenerate)
+	v1.GET("/qr/:bank_bin/:account_number", h.GenerateImage)
+	v1.POST("/sheet", h.Sheet)
+	v1.POST("/decode", h.Decode)
+	v1.POST("/rewrite", h.Rewrite)
+	return router
+}
+
+// serve runs req against the test router
+func serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	newTestRouter().ServeHTTP(w, req)
+	return w
+}
+
+// postForm sends a form-encoded POST
+func postForm(path string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return serve(req)
+}
+
+// postJSON sends a JSON POST
+func postJSON(path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return serve(req)
+}
+
+// get sends a GET
+func get(path string) *httptest.ResponseRecorder {
+	return serve(httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+func TestDecodeMasksCardNumber(t *testing.T) {
+	const card = "9704361234567890"
+	qr := vietqr.Encode(vietqr.TransferInfo{
+		BankBin:        "970436",
+		AccountNumber:  card,
+		TransferMethod: vietqr.TransferToCard,
+	})
+
+	w := postForm("/api/v1/decode", url.Values{"qr_string": {qr}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), card) {
+		t.Errorf("response echoes the card number: %s", w.Body)
+	}
+
+	var resp struct {
+		Data struct {
+			AccountNumber string            `json:"account_number"`
+			TLV           []*vietqr.TLVNode `json:"tlv"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	masked := vietqr.MaskCardNumber(card)
+	if resp.Data.AccountNumber != masked {
+		t.Errorf("account_number = %q, want %q", resp.Data.AccountNumber, masked)
+	}
+	tree := &vietqr.TLVTree{Nodes: resp.Data.TLV}
+	if got := tree.Get("38", "01", "01"); got != masked {
+		t.Errorf("tlv 38.01.01 = %q, want %q", got, masked)
+	}
+
+	// Account transfers are echoed unchanged
+	qr = vietqr.Encode(vietqr.TransferInfo{BankBin: "970436", AccountNumber: "1234567890"})
+	w = postForm("/api/v1/decode", url.Values{"qr_string": {qr}})
+	if !strings.Contains(w.Body.String(), `"account_number":"1234567890"`) {
+		t.Errorf("account transfer response = %s", w.Body)
+	}
+}
+
+func TestMerchantModeErrorFields(t *testing.T) {
+	const target = `"bank_bin":"970436","account_number":"1234567890"`
+	tests := []struct {
+		body  string
+		field string
+	}{
+		{`{` + target + `,"mcc":"5812"}`, "mcc"},
+		{`{` + target + `,"mode":"transfer","fee_fixed":1000}`, "fee_fixed"},
+		{`{` + target + `,"postal_code":"700000"}`, "postal_code"},
+		{`{` + target + `,"mode":"shop"}`, "mode"},
+		{`{` + target + `,"mode":"merchant","mcc":"5812","tip":"sometimes"}`, "tip"},
+	}
+
+	for _, tt := range tests {
+		w := postJSON("/api/v1/generate", tt.body)
+		var resp GenerateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Field != tt.field {
+			t.Errorf("%s: status %d, body %s; want 400 on %s", tt.body, w.Code, w.Body, tt.field)
+		}
+	}
+}
+
+func TestTransferErrorFields(t *testing.T) {
+	tests := []struct {
+		body  string
+		field string
+	}{
+		{`{"account_number":"1234567890"}`, "bank_bin"},
+		{`{"bank_bin":"999999","account_number":"1234567890"}`, "bank_bin"},
+		{`{"bank_code":"NOPE","account_number":"1234567890"}`, "bank_code"},
+		{`{"bank_bin":"970436"}`, "account_number"},
+		{`{"bank_bin":"970436","account_number":"1234567890","card_number":"9704361234567890"}`, "card_number"},
+		{`{"bank_bin":"970436","card_number":"1234"}`, "card_number"},
+	}
+
+	for _, tt := range tests {
+		w := postJSON("/api/v1/generate", tt.body)
+		var resp GenerateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Field != tt.field {
+			t.Errorf("%s: status %d, body %s; want 400 on %s", tt.body, w.Code, w.Body, tt.field)
+			continue
+		}
+		if !strings.HasPrefix(resp.Error, tt.field+": ") {
+			t.Errorf("%s: error %q does not name %s", tt.body, resp.Error, tt.field)
+		}
+	}
+
+	// The image endpoint rejects amounts it cannot parse instead of dropping them
+	w := get("/api/v1/qr/970436/1234567890?amount=12abc")
+	var resp struct {
+		Error string `json:"error"`
+		Field string `json:"field"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusBadRequest || resp.Error != "invalid_amount" || resp.Field != "amount" {
+		t.Errorf("amount=12abc: status %d, body %s; want 400 invalid_amount on amount", w.Code, w.Body)
+	}
+}
+
+func TestRewriteFormats(t *testing.T) {
+	qr := vietqr.Encode(vietqr.TransferInfo{BankBin: "970436", AccountNumber: "1234567890"})
+
+	tests := []struct {
+		format      string
+		status      int
+		contentType string
+	}{
+		{"", http.StatusOK, "application/json"},
+		{"svg", http.StatusOK, "image/svg+xml"},
+		{"webp", http.StatusOK, "image/webp"},
+		{"pdf", http.StatusBadRequest, "application/json"},
+		{"escpos", http.StatusBadRequest, "application/json"},
+		{"gif", http.StatusBadRequest, "application/json"},
+	}
+
+	for _, tt := range tests {
+		w := postForm("/api/v1/rewrite", url.Values{"qr_string": {qr}, "amount": {"50000"}, "format": {tt.format}})
+		if w.Code != tt.status || !strings.HasPrefix(w.Header().Get("Content-Type"), tt.contentType) {
+			t.Errorf("format=%q: status %d, Content-Type %q; want %d, %s", tt.format, w.Code, w.Header().Get("Content-Type"), tt.status, tt.contentType)
+		}
+		if tt.status == http.StatusBadRequest && !strings.Contains(w.Body.String(), `"field":"format"`) {
+			t.Errorf("format=%q: body = %s, want field format", tt.format, w.Body)
+		}
+	}
+
+	tampered := strings.Replace(qr, "1234567890", "1234567899", 1)
+	w := postForm("/api/v1/rewrite", url.Values{"qr_string": {tampered}, "amount": {"50000"}})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "crc_mismatch") {
+		t.Errorf("tampered payload: status %d, body %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/qrgen"
+	"github.com/maxqr-api/internal/scheme"
+)
+
+// isVietQR reports whether a scheme parameter selects the built-in VietQR flow
+func isVietQR(id string) bool {
+	id = strings.ToLower(strings.TrimSpace(id))
+	return id == "" || id == scheme.VietQR
+}
+
+// ListSchemes handles GET /api/v1/schemes
+func (h *QRHandler) ListSchemes(c *gin.Context) {
+	schemes := scheme.All()
+	data := make([]gin.H, 0, len(schemes))
+	for _, s := range schemes {
+		data = append(data, gin.H{
+			"id":         s.ID(),
+			"name":       s.Name(),
+			"country":    s.Country(),
+			"currencies": s.Currencies(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"count":   len(data),
+		"data":    data,
+	})
+}
+
+// generateScheme handles POST /api/v1/generate for non-VietQR schemes.
+// Whole amounts come in amount like VietQR; amounts with minor units come
+// as a decimal string in decimal_amount so they never pass through a float.
+func (h *QRHandler) generateScheme(c *gin.Context, req *GenerateRequest) {
+	if req.DecimalAmount == "" {
+		amount := ""
+		if req.Amount != 0 {
+			amount = strconv.FormatInt(req.Amount, 10)
+		}
+		h.serveScheme(c, req, amount, "amount")
+		return
+	}
+	if req.Amount != 0 {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   "set either amount or decimal_amount, not both",
+			Field:   "decimal_amount",
+		})
+		return
+	}
+	h.serveScheme(c, req, req.DecimalAmount, "decimal_amount")
+}
+
+// vietQROnlyParam returns the first parameter set in req (or uploaded as
+// logo_file) that only the VietQR flow supports, or ""
+func vietQROnlyParam(c *gin.Context, req *GenerateRequest) string {
+	switch {
+	case req.Logo != "" && !strings.EqualFold(req.Logo, logoNone):
+		return "logo"
+	case req.LogoData != "":
+		return "logo_data"
+	case req.Template != "" && !strings.EqualFold(req.Template, string(qrgen.TemplateBare)):
+		return "template"
+	case req.Theme != "":
+		return "theme"
+	case req.TextPolicy != "":
+		return "text_policy"
+	}
+	if _, err := c.FormFile("logo_file"); err == nil {
+		return "logo_file"
+	}
+	return ""
+}
+
+// serveScheme encodes and serves a non-VietQR payment. amount is a decimal
+// string in major units and amountField the parameter it came from, used
+// when reporting an invalid amount. Templates, logos and text policies are
+// rejected rather than ignored: they are only implemented for VietQR.
+func (h *QRHandler) serveScheme(c *gin.Context, req *GenerateRequest, amount, amountField string) {
+	s, err := scheme.Get(req.Scheme)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   "scheme",
+		})
+		return
+	}
+	if param := vietQROnlyParam(c, req); param != "" {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   param + " is only supported for VietQR",
+			Field:   param,
+		})
+		return
+	}
+
+	var expiry time.Time
+	if req.Expiry != "" {
+		if expiry, err = time.Parse("2006-01-02", req.Expiry); err != nil {
+			c.JSON(http.StatusBadRequest, GenerateResponse{
+				Success: false,
+				Error:   "expiry must be a date in YYYY-MM-DD format",
+				Field:   "expiry",
+			})
+			return
+		}
+	}
+
+	qrString, err := s.Encode(scheme.Payment{
+		Account:      req.AccountNumber,
+		AccountType:  req.AccountType,
+		Bank:         req.BankCode,
+		Amount:       amount,
+		Currency:     req.Currency,
+		MerchantName: req.AccountName,
+		MerchantCity: req.MerchantCity,
+		MerchantID:   req.MerchantID,
+		Message:      req.Message,
+		BillNumber:   req.BillNumber,
+		Editable:     req.Editable,
+		Expiry:       expiry,
+	})
+	if err != nil {
+		err = mapFieldError(err)
+		field := errorField(err)
+		if field == "amount" {
+			field = amountField
+		}
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   field,
+		})
+		return
+	}
+
+	opts, field, err := h.parseImageOptions(req.imageParams())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   field,
+		})
+		return
+	}
+	opts.Content = qrString
+
+	response := GenerateResponse{
+		Success:  true,
+		QRString: qrString,
+		Scheme:   s.ID(),
+	}
+	if decoded, err := s.Decode(qrString); err == nil {
+		decoded.Tree = nil
+		response.Payment = decoded
+	}
+
+	format := strings.ToLower(req.Format)
+	if format != "" {
+		if raster, ok := rasterFormat(c, format); ok {
+			opts.Format = raster
+			h.servePNG(c, opts)
+			return
+		}
+	}
+
+	switch format {
+	case "svg":
+		h.serveSVG(c, opts)
+		return
+	case "pdf":
+		var lines []qrgen.PDFLine
+		if response.Payment != nil {
+			lines = paymentLines(response.Payment)
+		}
+		h.servePDF(c, opts, req.Page, s.ID(), s.Name(), lines)
+		return
+	case "escpos", "zpl":
+		h.servePrinter(c, opts, s.ID(), format, req.PrinterWidth, req.DPI, req.PrinterMode)
+		return
+	case "base64":
+		imgData, err := h.getOrGenerateQR(opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, GenerateResponse{
+				Success: false,
+				Error:   "Failed to generate QR image",
+			})
+			return
+		}
+		response.Base64Image = "data:image/png;base64," + encodeBase64(imgData)
+	}
+
+	response.ECC, response.Version = symbolInfo(opts, nil)
+	c.JSON(http.StatusOK, response)
+}
+
+// quickScheme handles GET /api/v1/quick for non-VietQR schemes. The query
+// parameters mirror the generate request; format defaults to the image
+// format negotiated from Accept (PNG).
+func (h *QRHandler) quickScheme(c *gin.Context) {
+	printerWidth, dpi, field, err := queryPrinter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_printer_options",
+			"field":   field,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	border, err := queryBorder(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_image_options",
+			"field":   "border",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	gradientAngle := 0.0
+	if s := c.Query("gradient_angle"); s != "" {
+		if gradientAngle, err = strconv.ParseFloat(s, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_image_options",
+				"field":   "gradient_angle",
+				"message": qrgen.ErrInvalidGradientAngle.Error(),
+			})
+			return
+		}
+	}
+
+	format := strings.ToLower(c.Query("format"))
+	switch format {
+	case "", "image":
+		format = string(acceptedRaster(c))
+	case "json":
+		format = "base64"
+	}
+
+	h.serveScheme(c, &GenerateRequest{
+		Scheme:        c.Query("scheme"),
+		AccountNumber: c.Query("account"),
+		AccountType:   c.Query("type"),
+		BankCode:      c.Query("bank"),
+		Currency:      c.Query("currency"),
+		AccountName:   c.Query("name"),
+		MerchantCity:  c.Query("city"),
+		MerchantID:    c.Query("merchant_id"),
+		Message:       c.Query("message"),
+		BillNumber:    c.Query("bill_number"),
+		Expiry:        c.Query("expiry"),
+		Size:          c.Query("size"),
+		Format:        format,
+		Page:          c.Query("page"),
+		PrinterWidth:  printerWidth,
+		DPI:           dpi,
+		PrinterMode:   c.Query("printer_mode"),
+		ECC:           c.Query("ecc"),
+		FG:            c.Query("fg"),
+		BG:            c.Query("bg"),
+		Border:        border,
+		Shape:         c.Query("shape"),
+		Eye:           c.Query("eye"),
+		EyeColor:      c.Query("eye_color"),
+		Gradient:      c.Query("gradient"),
+		GradientTo:    c.Query("gradient_to"),
+		GradientAngle: gradientAngle,
+		Editable:      c.Query("editable") == "true" || c.Query("editable") == "1",
+		Template:      c.Query("template"),
+		Theme:         c.Query("theme"),
+		Logo:          c.Query("logo"),
+		TextPolicy:    c.Query("text_policy"),
+	}, c.Query("amount"), "amount")
+}
+
+// decodeScheme handles POST /api/v1/decode for non-VietQR schemes
+func (h *QRHandler) decodeScheme(c *gin.Context, id, qrString string, strict bool) {
+	s, err := scheme.Get(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_scheme",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	diagnostics := s.Validate(qrString)
+	if strict && diagnostics != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "validation_failed",
+			"message": diagnostics.Error(),
+			"errors":  diagnostics,
+		})
+		return
+	}
+
+	decoded, err := s.Decode(qrString)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "decode_failed",
+			"message": err.Error(),
+			"errors":  diagnostics,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"scheme":      s.ID(),
+		"data":        decoded,
+		"compliant":   diagnostics == nil,
+		"diagnostics": diagnostics,
+	})
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQuickRejectsUnparsableNumbers(t *testing.T) {
+	tests := []struct {
+		query string
+		field string
+	}{
+		{"bank=970436&account=1234567890&amount=abc", "amount"},
+		{"bank=970436&account=1234567890&amount=-5", "amount"},
+		{"bank=970436&account=1234567890&format=escpos&printer_width=abc", "printer_width"},
+		{"bank=970436&account=1234567890&format=zpl&dpi=abc", "dpi"},
+		{"scheme=promptpay&account=0812345678&amount=abc", "amount"},
+		{"scheme=promptpay&account=0812345678&amount=1.005", "amount"},
+		{"scheme=promptpay&account=0812345678&format=escpos&printer_width=abc", "printer_width"},
+		{"scheme=promptpay&account=0812345678&format=zpl&dpi=abc", "dpi"},
+	}
+
+	for _, tt := range tests {
+		w := get("/api/v1/quick?" + tt.query)
+		var resp struct {
+			Field string `json:"field"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Field != tt.field {
+			t.Errorf("%s: status %d, body %s; want 400 on %s", tt.query, w.Code, w.Body, tt.field)
+		}
+	}
+}
+
+func TestGenerateSchemeAmounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   string // Tag 54 in the payload, or the error field
+	}{
+		{"decimal", `{"scheme":"promptpay","account_number":"0812345678","decimal_amount":"100.50"}`, http.StatusOK, "5406100.50"},
+		{"whole", `{"scheme":"promptpay","account_number":"0812345678","amount":100}`, http.StatusOK, "5406100.00"},
+		{"KHR whole", `{"scheme":"khqr","account_number":"coffee@aclb","account_name":"Coffee","amount":5000}`, http.StatusOK, "54045000"},
+		{"too precise", `{"scheme":"promptpay","account_number":"0812345678","decimal_amount":"1.005"}`, http.StatusBadRequest, "decimal_amount"},
+		{"both", `{"scheme":"promptpay","account_number":"0812345678","amount":1,"decimal_amount":"1.50"}`, http.StatusBadRequest, "decimal_amount"},
+		{"negative", `{"scheme":"promptpay","account_number":"0812345678","amount":-1}`, http.StatusBadRequest, "amount"},
+		{"VietQR decimal", `{"bank_bin":"970436","account_number":"1234567890","decimal_amount":"10.5"}`, http.StatusBadRequest, "decimal_amount"},
+		{"VietQR", `{"bank_bin":"970436","account_number":"1234567890","amount":9999999999999}`, http.StatusOK, "54139999999999999"},
+	}
+
+	for _, tt := range tests {
+		w := postJSON("/api/v1/generate", tt.body)
+		var resp GenerateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, body %s", tt.name, w.Code, w.Body)
+			continue
+		}
+		if tt.status == http.StatusOK && !strings.Contains(resp.QRString, tt.want) {
+			t.Errorf("%s: qr_string %s, want tag 54 %s", tt.name, resp.QRString, tt.want)
+		}
+		if tt.status != http.StatusOK && resp.Field != tt.want {
+			t.Errorf("%s: field %q, want %q", tt.name, resp.Field, tt.want)
+		}
+	}
+
+	// A fractional JSON amount no longer binds to the whole-unit field
+	if w := postJSON("/api/v1/generate", `{"bank_bin":"970436","account_number":"1234567890","amount":10.5}`); w.Code != http.StatusBadRequest {
+		t.Errorf("fractional amount: status %d, want 400", w.Code)
+	}
+}
+
+func TestPDFFilenameNamesScheme(t *testing.T) {
+	tests := map[string]string{
+		"bank=970436&account=1234567890&format=pdf":      "vietqr.pdf",
+		"scheme=paynow&account=91234567&format=pdf":      "paynow.pdf",
+		"scheme=promptpay&account=0812345678&format=pdf": "promptpay.pdf",
+	}
+	for query, name := range tests {
+		w := get("/api/v1/quick?" + query)
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "%PDF") {
+			t.Errorf("%s: status %d, body %.40q", query, w.Code, w.Body)
+			continue
+		}
+		want := `inline; filename="` + name + `"`
+		if got := w.Header().Get("Content-Disposition"); got != want {
+			t.Errorf("%s: Content-Disposition %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestPrinterFilenameNamesScheme(t *testing.T) {
+	tests := map[string]string{
+		"bank=970436&account=1234567890&format=zpl":         "vietqr.zpl",
+		"bank=970436&account=1234567890&format=escpos":      "vietqr.bin",
+		"scheme=paynow&account=91234567&format=zpl":         "paynow.zpl",
+		"scheme=promptpay&account=0812345678&format=escpos": "promptpay.bin",
+	}
+	for query, name := range tests {
+		w := get("/api/v1/quick?" + query)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status %d, body %s", query, w.Code, w.Body)
+			continue
+		}
+		want := `attachment; filename="` + name + `"`
+		if got := w.Header().Get("Content-Disposition"); got != want {
+			t.Errorf("%s: Content-Disposition %q, want %q", query, got, want)
+		}
+	}
+
+	w := postJSON("/api/v1/generate", `{"scheme":"khqr","account_number":"coffee@aclb","account_name":"Coffee","format":"zpl"}`)
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="khqr.zpl"` {
+		t.Errorf("generate khqr: status %d, Content-Disposition %q", w.Code, got)
+	}
+}
+
+func TestSchemeRejectsVietQROnlyParams(t *testing.T) {
+	const quick = "/api/v1/quick?scheme=khqr&account=coffee@aclb&name=Coffee"
+	for query, field := range map[string]string{
+		"&logo=monogram":     "logo",
+		"&logo=custom":       "logo",
+		"&template=card":     "template",
+		"&theme=dark":        "theme",
+		"&text_policy=strip": "text_policy",
+	} {
+		w := get(quick + query)
+		var resp GenerateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Field != field {
+			t.Errorf("%s: status %d, body %s; want 400 on %s", query, w.Code, w.Body, field)
+		}
+	}
+
+	// The defaults are accepted, and bill_number reaches the payload
+	w := get(quick + "&logo=none&template=bare&bill_number=INV7&format=json")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"bill_number":"INV7"`) {
+		t.Errorf("defaults: status %d, body %s", w.Code, w.Body)
+	}
+
+	const target = `"scheme":"paynow","account_number":"91234567"`
+	for body, field := range map[string]string{
+		`{` + target + `,"logo":"custom","logo_data":"iVBORw0KGgo="}`: "logo",
+		`{` + target + `,"logo_data":"iVBORw0KGgo="}`:                 "logo_data",
+		`{` + target + `,"template":"compact"}`:                       "template",
+		`{` + target + `,"text_policy":"replace"}`:                    "text_policy",
+	} {
+		w := postJSON("/api/v1/generate", body)
+		var resp GenerateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Field != field {
+			t.Errorf("%s: status %d, body %s; want 400 on %s", body, w.Code, w.Body, field)
+		}
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("scheme", "paynow")
+	mw.WriteField("account_number", "91234567")
+	part, _ := mw.CreateFormFile("logo_file", "logo.png")
+	part.Write([]byte("\x89PNG"))
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/generate", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w = serve(req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"field":"logo_file"`) {
+		t.Errorf("logo_file upload: status %d, body %s", w.Code, w.Body)
+	}
+}
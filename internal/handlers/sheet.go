@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/qrgen"
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// maxSheetVariants caps the number of QR codes in one sheet request
+const maxSheetVariants = 500
+
+// SheetRequest is a base transfer plus one variant per printed QR code
+type SheetRequest struct {
+	BankBin       string `json:"bank_bin"`
+	BankCode      string `json:"bank_code"`
+	AccountNumber string `json:"account_number"`
+	CardNumber    string `json:"card_number"`
+	AccountName   string `json:"account_name"`
+	MerchantCity  string `json:"merchant_city"`
+	Amount        int64  `json:"amount"`  // Default amount for variants without one
+	Message       string `json:"message"` // Default message for variants without one
+	Editable      bool   `json:"editable"`
+	TextPolicy    string `json:"text_policy"`
+	ECC           string `json:"ecc"` // Error correction: L, M, Q or H (default QR_DEFAULT_RECOVERY)
+
+	Variants []SheetVariant `json:"variants"`
+
+	// Layout
+	Page     string  `json:"page"`      // a4 (default), a5, a6 or WIDTHxHEIGHT in mm
+	Columns  int     `json:"columns"`   // Default 3
+	Rows     int     `json:"rows"`      // Default 4
+	Margin   float64 `json:"margin"`    // Page margin in mm (default 10)
+	CutMarks *bool   `json:"cut_marks"` // Default true
+}
+
+// SheetVariant overrides the base transfer for one QR code
+type SheetVariant struct {
+	Message *string `json:"message"`
+	Amount  *int64  `json:"amount"`
+	Label   string  `json:"label"` // Printed under the code; defaults to the message
+}
+
+// Sheet handles POST /api/v1/sheet
+func (h *QRHandler) Sheet(c *gin.Context) {
+	var req SheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   "Invalid request: " + err.Error(),
+		})
+		return
+	}
+
+	if len(req.Variants) == 0 || len(req.Variants) > maxSheetVariants {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   fmt.Sprintf("variants must contain 1 to %d entries", maxSheetVariants),
+			Field:   "variants",
+		})
+		return
+	}
+
+	bank, err := h.resolveBank(req.BankBin, req.BankCode)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   errorField(err),
+		})
+		return
+	}
+
+	number, method, err := resolveTransferTarget(bank.BIN, req.AccountNumber, req.CardNumber)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   errorField(err),
+		})
+		return
+	}
+
+	textPolicy, err := vietqr.ParseTextPolicy(req.TextPolicy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   "text_policy",
+		})
+		return
+	}
+
+	opts, field, err := h.parseImageOptions(imageParams{ECC: req.ECC})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   field,
+		})
+		return
+	}
+
+	page, err := qrgen.ParsePageSize(req.Page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   "page",
+		})
+		return
+	}
+
+	base := vietqr.TransferInfo{
+		BankBin:        bank.BIN,
+		AccountNumber:  number,
+		MerchantName:   req.AccountName,
+		MerchantCity:   req.MerchantCity,
+		Editable:       req.Editable,
+		TransferMethod: method,
+		TextPolicy:     textPolicy,
+	}
+
+	items := make([]qrgen.SheetItem, 0, len(req.Variants))
+	for i, variant := range req.Variants {
+		info := base
+		info.Amount = req.Amount
+		if variant.Amount != nil {
+			info.Amount = *variant.Amount
+		}
+		info.Message = req.Message
+		if variant.Message != nil {
+			info.Message = *variant.Message
+		}
+		info.IsDynamic = info.Amount > 0 && !info.Editable
+
+		if info.Amount < 0 {
+			c.JSON(http.StatusBadRequest, GenerateResponse{
+				Success: false,
+				Error:   "Amount must be non-negative",
+				Field:   fmt.Sprintf("variants[%d].amount", i),
+			})
+			return
+		}
+
+		qrString, err := encodeTransfer(info)
+		if err != nil {
+			field := errorField(err)
+			if field == "message" || field == "amount" {
+				field = fmt.Sprintf("variants[%d].%s", i, field)
+			}
+			c.JSON(http.StatusBadRequest, GenerateResponse{
+				Success: false,
+				Error:   fmt.Sprintf("variant %d: %v", i, err),
+				Field:   field,
+			})
+			return
+		}
+
+		item := qrgen.SheetItem{Content: qrString, Label: variant.Label}
+		if item.Label == "" {
+			item.Label = info.Message
+		}
+		if info.Amount > 0 {
+			item.Caption = formatVND(info.Amount)
+		}
+		items = append(items, item)
+	}
+
+	columns, rows := req.Columns, req.Rows
+	if columns == 0 {
+		columns = 3
+	}
+	if rows == 0 {
+		rows = 4
+	}
+
+	pdf, err := h.generator.GenerateSheet(qrgen.SheetOptions{
+		Page:          page,
+		Columns:       columns,
+		Rows:          rows,
+		Margin:        req.Margin,
+		CutMarks:      req.CutMarks == nil || *req.CutMarks,
+		MaxQRSide:     qrgen.MillimetresForPixels(h.maxSize, qrgen.PrintDPI),
+		RecoveryLevel: opts.RecoveryLevel,
+		Items:         items,
+	})
+	if field := sheetErrorField(err); field != "" {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Field:   field,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GenerateResponse{
+			Success: false,
+			Error:   "Failed to generate QR sheet",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="vietqr-sheet.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// sheetErrorField returns the layout field a GenerateSheet error is about,
+// or "" for errors that are not the client's
+func sheetErrorField(err error) string {
+	switch {
+	case errors.Is(err, qrgen.ErrInvalidColumns), errors.Is(err, qrgen.ErrCellTooNarrow):
+		return "columns"
+	case errors.Is(err, qrgen.ErrInvalidRows), errors.Is(err, qrgen.ErrCellTooShort):
+		return "rows"
+	case errors.Is(err, qrgen.ErrInvalidMargin):
+		return "margin"
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSheetLayoutErrorFields(t *testing.T) {
+	const base = `"bank_bin":"970436","account_number":"1234567890"`
+	tests := []struct {
+		layout string
+		field  string
+	}{
+		{`"columns":11`, "columns"},
+		{`"rows":21`, "rows"},
+		{`"margin":-5`, "margin"},
+		{`"margin":200`, "margin"},
+		{`"page":"a6","columns":10,"rows":1`, "columns"},
+		{`"page":"a6","columns":1,"rows":20`, "rows"},
+	}
+	for _, tt := range tests {
+		w := postJSON("/api/v1/sheet", "{"+base+`,"variants":[{"message":"Ban 01"}],`+tt.layout+"}")
+		var resp GenerateResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusBadRequest || resp.Field != tt.field {
+			t.Errorf("%s: status %d, body %s; want 400 on %s", tt.layout, w.Code, w.Body, tt.field)
+		}
+	}
+
+	w := postJSON("/api/v1/sheet", `{"bank_bin":"999999","account_number":"1234567890","variants":[{"message":"Ban 01"}]}`)
+	var resp GenerateResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusBadRequest || resp.Field != "bank_bin" {
+		t.Errorf("unknown bank: status %d, body %s; want 400 on bank_bin", w.Code, w.Body)
+	}
+
+	w = postJSON("/api/v1/sheet", "{"+base+`,"variants":[{"label":"`+strings.Repeat("Bàn sân vườn ", 20)+`"}]}`)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "%PDF") {
+		t.Errorf("long label: status %d, body %.60q", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxqr-api/internal/qrgen"
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+var (
+	errTemplateRasterOnly = errors.New("template needs a raster format (png, png8, jpeg, webp or base64)")
+	errLogoNotPrintable   = errors.New("logo is not available for escpos or zpl")
+)
+
+// cardStyle is the template, theme and centre logo requested for a VietQR
+// image. A nil *cardStyle means the bare QR code.
+type cardStyle struct {
+	template qrgen.Template
+	theme    qrgen.Theme
+	logo     *qrLogo
+}
+
+// parseCardStyle validates the template and theme parameters. It returns
+// the offending field name on error.
+func parseCardStyle(template, theme string) (*cardStyle, string, error) {
+	tmpl, err := qrgen.ParseTemplate(template)
+	if err != nil {
+		return nil, "template", err
+	}
+	t, err := qrgen.GetTheme(theme)
+	if err != nil {
+		return nil, "theme", err
+	}
+	if tmpl == qrgen.TemplateBare {
+		return nil, "", nil
+	}
+	return &cardStyle{template: tmpl, theme: t}, "", nil
+}
+
+// withLogo returns a copy of s with the centre logo set; a nil s becomes
+// the bare template with a logo
+func (s *cardStyle) withLogo(logo *qrLogo) *cardStyle {
+	if logo == nil {
+		return s
+	}
+	style := cardStyle{template: qrgen.TemplateBare}
+	if s != nil {
+		style = *s
+	}
+	style.logo = logo
+	return &style
+}
+
+// applyTo prepares opts for a non-raster format (svg, pdf, escpos or zpl).
+// Templates are raster-only, and printers draw the code with their own QR
+// command, so both are rejected rather than dropped; it returns the
+// offending field. SVG and PDF carry the centre logo in opts.
+func (s *cardStyle) applyTo(format string, opts *qrgen.GenerateOptions) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	if s.template != qrgen.TemplateBare {
+		return "template", errTemplateRasterOnly
+	}
+	if s.logo != nil {
+		if isPrinterFormat(format) {
+			return "logo", errLogoNotPrintable
+		}
+		opts.Logo = s.logo.image
+	}
+	return "", nil
+}
+
+// cardInfo builds the text of a card from the bank and transfer details
+func cardInfo(bank *vietqr.Bank, details *TransferDetails) qrgen.CardInfo {
+	info := qrgen.CardInfo{
+		BankName:      bank.Name,
+		BankShortName: bank.ShortName,
+		AccountName:   details.AccountName,
+		AccountNumber: details.AccountNumber,
+		Message:       details.Message,
+	}
+	if details.CardNumber != "" {
+		info.AccountNumber = details.CardNumber
+	}
+	if details.Amount > 0 {
+		info.Amount = formatVND(details.Amount)
+	}
+	return info
+}
+
+// getOrGenerateCard renders a templated image, caching it like bare codes
+func (h *QRHandler) getOrGenerateCard(opts qrgen.GenerateOptions, style *cardStyle, info qrgen.CardInfo) ([]byte, error) {
+	if style == nil {
+		return h.getOrGenerateQR(opts)
+	}
+
+	// Uploaded logos are one-off images and are not cached
+	useCache := h.useCache
+	logoKey := ""
+	if style.logo != nil {
+		opts.Logo = style.logo.image
+		logoKey = style.logo.key
+		useCache = useCache && logoKey != ""
+	}
+
+	cacheKey := opts.CacheKey(
+		string(style.template), style.theme.Name, logoKey,
+		info.BankName, info.AccountName, info.AccountNumber, info.Amount, info.Message,
+	)
+
+	if useCache {
+		if data, found := h.cache.Get(cacheKey); found {
+			return data, nil
+		}
+	}
+
+	data, err := h.generator.GenerateCardWithOptions(opts, style.template, style.theme, info)
+	if err != nil {
+		return nil, err
+	}
+
+	if useCache {
+		h.cache.Set(cacheKey, data)
+	}
+
+	return data, nil
+}
+
+// serveCard serves a templated image response in opts.Format
+func (h *QRHandler) serveCard(c *gin.Context, opts qrgen.GenerateOptions, style *cardStyle, info qrgen.CardInfo) {
+	imgData, err := h.getOrGenerateCard(opts, style, info)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "generation_failed",
+			"message": "Failed to generate QR code",
+		})
+		return
+	}
+	serveImageData(c, opts.Format, imgData)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/maxqr-api/internal/qrgen"
+)
+
+func TestTransparentCodeOnDarkTemplate(t *testing.T) {
+	w := get("/api/v1/quick?bank=970436&account=1234567890&format=png&bg=transparent&template=card&theme=dark")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	card, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The 300px code sits below the header and logo on a white square,
+	// so its black modules keep full contrast on the dark card
+	const x, y = 18, 48 + 9 + 36
+	quiet := card.At(x+1, y+1)
+	if r, g, b, _ := quiet.RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+		t.Errorf("quiet zone is %v, want white", quiet)
+	}
+	if ratio := qrgen.ContrastRatio(color.Black, quiet); ratio < qrgen.MinContrastRatio {
+		t.Errorf("modules have %.2f:1 contrast on the card", ratio)
+	}
+}
@@ -0,0 +1,147 @@
+package qrgen
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinContrastRatio is the lowest WCAG contrast ratio between the modules and
+// the background that phone cameras in banking apps read reliably
+const MinContrastRatio = 3.0
+
+// MaxQuietZone is the widest quiet zone in modules
+const MaxQuietZone = 20
+
+// Color option errors
+var (
+	ErrInvalidColor     = errors.New("color must be #RGB, #RGBA, #RRGGBB, #RRGGBBAA, rgba(r,g,b,a) or transparent")
+	ErrTransparentFG    = errors.New("foreground color must not be transparent")
+	ErrInvertedColors   = errors.New("foreground must be darker than background; inverted codes do not scan in most banking apps")
+	ErrLowContrast      = fmt.Errorf("foreground and background contrast must be at least %.0f:1", MinContrastRatio)
+	ErrInvalidQuietZone = fmt.Errorf("border must be between 0 and %d modules", MaxQuietZone)
+)
+
+// ParseColor parses a hex colour (#RGB, #RGBA, #RRGGBB or #RRGGBBAA, with or
+// without the #), rgb(r,g,b), rgba(r,g,b,a) with alpha in 0-1, or
+// "transparent"
+func ParseColor(s string) (color.Color, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "transparent" {
+		return color.NRGBA{}, nil
+	}
+	if strings.HasPrefix(s, "rgb") {
+		return parseRGBFunc(s)
+	}
+
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 || len(hex) == 4 {
+		var long strings.Builder
+		for _, c := range hex {
+			long.WriteRune(c)
+			long.WriteRune(c)
+		}
+		hex = long.String()
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return nil, ErrInvalidColor
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, ErrInvalidColor
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// parseRGBFunc parses the CSS rgb() and rgba() notations
+func parseRGBFunc(s string) (color.Color, error) {
+	name, args, ok := strings.Cut(s, "(")
+	if !ok || !strings.HasSuffix(args, ")") || (name != "rgb" && name != "rgba") {
+		return nil, ErrInvalidColor
+	}
+	parts := strings.Split(strings.TrimSuffix(args, ")"), ",")
+	if len(parts) != 3 && len(parts) != 4 {
+		return nil, ErrInvalidColor
+	}
+
+	var rgb [3]uint8
+	for i := range rgb {
+		v, err := strconv.ParseUint(strings.TrimSpace(parts[i]), 10, 8)
+		if err != nil {
+			return nil, ErrInvalidColor
+		}
+		rgb[i] = uint8(v)
+	}
+	alpha := 1.0
+	if len(parts) == 4 {
+		a, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		if err != nil || a < 0 || a > 1 {
+			return nil, ErrInvalidColor
+		}
+		alpha = a
+	}
+	return color.NRGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: uint8(math.Round(alpha * 255))}, nil
+}
+
+// ColorHex formats c as #RRGGBBAA (non-premultiplied)
+func ColorHex(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x%02x", n.R, n.G, n.B, n.A)
+}
+
+// ContrastRatio returns the WCAG 2 contrast ratio of fg drawn over bg, with
+// bg itself composited over white (how transparent images are usually shown)
+func ContrastRatio(fg, bg color.Color) float64 {
+	back := over(bg, color.White)
+	front := over(fg, back)
+	l1, l2 := luminance(front), luminance(back)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// CheckContrast rejects colour pairs scanners cannot read: a transparent
+// foreground, a foreground lighter than the background, or a contrast ratio
+// below MinContrastRatio
+func CheckContrast(fg, bg color.Color) error {
+	if _, _, _, a := fg.RGBA(); a == 0 {
+		return ErrTransparentFG
+	}
+	back := over(bg, color.White)
+	if luminance(over(fg, back)) >= luminance(back) {
+		return ErrInvertedColors
+	}
+	if ContrastRatio(fg, bg) < MinContrastRatio {
+		return ErrLowContrast
+	}
+	return nil
+}
+
+// over composites src over an opaque dst
+func over(src, dst color.Color) color.RGBA {
+	sr, sg, sb, sa := src.RGBA()
+	dr, dg, db, _ := dst.RGBA()
+	blend := func(s, d uint32) uint8 {
+		return uint8((s + d*(0xffff-sa)/0xffff) >> 8)
+	}
+	return color.RGBA{R: blend(sr, dr), G: blend(sg, dg), B: blend(sb, db), A: 0xff}
+}
+
+// luminance returns the WCAG relative luminance of an opaque colour
+func luminance(c color.RGBA) float64 {
+	channel := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
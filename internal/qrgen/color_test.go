@@ -0,0 +1,78 @@
+package qrgen
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.NRGBA
+	}{
+		{"#000", color.NRGBA{0, 0, 0, 0xff}},
+		{"fff", color.NRGBA{0xff, 0xff, 0xff, 0xff}},
+		{"#1234", color.NRGBA{0x11, 0x22, 0x33, 0x44}},
+		{"#003366", color.NRGBA{0x00, 0x33, 0x66, 0xff}},
+		{" #00336680 ", color.NRGBA{0x00, 0x33, 0x66, 0x80}},
+		{"#ABCDEF", color.NRGBA{0xab, 0xcd, 0xef, 0xff}},
+		{"rgb(1, 2, 3)", color.NRGBA{1, 2, 3, 0xff}},
+		{"RGBA(10,20,30,0.5)", color.NRGBA{10, 20, 30, 0x80}},
+		{"rgba(10,20,30,0)", color.NRGBA{10, 20, 30, 0}},
+		{"transparent", color.NRGBA{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "#12", "#12345", "#1234567", "#gggggg", "red", "rgb(1,2)", "rgb(256,0,0)", "rgba(1,2,3,1.5)", "rgba(1,2,3,x)", "hsl(0,0,0)", "rgb(1,2,3"} {
+		if _, err := ParseColor(in); err != ErrInvalidColor {
+			t.Errorf("ParseColor(%q) error = %v, want ErrInvalidColor", in, err)
+		}
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		name   string
+		fg, bg color.Color
+		want   float64
+	}{
+		{"black on white", color.Black, color.White, 21},
+		{"white on black", color.White, color.Black, 21},
+		{"same colour", color.NRGBA{0x80, 0x80, 0x80, 0xff}, color.NRGBA{0x80, 0x80, 0x80, 0xff}, 1},
+		{"transparent background is white", color.Black, color.NRGBA{}, 21},
+		{"half-transparent black background", color.Black, color.NRGBA{A: 0x80}, 5.24},
+		{"half-transparent foreground", color.NRGBA{A: 0x80}, color.White, 4.00},
+		{"grey on white", color.NRGBA{0x76, 0x76, 0x76, 0xff}, color.White, 4.54},
+	}
+	for _, tt := range tests {
+		if got := ContrastRatio(tt.fg, tt.bg); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%s: ContrastRatio = %.2f, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckContrast(t *testing.T) {
+	tests := []struct {
+		name   string
+		fg, bg color.Color
+		want   error
+	}{
+		{"default", color.Black, color.White, nil},
+		{"transparent background", color.NRGBA{0, 0x33, 0x66, 0xff}, color.NRGBA{}, nil},
+		{"transparent foreground", color.NRGBA{}, color.White, ErrTransparentFG},
+		{"inverted", color.White, color.Black, ErrInvertedColors},
+		{"light grey", color.NRGBA{0xaa, 0xaa, 0xaa, 0xff}, color.White, ErrLowContrast},
+		{"white on transparent", color.White, color.NRGBA{}, ErrInvertedColors},
+	}
+	for _, tt := range tests {
+		if err := CheckContrast(tt.fg, tt.bg); err != tt.want {
+			t.Errorf("%s: CheckContrast = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package qrgen
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"sort"
+
+	"golang.org/x/image/draw"
+)
+
+// JPEGQuality is the quality JPEG images are encoded at; high enough that
+// module edges stay sharp after chroma subsampling
+const JPEGQuality = 92
+
+// maxPaletteColors is the largest palette a PNG can hold
+const maxPaletteColors = 256
+
+// ContentType returns the MIME type of the format
+func (f QRFormat) ContentType() string {
+	switch f {
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatWebP:
+		return "image/webp"
+	case FormatSVG:
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// rasterFormat returns f with the empty format meaning PNG
+func (f QRFormat) rasterFormat() QRFormat {
+	if f == "" {
+		return FormatPNG
+	}
+	return f
+}
+
+// encode encodes img in format: PNG (palette-based when img has at most 256
+// colours, so two-colour codes take one bit per pixel), PNG8 (always
+// palette-based, reduced to the 256 most common colours), JPEG (flattened
+// onto white) or lossless WebP. dpi is recorded in PNG files.
+func (g *Generator) encode(img image.Image, format QRFormat, dpi int) ([]byte, error) {
+	switch format.rasterFormat() {
+	case FormatPNG8:
+		return g.encodePNG(palettize(img, true), dpi)
+	case FormatJPEG:
+		return encodeJPEG(img)
+	case FormatWebP:
+		return encodeWebP(img)
+	}
+	return g.encodePNG(palettize(img, false), dpi)
+}
+
+// encodeJPEG encodes img as a JPEG, compositing any transparency over white
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: JPEGQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// flatten composites img over white, the backdrop ContrastRatio assumes
+// for transparent backgrounds
+func flatten(img image.Image) *image.RGBA {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+	return flat
+}
+
+// palettize returns img as a paletted image when it has at most 256
+// colours. With quantize set, images with more colours are reduced to their
+// 256 most common ones (anti-aliased edges snap to the nearest); otherwise
+// they are returned unchanged.
+func palettize(img image.Image, quantize bool) image.Image {
+	if _, ok := img.(*image.Paletted); ok {
+		return img
+	}
+	b := img.Bounds()
+
+	counts := make(map[color.NRGBA]int)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			counts[color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)]++
+			if len(counts) > maxPaletteColors && !quantize {
+				return img
+			}
+		}
+	}
+
+	colors := make([]color.NRGBA, 0, len(counts))
+	for c := range counts {
+		colors = append(colors, c)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		if counts[colors[i]] != counts[colors[j]] {
+			return counts[colors[i]] > counts[colors[j]]
+		}
+		return colorLess(colors[i], colors[j])
+	})
+	colors = colors[:min(len(colors), maxPaletteColors)]
+
+	palette := make(color.Palette, len(colors))
+	index := make(map[color.NRGBA]uint8, len(counts))
+	for i, c := range colors {
+		palette[i] = c
+		index[c] = uint8(i)
+	}
+
+	out := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), palette)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			i, ok := index[c]
+			if !ok {
+				i = uint8(palette.Index(c))
+				index[c] = i
+			}
+			out.Pix[(y-b.Min.Y)*out.Stride+x-b.Min.X] = i
+		}
+	}
+	return out
+}
+
+// colorLess orders colours so palettes are deterministic
+func colorLess(a, b color.NRGBA) bool {
+	if a.R != b.R {
+		return a.R < b.R
+	}
+	if a.G != b.G {
+		return a.G < b.G
+	}
+	if a.B != b.B {
+		return a.B < b.B
+	}
+	return a.A < b.A
+}
@@ -0,0 +1,262 @@
+package qrgen
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	qrcode "github.com/skip2/go-qrcode"
+	"golang.org/x/image/webp"
+)
+
+// gradientImage returns a w×h image with more than 256 colours, including
+// transparent and partly transparent pixels
+func gradientImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 6), G: uint8(y * 8), B: uint8(x * y), A: uint8(x * 255 / (w - 1))})
+		}
+	}
+	return img
+}
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	code, err := g.GenerateImage(testContent, SizeMedium)
+	if err != nil {
+		t.Fatalf("GenerateImage: %v", err)
+	}
+
+	// Odd widths leave a partly filled last pixel when indexes are bundled
+	palette := color.Palette{
+		color.NRGBA{},
+		color.NRGBA{R: 0xff, A: 0xff},
+		color.NRGBA{G: 0x80, B: 0xff, A: 0x80},
+		color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff},
+		color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+	paletted := image.NewPaletted(image.Rect(0, 0, 37, 23), palette)
+	for i := range paletted.Pix {
+		paletted.Pix[i] = uint8(i * 7 % len(palette))
+	}
+
+	wide := image.NewPaletted(image.Rect(0, 0, 45, 19), nil)
+	for i := 0; i < 256; i++ {
+		wide.Palette = append(wide.Palette, color.NRGBA{R: uint8(i), G: uint8(255 - i), B: uint8(i * 3), A: 0xff})
+	}
+	for i := range wide.Pix {
+		wide.Pix[i] = uint8(i * 13)
+	}
+
+	tests := map[string]image.Image{
+		"two-colour code": code,
+		"paletted":        paletted,
+		"256 colours":     wide,
+		"gradient":        gradientImage(43, 35),
+		"offset bounds":   gradientImage(40, 30).SubImage(image.Rect(5, 3, 38, 29)),
+		"single pixel":    image.NewNRGBA(image.Rect(0, 0, 1, 1)),
+		"solid":           flatten(image.NewRGBA(image.Rect(0, 0, 300, 2))),
+	}
+
+	for name, img := range tests {
+		data, err := encodeWebP(img)
+		if err != nil {
+			t.Errorf("%s: encodeWebP: %v", name, err)
+			continue
+		}
+		got, err := webp.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: webp.Decode: %v", name, err)
+			continue
+		}
+		b := img.Bounds()
+		if got.Bounds().Dx() != b.Dx() || got.Bounds().Dy() != b.Dy() {
+			t.Errorf("%s: decoded %v, want %dx%d", name, got.Bounds(), b.Dx(), b.Dy())
+			continue
+		}
+		mismatches := 0
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				want := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+				have := color.NRGBAModel.Convert(got.At(got.Bounds().Min.X+x, got.Bounds().Min.Y+y)).(color.NRGBA)
+				if want.A == 0 {
+					want, have = color.NRGBA{}, color.NRGBA{A: have.A}
+				}
+				if have != want {
+					if mismatches++; mismatches <= 3 {
+						t.Errorf("%s: pixel (%d,%d) = %v, want %v", name, x, y, have, want)
+					}
+				}
+			}
+		}
+	}
+
+	if _, err := encodeWebP(image.NewNRGBA(image.Rect(0, 0, vp8lMaxDimension+1, 1))); !errors.Is(err, ErrWebPTooLarge) {
+		t.Errorf("oversized image error = %v, want ErrWebPTooLarge", err)
+	}
+}
+
+func TestPalettize(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	gradient := gradientImage(43, 35)
+
+	tests := []struct {
+		name     string
+		img      image.Image
+		format   QRFormat
+		paletted bool
+		colors   int // Exact palette size, or the most allowed when negative
+	}{
+		{name: "png8 gradient", img: gradient, format: FormatPNG8, paletted: true, colors: -maxPaletteColors},
+		{name: "png gradient", img: gradient, format: FormatPNG},
+		{name: "png two colours", img: flatten(gradientImage(2, 1)), format: FormatPNG, paletted: true, colors: 2},
+		{name: "png8 two colours", img: flatten(gradientImage(2, 1)), format: FormatPNG8, paletted: true, colors: 2},
+	}
+	for _, tt := range tests {
+		data, err := g.encode(tt.img, tt.format, 0)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", tt.name, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: png.Decode: %v", tt.name, err)
+		}
+		p, ok := img.(*image.Paletted)
+		if ok != tt.paletted {
+			t.Errorf("%s: decoded %T, paletted = %v", tt.name, img, tt.paletted)
+			continue
+		}
+		switch {
+		case !ok:
+		case tt.colors < 0 && (len(p.Palette) < 2 || len(p.Palette) > -tt.colors):
+			t.Errorf("%s: palette has %d colours, want at most %d", tt.name, len(p.Palette), -tt.colors)
+		case tt.colors > 0 && len(p.Palette) != tt.colors:
+			t.Errorf("%s: palette has %d colours, want %d", tt.name, len(p.Palette), tt.colors)
+		}
+	}
+
+	// Quantizing keeps the most common colours and snaps the rest to them
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 2))
+	for x := 0; x < 300; x++ {
+		img.SetNRGBA(x, 0, color.NRGBA{R: uint8(x), G: uint8(x >> 8), A: 0xff})
+		img.SetNRGBA(x, 1, color.NRGBA{B: 0xff, A: 0xff})
+	}
+	p := palettize(img, true).(*image.Paletted)
+	if len(p.Palette) != maxPaletteColors {
+		t.Fatalf("palette has %d colours, want %d", len(p.Palette), maxPaletteColors)
+	}
+	if p.Palette[0] != (color.NRGBA{B: 0xff, A: 0xff}) {
+		t.Errorf("most common colour is %v, want it first", p.Palette[0])
+	}
+	if c := p.At(0, 1); c != (color.NRGBA{B: 0xff, A: 0xff}) {
+		t.Errorf("kept colour became %v", c)
+	}
+}
+
+func TestPNGSize(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+
+	// The old plain PNGs came straight from go-qrcode, already two-colour
+	// paletted; the new ones must not be larger
+	qr, err := qrcode.New(testContent, qrcode.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := qr.PNG(int(SizeMedium))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := g.GeneratePNG(testContent, SizeMedium)
+	if err != nil {
+		t.Fatalf("GeneratePNG: %v", err)
+	}
+	if len(plain) > len(old)*11/10 {
+		t.Errorf("plain PNG is %d bytes, go-qrcode wrote %d", len(plain), len(old))
+	}
+
+	// Coloured, styled and logo codes used to be encoded as truecolour RGBA.
+	// Palettes make hard-edged codes several times smaller; anti-aliased
+	// styles keep enough colours that they only shrink.
+	tests := []struct {
+		name  string
+		set   func(*GenerateOptions)
+		ratio int
+	}{
+		{"coloured", func(o *GenerateOptions) { o.ForegroundColor = color.RGBA{R: 0x80, A: 0xff} }, 4},
+		{"styled", func(o *GenerateOptions) { o.Style = &Style{Shape: ShapeDot, Eye: EyeCircle} }, 1},
+	}
+	for _, tt := range tests {
+		opts := g.DefaultOptions(testContent, SizeMedium)
+		tt.set(&opts)
+		img, err := g.GenerateImageWithOptions(opts)
+		if err != nil {
+			t.Fatalf("%s: GenerateImageWithOptions: %v", tt.name, err)
+		}
+		rgba := image.NewRGBA(img.Bounds())
+		draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+		var truecolor bytes.Buffer
+		if err := png.Encode(&truecolor, rgba); err != nil {
+			t.Fatal(err)
+		}
+		data, err := g.GenerateRasterWithOptions(opts)
+		if err != nil {
+			t.Fatalf("%s: GenerateRasterWithOptions: %v", tt.name, err)
+		}
+		if len(data)*tt.ratio >= truecolor.Len() {
+			t.Errorf("%s: PNG is %d bytes, truecolour %d, want under 1/%d", tt.name, len(data), truecolor.Len(), tt.ratio)
+		}
+	}
+}
+
+func TestEncodeJPEGFlattensTransparency(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	opts := g.DefaultOptions(testContent, SizeMedium)
+	opts.BackgroundColor = color.Transparent
+	opts.Format = FormatJPEG
+
+	data, err := g.GenerateRasterWithOptions(opts)
+	if err != nil {
+		t.Fatalf("GenerateRasterWithOptions: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("jpeg.Decode: %v", err)
+	}
+
+	b := img.Bounds()
+	darkest := uint32(0xffff)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			darkest = min(darkest, r)
+		}
+	}
+	if r, gr, bl, _ := img.At(b.Min.X, b.Min.Y).RGBA(); r < 0xf000 || gr < 0xf000 || bl < 0xf000 {
+		t.Errorf("transparent background came out as %04x %04x %04x, want white", r, gr, bl)
+	}
+	if darkest > 0x4000 {
+		t.Errorf("darkest pixel %04x, want the modules black", darkest)
+	}
+
+	// Partial transparency is composited, not dropped
+	half := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	draw.Draw(half, half.Bounds(), image.NewUniform(color.NRGBA{R: 0xff, A: 0x80}), image.Point{}, draw.Src)
+	data, err = encodeJPEG(half)
+	if err != nil {
+		t.Fatalf("encodeJPEG: %v", err)
+	}
+	img, err = jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("jpeg.Decode: %v", err)
+	}
+	r, gr, bl, _ := img.At(8, 8).RGBA()
+	if r < 0xf000 || gr>>8 < 0x70 || gr>>8 > 0x90 || bl>>8 < 0x70 || bl>>8 > 0x90 {
+		t.Errorf("half-transparent red came out as %04x %04x %04x, want about ff 80 80", r, gr, bl)
+	}
+}
@@ -0,0 +1,56 @@
+package qrgen
+
+import (
+	"bytes"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// escposMaxModule is the largest module size GS ( k function 167 accepts
+const escposMaxModule = 16
+
+// escposECC maps recovery levels to the GS ( k function 169 argument
+var escposECC = map[qrcode.RecoveryLevel]byte{
+	qrcode.Low:     48,
+	qrcode.Medium:  49,
+	qrcode.High:    50,
+	qrcode.Highest: 51,
+}
+
+// GenerateESCPOS returns ESC/POS commands that print the QR code centred
+// on a receipt printer, followed by a line feed. The native mode uses the
+// printer's QR engine (GS ( k); raster mode sends the rendered bitmap with
+// GS v 0 for printers without one.
+func (g *Generator) GenerateESCPOS(opts PrinterOptions) ([]byte, error) {
+	layout, err := layoutPrinter(opts, printerPaperMargin, escposMaxModule)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write([]byte{0x1b, 0x40})       // ESC @ - initialize
+	buf.Write([]byte{0x1b, 0x61, 0x01}) // ESC a 1 - centre
+
+	if opts.Raster {
+		rows, rowBytes := layout.packRows(layout.size())
+		buf.Write([]byte{0x1d, 0x76, 0x30, 0x00, // GS v 0, normal density
+			byte(rowBytes), byte(rowBytes >> 8),
+			byte(len(rows)), byte(len(rows) >> 8)})
+		for _, row := range rows {
+			buf.Write(row)
+		}
+	} else {
+		data := len(opts.Content) + 3
+		buf.Write([]byte{0x1d, 0x28, 0x6b, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00})                    // Model 2
+		buf.Write([]byte{0x1d, 0x28, 0x6b, 0x03, 0x00, 0x31, 0x43, byte(layout.module)})           // Module size
+		buf.Write([]byte{0x1d, 0x28, 0x6b, 0x03, 0x00, 0x31, 0x45, escposECC[opts.RecoveryLevel]}) // Error correction
+		buf.Write([]byte{0x1d, 0x28, 0x6b, byte(data), byte(data >> 8), 0x31, 0x50, 0x30})         // Store data
+		buf.WriteString(opts.Content)
+		buf.Write([]byte{0x1d, 0x28, 0x6b, 0x03, 0x00, 0x31, 0x51, 0x30}) // Print
+	}
+
+	buf.WriteByte(0x0a)                 // LF
+	buf.Write([]byte{0x1b, 0x61, 0x00}) // ESC a 0 - back to left alignment
+
+	return buf.Bytes(), nil
+}
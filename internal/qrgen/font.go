@@ -0,0 +1,71 @@
+package qrgen
+
+import (
+	_ "embed"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// fontRegular is DejaVu Sans, which covers Vietnamese diacritics
+//
+//go:embed fonts/DejaVuSans.ttf
+var fontRegular []byte
+
+var (
+	parsedFont    *opentype.Font
+	parsedFontErr error
+	parseFontOnce sync.Once
+)
+
+// loadFont parses the embedded font on first use
+func loadFont() (*opentype.Font, error) {
+	parseFontOnce.Do(func() {
+		parsedFont, parsedFontErr = opentype.Parse(fontRegular)
+	})
+	return parsedFont, parsedFontErr
+}
+
+// newFace returns a face of the embedded font at size pixels
+func newFace(size float64) (font.Face, error) {
+	f, err := loadFont()
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// drawText draws text centred on cx with its baseline at y, shortening it
+// with an ellipsis when it is wider than maxWidth
+func drawText(dst draw.Image, face font.Face, text string, cx, y, maxWidth int, c color.Color) {
+	text = fitText(face, text, maxWidth)
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: face}
+	width := d.MeasureString(text).Round()
+	d.Dot = fixed.P(cx-width/2, y)
+	d.DrawString(text)
+}
+
+// fitText shortens text with an ellipsis until it fits maxWidth pixels
+func fitText(face font.Face, text string, maxWidth int) string {
+	if font.MeasureString(face, text).Round() <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "…"
+		if font.MeasureString(face, candidate).Round() <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -2,15 +2,17 @@ package qrgen
 
 import (
 	"bytes"
+	"encoding/binary"
 	"hash/fnv"
 	"image"
 	"image/color"
-	"image/draw"
 	"image/png"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/draw"
 )
 
 // QRSize represents QR code size options
@@ -27,18 +29,21 @@ const (
 type QRFormat string
 
 const (
-	FormatPNG  QRFormat = "png"
-	FormatSVG  QRFormat = "svg"
+	FormatPNG    QRFormat = "png"
+	FormatSVG    QRFormat = "svg"
 	FormatBase64 QRFormat = "base64"
+	FormatPNG8   QRFormat = "png8" // Palette-based PNG, at most 256 colours
+	FormatJPEG   QRFormat = "jpeg"
+	FormatWebP   QRFormat = "webp" // Lossless
 )
 
 // GeneratorConfig holds configuration for QR code generation
 type GeneratorConfig struct {
-	DefaultSize        QRSize
-	DefaultRecovery    qrcode.RecoveryLevel
-	BackgroundColor    color.Color
-	ForegroundColor    color.Color
-	DisableBorder      bool
+	DefaultSize     QRSize
+	DefaultRecovery qrcode.RecoveryLevel
+	BackgroundColor color.Color
+	ForegroundColor color.Color
+	DisableBorder   bool
 }
 
 // DefaultConfig returns default generator configuration
@@ -77,135 +82,188 @@ type GenerateOptions struct {
 	RecoveryLevel   qrcode.RecoveryLevel
 	BackgroundColor color.Color
 	ForegroundColor color.Color
+	QuietZone       int         // Margin in modules around the symbol
+	Logo            image.Image // Centre logo (optional, raises the level to qrcode.Highest)
+	LogoSize        int         // Logo side in pixels (0 = largest allowed)
+	DPI             int         // Resolution written to the PNG pHYs chunk (0 = none)
+	Style           *Style      // Module shapes, finder patterns and gradient (nil = plain)
+	Format          QRFormat    // Raster encoding for GenerateRasterWithOptions (default PNG)
 }
 
-// GeneratePNG generates a QR code as PNG bytes
-func (g *Generator) GeneratePNG(content string, size QRSize) ([]byte, error) {
-	return g.GeneratePNGWithOptions(GenerateOptions{
+// DefaultOptions returns the generator's default options for content
+func (g *Generator) DefaultOptions(content string, size QRSize) GenerateOptions {
+	quietZone := DefaultQuietZone
+	if g.config.DisableBorder {
+		quietZone = 0
+	}
+	return GenerateOptions{
 		Content:         content,
 		Size:            size,
 		RecoveryLevel:   g.config.DefaultRecovery,
 		BackgroundColor: g.config.BackgroundColor,
 		ForegroundColor: g.config.ForegroundColor,
-	})
+		QuietZone:       quietZone,
+	}
+}
+
+// GeneratePNG generates a QR code as PNG bytes
+func (g *Generator) GeneratePNG(content string, size QRSize) ([]byte, error) {
+	return g.GeneratePNGWithOptions(g.DefaultOptions(content, size))
 }
 
 // GeneratePNGWithOptions generates a QR code with custom options
 func (g *Generator) GeneratePNGWithOptions(opts GenerateOptions) ([]byte, error) {
-	// Create QR code
-	qr, err := qrcode.New(opts.Content, opts.RecoveryLevel)
+	opts.Format = FormatPNG
+	return g.GenerateRasterWithOptions(opts)
+}
+
+// GenerateRasterWithOptions generates a QR code encoded in opts.Format (PNG,
+// PNG8, JPEG or WebP)
+func (g *Generator) GenerateRasterWithOptions(opts GenerateOptions) ([]byte, error) {
+	img, err := g.GenerateImageWithOptions(opts)
 	if err != nil {
 		return nil, err
 	}
-
-	// Set colors
-	if opts.BackgroundColor != nil {
-		qr.BackgroundColor = opts.BackgroundColor
-	}
-	if opts.ForegroundColor != nil {
-		qr.ForegroundColor = opts.ForegroundColor
-	}
-
-	// Set border
-	qr.DisableBorder = g.config.DisableBorder
-
-	// Generate PNG
-	return qr.PNG(int(opts.Size))
+	return g.encode(img, opts.Format, opts.DPI)
 }
 
 // GenerateImage generates a QR code as image.Image
 func (g *Generator) GenerateImage(content string, size QRSize) (image.Image, error) {
-	qr, err := qrcode.New(content, g.config.DefaultRecovery)
-	if err != nil {
-		return nil, err
-	}
-
-	qr.BackgroundColor = g.config.BackgroundColor
-	qr.ForegroundColor = g.config.ForegroundColor
-	qr.DisableBorder = g.config.DisableBorder
-
-	return qr.Image(int(size)), nil
+	return g.GenerateImageWithOptions(g.DefaultOptions(content, size))
 }
 
-// GenerateWithLogo generates a QR code with a logo in the center
-func (g *Generator) GenerateWithLogo(content string, size QRSize, logo image.Image, logoSize int) ([]byte, error) {
-	// Generate base QR with high error correction for logo overlay
-	qr, err := qrcode.New(content, qrcode.Highest)
+// GenerateImageWithOptions renders the module matrix Size pixels square with
+// QuietZone modules of background around it. Every module is the same
+// whole number of pixels so edges stay crisp when printed; the pixels left
+// over are split evenly around the quiet zone. Colours may be translucent;
+// a Logo is overlaid by overlayLogo. A Style other than plain squares is
+// drawn anti-aliased by renderStyled.
+func (g *Generator) GenerateImageWithOptions(opts GenerateOptions) (image.Image, error) {
+	matrix, err := Matrix(opts.Content, opts.Recovery())
 	if err != nil {
 		return nil, err
 	}
 
-	qr.BackgroundColor = g.config.BackgroundColor
-	qr.ForegroundColor = g.config.ForegroundColor
-
-	// Generate QR as image
-	qrImage := qr.Image(int(size))
-
-	// Create output image
-	bounds := qrImage.Bounds()
-	output := image.NewRGBA(bounds)
-	draw.Draw(output, bounds, qrImage, image.Point{}, draw.Src)
-
-	// Calculate logo position (center)
-	if logo != nil && logoSize > 0 {
-		logoResized := resizeImage(logo, logoSize, logoSize)
-		logoBounds := logoResized.Bounds()
+	bg := opts.BackgroundColor
+	if bg == nil {
+		bg = color.White
+	}
+	fg := opts.ForegroundColor
+	if fg == nil {
+		fg = color.Black
+	}
+	quietZone := max(opts.QuietZone, 0)
+
+	// The image grows to one pixel per module when Size is too small
+	// (minStyledModule pixels for styled codes)
+	dim := len(matrix) + 2*quietZone
+	minModule := 1
+	if !opts.Style.plain() {
+		minModule = minStyledModule
+	}
+	size := max(int(opts.Size), dim*minModule)
+	module := size / dim
+	offset := (size-module*dim)/2 + quietZone*module
+
+	if !opts.Style.plain() {
+		output := opts.Style.renderStyled(matrix, size, module, offset, fg, bg)
+		if opts.Logo != nil {
+			overlayLogo(output, len(matrix)*module, module, opts.Logo, opts.LogoSize, bg)
+		}
+		return output, nil
+	}
 
-		offsetX := (bounds.Dx() - logoBounds.Dx()) / 2
-		offsetY := (bounds.Dy() - logoBounds.Dy()) / 2
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{bg, fg})
+	for my, modules := range matrix {
+		for mx, dark := range modules {
+			if !dark {
+				continue
+			}
+			x, y := offset+mx*module, offset+my*module
+			for py := y; py < y+module; py++ {
+				row := img.Pix[py*img.Stride+x : py*img.Stride+x+module]
+				for i := range row {
+					row[i] = 1
+				}
+			}
+		}
+	}
 
-		logoRect := image.Rect(offsetX, offsetY, offsetX+logoBounds.Dx(), offsetY+logoBounds.Dy())
-		draw.Draw(output, logoRect, logoResized, image.Point{}, draw.Over)
+	if opts.Logo == nil {
+		return img, nil
 	}
+	output := image.NewRGBA(img.Bounds())
+	draw.Draw(output, output.Bounds(), img, image.Point{}, draw.Src)
+	overlayLogo(output, len(matrix)*module, module, opts.Logo, opts.LogoSize, bg)
+	return output, nil
+}
 
-	// Encode to PNG
+// encodePNG encodes img using a pooled buffer, recording dpi when positive
+func (g *Generator) encodePNG(img image.Image, dpi int) ([]byte, error) {
 	buf := g.bufferPool.Get().(*bytes.Buffer)
 	buf.Reset()
 	defer g.bufferPool.Put(buf)
 
-	if err := png.Encode(buf, output); err != nil {
+	if err := png.Encode(buf, img); err != nil {
 		return nil, err
 	}
 
 	result := make([]byte, buf.Len())
 	copy(result, buf.Bytes())
-	return result, nil
+	return withDPI(result, dpi), nil
 }
 
-// GenerateSVG generates a QR code as SVG string
-func (g *Generator) GenerateSVG(content string, size QRSize) (string, error) {
-	qr, err := qrcode.New(content, g.config.DefaultRecovery)
-	if err != nil {
-		return "", err
-	}
-
-	return qr.ToSmallString(false), nil
+// GenerateWithLogo generates a QR code with a logo in the center. The code
+// uses the highest recovery level, and logoSize (in pixels) is capped so the
+// logo never hides more modules than the code can recover; 0 selects the
+// largest allowed size.
+func (g *Generator) GenerateWithLogo(content string, size QRSize, logo image.Image, logoSize int) ([]byte, error) {
+	opts := g.DefaultOptions(content, size)
+	opts.Logo = logo
+	opts.LogoSize = logoSize
+	return g.GeneratePNGWithOptions(opts)
 }
 
 // ContentHash generates a hash for caching purposes (FNV-1a is ~20x faster than SHA256)
 func ContentHash(content string, size QRSize) string {
 	h := fnv.New64a()
 	h.Write([]byte(content))
-	h.Write([]byte{byte(size >> 8), byte(size)})
+	// All 64 bits: pixel sizes can exceed 65535
+	h.Write(binary.BigEndian.AppendUint64(nil, uint64(size)))
 	return strconv.FormatUint(h.Sum64(), 36) // Base36 for compact representation
 }
 
-// resizeImage is a simple nearest-neighbor resize
-func resizeImage(src image.Image, width, height int) image.Image {
-	srcBounds := src.Bounds()
-	srcW := srcBounds.Dx()
-	srcH := srcBounds.Dy()
-
-	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+// CacheKey hashes the content together with every option that changes the
+// image. extra carries what the options cannot identify themselves, such as
+// the logo or template.
+func (opts GenerateOptions) CacheKey(extra ...string) string {
+	parts := append([]string{
+		opts.Content,
+		strconv.Itoa(int(opts.Recovery())),
+		colorKey(opts.ForegroundColor),
+		colorKey(opts.BackgroundColor),
+		strconv.Itoa(opts.QuietZone),
+		strconv.Itoa(opts.LogoSize),
+		strconv.Itoa(opts.DPI),
+		opts.Style.key(),
+		string(opts.Format.rasterFormat()),
+	}, extra...)
+	return ContentHash(strings.Join(parts, "\x00"), opts.Size)
+}
 
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			srcX := x * srcW / width
-			srcY := y * srcH / height
-			dst.Set(x, y, src.At(srcBounds.Min.X+srcX, srcBounds.Min.Y+srcY))
-		}
+// colorKey formats an optional colour for cache keys
+func colorKey(c color.Color) string {
+	if c == nil {
+		return ""
 	}
+	return ColorHex(c)
+}
 
+// resizeImage scales src to width x height with a Catmull-Rom filter so
+// logos stay smooth at any size
+func resizeImage(src image.Image, width, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
 	return dst
 }
 
@@ -0,0 +1,54 @@
+package qrgen
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+)
+
+func TestCacheKey(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	base := g.DefaultOptions(testContent, SizeMedium)
+	key := base.CacheKey()
+
+	if again := g.DefaultOptions(testContent, SizeMedium).CacheKey(); again != key {
+		t.Errorf("equal options give keys %q and %q", key, again)
+	}
+	png := base
+	png.Format = FormatPNG
+	if png.CacheKey() != key {
+		t.Error("the empty format and png should share a key")
+	}
+
+	changes := map[string]func(*GenerateOptions){
+		"content":    func(o *GenerateOptions) { o.Content += "x" },
+		"size":       func(o *GenerateOptions) { o.Size = SizeSmall },
+		"recovery":   func(o *GenerateOptions) { o.RecoveryLevel = qrcode.Highest },
+		"foreground": func(o *GenerateOptions) { o.ForegroundColor = color.NRGBA{0, 0x33, 0x66, 0xff} },
+		"background": func(o *GenerateOptions) { o.BackgroundColor = color.Transparent },
+		"quiet zone": func(o *GenerateOptions) { o.QuietZone = 2 },
+		"logo size":  func(o *GenerateOptions) { o.LogoSize = 40 },
+		"dpi":        func(o *GenerateOptions) { o.DPI = 300 },
+		"style":      func(o *GenerateOptions) { o.Style = &Style{Shape: ShapeDot} },
+		"format":     func(o *GenerateOptions) { o.Format = FormatWebP },
+	}
+	seen := map[string]string{key: "base"}
+	for name, change := range changes {
+		opts := base
+		change(&opts)
+		k := opts.CacheKey()
+		if other, ok := seen[k]; ok {
+			t.Errorf("%s: key %q collides with %s", name, k, other)
+		}
+		seen[k] = name
+	}
+
+	// Extra parts identify what the options cannot, such as the template
+	if base.CacheKey("card") == key || base.CacheKey("card") == base.CacheKey("compact") {
+		t.Error("extra parts do not change the key")
+	}
+	if base.CacheKey("a", "bc") == base.CacheKey("ab", "c") {
+		t.Error("extra parts are not separated")
+	}
+}
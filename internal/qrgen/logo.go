@@ -0,0 +1,131 @@
+package qrgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/draw"
+)
+
+// MaxLogoRatio is the largest logo plate side as a fraction of the symbol
+// side. At 0.25 the plate hides about 6% of the modules, well inside the
+// 30% the highest recovery level can restore.
+const MaxLogoRatio = 0.25
+
+// MaxLogoBytes is the largest accepted logo upload
+const MaxLogoBytes = 512 << 10
+
+// Decoded logo limits in pixels
+const (
+	maxLogoDimension = 4096
+	logoRasterSize   = 512 // Longest side SVG logos are rasterised at
+)
+
+// Logo upload errors
+var (
+	ErrInvalidLogo  = errors.New("logo must be a PNG or SVG image")
+	ErrLogoTooLarge = fmt.Errorf("logo must be at most %d KB and %dx%d pixels", MaxLogoBytes>>10, maxLogoDimension, maxLogoDimension)
+)
+
+// DecodeLogo decodes an uploaded PNG or SVG logo. SVG logos are rasterised
+// so they can be scaled like bitmaps.
+func DecodeLogo(data []byte) (image.Image, error) {
+	if len(data) > MaxLogoBytes {
+		return nil, ErrLogoTooLarge
+	}
+	if http.DetectContentType(data) == "image/png" {
+		cfg, err := png.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrInvalidLogo
+		}
+		if cfg.Width > maxLogoDimension || cfg.Height > maxLogoDimension {
+			return nil, ErrLogoTooLarge
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrInvalidLogo
+		}
+		return img, nil
+	}
+	if bytes.Contains(data, []byte("<svg")) {
+		return rasterizeSVG(data)
+	}
+	return nil, ErrInvalidLogo
+}
+
+// rasterizeSVG renders an SVG document with its longest side at logoRasterSize
+func rasterizeSVG(data []byte) (image.Image, error) {
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
+	if err != nil || icon.ViewBox.W <= 0 || icon.ViewBox.H <= 0 {
+		return nil, ErrInvalidLogo
+	}
+
+	w, h := logoRasterSize, logoRasterSize
+	if icon.ViewBox.W > icon.ViewBox.H {
+		h = max(1, int(float64(logoRasterSize)*icon.ViewBox.H/icon.ViewBox.W))
+	} else {
+		w = max(1, int(float64(logoRasterSize)*icon.ViewBox.W/icon.ViewBox.H))
+	}
+	icon.SetTarget(0, 0, float64(w), float64(h))
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	scanner := rasterx.NewScannerGV(w, h, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(w, h, scanner), 1)
+	return img, nil
+}
+
+// GenerateLogoImage renders the QR code at the highest recovery level with
+// logo in the centre; see overlayLogo. logoSize <= 0 selects the largest
+// logo allowed.
+func (g *Generator) GenerateLogoImage(content string, size QRSize, logo image.Image, logoSize int) (image.Image, error) {
+	opts := g.DefaultOptions(content, size)
+	opts.Logo = logo
+	opts.LogoSize = logoSize
+	return g.GenerateImageWithOptions(opts)
+}
+
+// overlayLogo centres logo on a plate of the background colour. dst holds
+// a symbol symbolSide pixels square drawn with module-pixel modules. The
+// plate keeps one module of clearance around the logo and its side is
+// capped at MaxLogoRatio of the symbol.
+func overlayLogo(dst *image.RGBA, symbolSide, module int, logo image.Image, logoSize int, bg color.Color) {
+	if logo.Bounds().Empty() {
+		return
+	}
+	bounds := dst.Bounds()
+
+	clearance := module
+	maxPlate := int(float64(symbolSide) * MaxLogoRatio)
+	plate := logoSize + 2*clearance
+	if logoSize <= 0 || plate > maxPlate {
+		plate = maxPlate
+	}
+	inner := plate - 2*clearance
+	if inner <= 0 {
+		return
+	}
+
+	// Fit the logo inside the plate, keeping its aspect ratio
+	w, h := inner, inner
+	lb := logo.Bounds()
+	if lb.Dx() > lb.Dy() {
+		h = max(1, inner*lb.Dy()/lb.Dx())
+	} else {
+		w = max(1, inner*lb.Dx()/lb.Dy())
+	}
+
+	px := (bounds.Dx() - plate) / 2
+	py := (bounds.Dy() - plate) / 2
+	draw.Draw(dst, image.Rect(px, py, px+plate, py+plate), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	lx := (bounds.Dx() - w) / 2
+	ly := (bounds.Dy() - h) / 2
+	draw.Draw(dst, image.Rect(lx, ly, lx+w, ly+h), resizeImage(logo, w, h), image.Point{}, draw.Over)
+}
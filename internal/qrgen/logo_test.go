@@ -0,0 +1,164 @@
+package qrgen
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/draw"
+)
+
+// encodeTestPNG returns a w x h PNG filled with c
+func encodeTestPNG(t *testing.T, w, h int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeLogo(t *testing.T) {
+	red := color.NRGBA{R: 0xff, A: 0xff}
+
+	img, err := DecodeLogo(encodeTestPNG(t, 40, 20, red))
+	if err != nil || img.Bounds().Dx() != 40 || img.Bounds().Dy() != 20 {
+		t.Errorf("PNG logo = %v, %v", img.Bounds(), err)
+	}
+
+	// SVG logos are rasterised with their longest side at logoRasterSize
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 200 100"><rect width="200" height="100" fill="#ff0000"/></svg>`
+	img, err = DecodeLogo([]byte(svg))
+	if err != nil {
+		t.Fatalf("SVG logo: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != logoRasterSize || b.Dy() != logoRasterSize/2 {
+		t.Errorf("SVG logo is %dx%d, want %dx%d", b.Dx(), b.Dy(), logoRasterSize, logoRasterSize/2)
+	}
+	if !sameColor(img.At(logoRasterSize/2, logoRasterSize/4), red) {
+		t.Errorf("SVG logo centre is %v, want red", img.At(logoRasterSize/2, logoRasterSize/4))
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"too many bytes", append(encodeTestPNG(t, 1, 1, red), make([]byte, MaxLogoBytes)...), ErrLogoTooLarge},
+		{"too wide", encodeTestPNG(t, maxLogoDimension+1, 1, red), ErrLogoTooLarge},
+		{"too tall", encodeTestPNG(t, 1, maxLogoDimension+1, red), ErrLogoTooLarge},
+		{"truncated PNG", encodeTestPNG(t, 8, 8, red)[:40], ErrInvalidLogo},
+		{"JPEG", []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), ErrInvalidLogo},
+		{"text", []byte("not an image"), ErrInvalidLogo},
+		{"broken SVG", []byte(`<svg xmlns="http://www.w3.org/2000/svg"`), ErrInvalidLogo},
+		{"SVG without size", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), ErrInvalidLogo},
+	}
+	for _, tt := range tests {
+		if _, err := DecodeLogo(tt.data); err != tt.want {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestOverlayLogoPlate(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	logo := image.NewUniform(red)
+
+	tests := []struct {
+		name     string
+		logo     image.Image
+		logoSize int
+		plate    int // Plate side; the symbol is 100px with 4px modules
+		logoW    int
+		logoH    int
+	}{
+		{"largest", image.NewRGBA(image.Rect(0, 0, 10, 10)), 0, 25, 17, 17},
+		{"capped", image.NewRGBA(image.Rect(0, 0, 10, 10)), 1000, 25, 17, 17},
+		{"small", image.NewRGBA(image.Rect(0, 0, 10, 10)), 5, 13, 5, 5},
+		{"wide", image.NewRGBA(image.Rect(0, 0, 20, 10)), 0, 25, 17, 8},
+	}
+	for _, tt := range tests {
+		draw.Draw(tt.logo.(*image.RGBA), tt.logo.Bounds(), logo, image.Point{}, draw.Src)
+		dst := image.NewRGBA(image.Rect(0, 0, 100, 100))
+		draw.Draw(dst, dst.Bounds(), image.Black, image.Point{}, draw.Src)
+		overlayLogo(dst, 100, 4, tt.logo, tt.logoSize, color.White)
+
+		// The plate is centred, background-coloured and nothing else changes
+		p := (100 - tt.plate) / 2
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 100; x++ {
+				inPlate := x >= p && x < p+tt.plate && y >= p && y < p+tt.plate
+				lx, ly := (100-tt.logoW)/2, (100-tt.logoH)/2
+				inLogo := x >= lx && x < lx+tt.logoW && y >= ly && y < ly+tt.logoH
+				want := color.Color(color.Black)
+				switch {
+				case inLogo:
+					want = red
+				case inPlate:
+					want = color.White
+				}
+				if !sameColor(dst.At(x, y), want) {
+					t.Fatalf("%s: pixel (%d,%d) = %v, want %v", tt.name, x, y, dst.At(x, y), want)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateSVGLogo(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	opts := g.DefaultOptions(testContent, SizeMedium)
+	logo := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	draw.Draw(logo, logo.Bounds(), image.NewUniform(color.RGBA{R: 0xff, A: 0xff}), image.Point{}, draw.Src)
+	opts.Logo = logo
+
+	doc, err := g.GenerateSVGWithOptions(opts)
+	if err != nil {
+		t.Fatalf("GenerateSVGWithOptions: %v", err)
+	}
+	elements := parseSVG(t, doc)
+	if len(elements["image"]) != 1 {
+		t.Fatalf("got %d images, want 1", len(elements["image"]))
+	}
+
+	// The logo sits in the centre of a plate of cleared modules, at the
+	// highest recovery level
+	matrix, err := Matrix(testContent, opts.Recovery())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(matrix)
+	plate := svgLogoPlate(opts, n, n+2*DefaultQuietZone)
+	if plate%2 != n%2 || plate > int(float64(n)*MaxLogoRatio) || plate < 5 {
+		t.Fatalf("plate = %d modules on a %d-module symbol", plate, n)
+	}
+	start := DefaultQuietZone + (n-plate)/2
+	img := elements["image"][0]
+	if img["x"] != strconv.Itoa(start+1) || img["y"] != img["x"] || img["width"] != strconv.Itoa(plate-2) || img["height"] != img["width"] {
+		t.Errorf("image at %s,%s size %sx%s, want %d,%d size %d", img["x"], img["y"], img["width"], img["height"], start+1, start+1, plate-2)
+	}
+	if !strings.HasPrefix(img["href"], "data:image/png;base64,") {
+		t.Errorf("image href = %.40q, want a PNG data URL", img["href"])
+	}
+
+	for _, m := range runPattern.FindAllStringSubmatch(elements["path"][0]["d"], -1) {
+		x, _ := strconv.Atoi(m[1])
+		y, _ := strconv.Atoi(m[2])
+		run, _ := strconv.Atoi(m[3])
+		if y >= start && y < start+plate && x < start+plate && x+run > start {
+			t.Errorf("run %q crosses the logo plate", m[0])
+		}
+	}
+
+	// Styled codes keep the logo too
+	opts.Style = &Style{Shape: ShapeDot}
+	if doc, err = g.GenerateSVGWithOptions(opts); err != nil || len(parseSVG(t, doc)["image"]) != 1 {
+		t.Errorf("styled SVG lost the logo (%v)", err)
+	}
+}
@@ -0,0 +1,388 @@
+package qrgen
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// pdfFont is the family name fontRegular is registered under
+const pdfFont = "DejaVu"
+
+// Text limits for GeneratePDF: the title wraps to at most pdfTitleLines
+// lines, and detail lines shrink to no less than minPDFTextScale of their
+// size before being cut short
+const (
+	pdfTitleLines   = 2
+	minPDFTextScale = 0.7
+)
+
+// PageSize is a PDF page size in millimetres
+type PageSize struct {
+	Name   string
+	Width  float64
+	Height float64
+}
+
+// Standard page sizes
+var (
+	PageA4 = PageSize{Name: "a4", Width: 210, Height: 297}
+	PageA5 = PageSize{Name: "a5", Width: 148, Height: 210}
+	PageA6 = PageSize{Name: "a6", Width: 105, Height: 148}
+)
+
+// Custom page size limits in millimetres
+const (
+	MinPageSize = 50
+	MaxPageSize = 1000
+)
+
+// ErrInvalidPageSize is returned for unknown or out-of-range page sizes
+var ErrInvalidPageSize = errors.New("page must be a4, a5, a6 or WIDTHxHEIGHT in mm (50-1000)")
+
+// ParsePageSize parses a page size name or a custom "WIDTHxHEIGHT" size in
+// millimetres (e.g., "100x150"). An empty string selects A4.
+func ParsePageSize(s string) (PageSize, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "a4":
+		return PageA4, nil
+	case "a5":
+		return PageA5, nil
+	case "a6":
+		return PageA6, nil
+	}
+
+	w, h, ok := strings.Cut(strings.TrimSuffix(s, "mm"), "x")
+	if !ok {
+		return PageSize{}, ErrInvalidPageSize
+	}
+	width, errW := strconv.ParseFloat(w, 64)
+	height, errH := strconv.ParseFloat(h, 64)
+	if errW != nil || errH != nil ||
+		width < MinPageSize || width > MaxPageSize || height < MinPageSize || height > MaxPageSize {
+		return PageSize{}, ErrInvalidPageSize
+	}
+	return PageSize{Name: "custom", Width: width, Height: height}, nil
+}
+
+// PDFLine is a labelled line of text printed beneath the QR code
+type PDFLine struct {
+	Label string // e.g., "Số tài khoản"
+	Value string
+}
+
+// PDFOptions holds options for a print-ready PDF page
+type PDFOptions struct {
+	QR    GenerateOptions // The code: content, recovery, colours, quiet zone, style and logo (Size, DPI and Format are ignored)
+	Page  PageSize
+	Title string    // Heading above the QR code (optional)
+	Lines []PDFLine // Payment details laid out beneath the QR code
+}
+
+// GeneratePDF renders a one-page PDF with the QR code centred in the upper
+// part of the page, followed by the title and detail lines. Text sizes
+// scale with the page width so the same layout works from A6 table tents to
+// A4 standees. At least DefaultQuietZone modules are kept clear between the
+// code and the text. Text that does not fit is shrunk, then cut short with an
+// ellipsis, so nothing runs off the page. Plain codes are drawn as vector
+// rectangles; styled codes and codes with a logo are embedded as a PNG
+// rendered at PrintDPI.
+func (g *Generator) GeneratePDF(opts PDFOptions) ([]byte, error) {
+	qr := opts.QR
+	matrix, err := Matrix(qr.Content, qr.Recovery())
+	if err != nil {
+		return nil, err
+	}
+
+	page := opts.Page
+	if page.Width == 0 || page.Height == 0 {
+		page = PageA4
+	}
+
+	pdf := newPDF(page)
+	pdf.AddPage()
+
+	margin := page.Width * 0.08
+	scale := page.Width / PageA4.Width
+
+	width := page.Width - 2*margin
+	y := margin
+	if opts.Title != "" {
+		pdf.SetFont(pdfFont, "", 20*scale)
+		for _, line := range wrapPDFText(pdf, opts.Title, width, pdfTitleLines) {
+			pdf.SetXY(margin, y)
+			pdf.CellFormat(width, 10*scale, line, "", 0, "C", false, 0, "")
+			y += 10 * scale
+		}
+	}
+
+	quietZone := max(qr.QuietZone, 0)
+	x, y, module := pdfCodeLayout(page, margin, y, len(matrix), quietZone)
+	if err := g.drawPDFCode(pdf, qr, matrix, x, y, module, quietZone); err != nil {
+		return nil, err
+	}
+	y += module * float64(len(matrix)+max(quietZone, DefaultQuietZone))
+
+	for _, row := range layoutPDFLines(pdf, opts.Lines, width, page.Height-margin-y, scale) {
+		pdf.SetFont(pdfFont, "", row.size)
+		if row.label {
+			pdf.SetTextColor(110, 110, 110)
+		} else {
+			pdf.SetTextColor(0, 0, 0)
+		}
+		pdf.SetXY(margin, y)
+		pdf.CellFormat(width, row.height, row.text, "", 0, "C", false, 0, "")
+		y += row.height + row.gap
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfRow is one line of detail text as laid out by layoutPDFLines
+type pdfRow struct {
+	text   string
+	label  bool
+	size   float64 // Font size in points
+	height float64 // Line height in mm
+	gap    float64 // Space below the line in mm
+}
+
+// layoutPDFLines wraps the labels and values of lines to width mm. The text
+// shrinks in steps down to minPDFTextScale until it fits in height mm; if it
+// still does not, the rows that fit are kept and the last is cut short with
+// an ellipsis.
+func layoutPDFLines(pdf *gofpdf.Fpdf, lines []PDFLine, width, height, scale float64) []pdfRow {
+	var rows []pdfRow
+	for step := 0; ; step++ {
+		f := max(1-0.1*float64(step), minPDFTextScale)
+		s := scale * f
+		rows = rows[:0]
+		total := 0.0
+		for _, line := range lines {
+			if strings.TrimSpace(line.Value) == "" {
+				continue
+			}
+			pdf.SetFont(pdfFont, "", 10*s)
+			for _, text := range wrapPDFText(pdf, line.Label, width, 0) {
+				rows = append(rows, pdfRow{text: text, label: true, size: 10 * s, height: 5 * s})
+			}
+			pdf.SetFont(pdfFont, "", 15*s)
+			for _, text := range wrapPDFText(pdf, line.Value, width, 0) {
+				rows = append(rows, pdfRow{text: text, size: 15 * s, height: 7 * s})
+			}
+			rows[len(rows)-1].gap = 2.5 * s
+		}
+		for _, row := range rows {
+			total += row.height + row.gap
+		}
+		if total <= height {
+			return rows
+		}
+		if f == minPDFTextScale {
+			break
+		}
+	}
+
+	// Keep the rows that fit, ending on a value rather than a bare label
+	n, used := 0, 0.0
+	for n < len(rows) && used+rows[n].height <= height {
+		used += rows[n].height + rows[n].gap
+		n++
+	}
+	for n > 0 && rows[n-1].label {
+		n--
+	}
+	if n == 0 {
+		return nil
+	}
+	rows = rows[:n]
+	last := &rows[n-1]
+	pdf.SetFont(pdfFont, "", last.size)
+	last.text = fitPDFText(pdf, last.text+"…", width)
+	return rows
+}
+
+// wrapPDFText splits text into lines no wider than width mm in the current
+// font, breaking between words and inside words too long for a line. With
+// maxLines above zero, lines beyond it are dropped and the last one kept
+// ends with an ellipsis.
+func wrapPDFText(pdf *gofpdf.Fpdf, text string, width float64, maxLines int) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if line != "" && pdf.GetStringWidth(line+" "+word) <= width {
+				line += " " + word
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// Break words wider than the line at the last rune that fits
+			for runes := []rune(word); ; {
+				n := len(runes)
+				for n > 1 && pdf.GetStringWidth(string(runes[:n])) > width {
+					n--
+				}
+				if n == len(runes) {
+					line = string(runes)
+					break
+				}
+				lines = append(lines, string(runes[:n]))
+				runes = runes[n:]
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if maxLines > 0 && len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = fitPDFText(pdf, lines[maxLines-1]+"…", width)
+	}
+	return lines
+}
+
+// fitPDFText shortens text with an ellipsis until it fits maxWidth mm in
+// the current font
+func fitPDFText(pdf *gofpdf.Fpdf, text string, maxWidth float64) string {
+	if pdf.GetStringWidth(text) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := string(runes) + "…"
+		if pdf.GetStringWidth(candidate) <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// pdfCodeLayout places an n-module code below top: the code and a clear
+// space of max(quietZone, DefaultQuietZone) modules on each side take the
+// page width less margins, or 65% of the height left so the text fits. It
+// returns the top-left corner of the first module and the module size.
+func pdfCodeLayout(page PageSize, margin, top float64, n, quietZone int) (x, y, module float64) {
+	clear := max(quietZone, DefaultQuietZone)
+	box := page.Width - 2*margin
+	if maxBox := (page.Height - top - margin) * 0.65; box > maxBox {
+		box = maxBox
+	}
+	module = box / float64(n+2*clear)
+	x = (page.Width - module*float64(n)) / 2
+	y = top + float64(clear)*module
+	return x, y, module
+}
+
+// drawPDFCode draws the code with its top-left module at (x, y) and the
+// background over quietZone modules around it
+func (g *Generator) drawPDFCode(pdf *gofpdf.Fpdf, qr GenerateOptions, matrix [][]bool, x, y, module float64, quietZone int) error {
+	n := len(matrix)
+	pad := float64(quietZone) * module
+	outer := float64(n)*module + 2*pad
+
+	if qr.Style.plain() && qr.Logo == nil {
+		bg := qr.BackgroundColor
+		if bg == nil {
+			bg = color.White
+		}
+		if setPDFFill(pdf, bg) {
+			pdf.Rect(x-pad, y-pad, outer, outer, "F")
+		}
+		fg := qr.ForegroundColor
+		if fg == nil {
+			fg = g.config.ForegroundColor
+		}
+		drawPDFMatrix(pdf, matrix, x, y, float64(n)*module, fg)
+		return nil
+	}
+
+	// Render whole pixels per module at print resolution
+	modulePx := max(int(math.Ceil(module/25.4*PrintDPI)), minStyledModule)
+	qr.QuietZone = quietZone
+	qr.Size = QRSize((n + 2*quietZone) * modulePx)
+	img, err := g.GenerateImageWithOptions(qr)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	info := pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, &buf)
+	if info == nil {
+		return pdf.Error()
+	}
+	pdf.ImageOptions("qr", x-pad, y-pad, outer, outer, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	return pdf.Error()
+}
+
+// newPDF creates a document of the given page size with the embedded font
+func newPDF(page PageSize) *gofpdf.Fpdf {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "mm",
+		Size:    gofpdf.SizeType{Wd: page.Width, Ht: page.Height},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetCreator("MaxQR API", true)
+	pdf.AddUTF8FontFromBytes(pdfFont, "", fontRegular)
+	return pdf
+}
+
+// drawPDFMatrix draws the dark modules of matrix as filled rectangles in a
+// side x side square at (x, y). Horizontal runs are merged into one
+// rectangle to keep the content stream small.
+func drawPDFMatrix(pdf *gofpdf.Fpdf, matrix [][]bool, x, y, side float64, fg color.Color) {
+	if len(matrix) == 0 {
+		return
+	}
+	if fg == nil {
+		fg = color.Black
+	}
+	if !setPDFFill(pdf, fg) {
+		return
+	}
+	defer pdf.SetAlpha(1, "Normal")
+
+	module := side / float64(len(matrix))
+	for row, modules := range matrix {
+		for col := 0; col < len(modules); {
+			if !modules[col] {
+				col++
+				continue
+			}
+			run := 1
+			for col+run < len(modules) && modules[col+run] {
+				run++
+			}
+			pdf.Rect(x+float64(col)*module, y+float64(row)*module, float64(run)*module, module, "F")
+			col += run
+		}
+	}
+}
+
+// setPDFFill sets the fill colour and opacity to c. It reports false, and
+// changes nothing, when c is fully transparent.
+func setPDFFill(pdf *gofpdf.Fpdf, c color.Color) bool {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A == 0 {
+		return false
+	}
+	pdf.SetFillColor(int(n.R), int(n.G), int(n.B))
+	pdf.SetAlpha(float64(n.A)/0xff, "Normal")
+	return true
+}
@@ -0,0 +1,277 @@
+package qrgen
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParsePageSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want PageSize
+		err  bool
+	}{
+		{in: "", want: PageA4},
+		{in: "a4", want: PageA4},
+		{in: " A5 ", want: PageA5},
+		{in: "a6", want: PageA6},
+		{in: "100x150", want: PageSize{Name: "custom", Width: 100, Height: 150}},
+		{in: "100x150mm", want: PageSize{Name: "custom", Width: 100, Height: 150}},
+		{in: "50x1000", want: PageSize{Name: "custom", Width: 50, Height: 1000}},
+		{in: "49x150", err: true},
+		{in: "100x1001", err: true},
+		{in: "letter", err: true},
+		{in: "100x", err: true},
+		{in: "x150", err: true},
+		{in: "100by150", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePageSize(tt.in)
+		if tt.err {
+			if !errors.Is(err, ErrInvalidPageSize) {
+				t.Errorf("ParsePageSize(%q) error = %v, want ErrInvalidPageSize", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePageSize(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestPDFCodeLayoutClearSpace(t *testing.T) {
+	const margin, top, n = 10.0, 40.0, 29
+	for _, quietZone := range []int{0, 2, 4, 8} {
+		x, y, module := pdfCodeLayout(PageA4, margin, top, n, quietZone)
+		clear := float64(max(quietZone, DefaultQuietZone)) * module
+		if y-top < clear-1e-9 {
+			t.Errorf("quiet zone %d: %.2fmm above the code, want at least %.2fmm", quietZone, y-top, clear)
+		}
+		if x-clear < margin-1e-9 {
+			t.Errorf("quiet zone %d: clear space starts at %.2fmm, inside the %.0fmm margin", quietZone, x-clear, margin)
+		}
+		if right := x + module*n + clear; right > PageA4.Width-margin+1e-9 {
+			t.Errorf("quiet zone %d: clear space ends at %.2fmm, past the margin", quietZone, right)
+		}
+	}
+
+	// A short page caps the code so the text below still fits
+	short := PageSize{Width: 200, Height: 100}
+	_, y, module := pdfCodeLayout(short, margin, top, n, 0)
+	if bottom := y + module*float64(n+DefaultQuietZone); bottom > short.Height-margin {
+		t.Errorf("code ends at %.2fmm on a %.0fmm page", bottom, short.Height)
+	}
+}
+
+// pdfContent returns the decompressed streams of a PDF
+func pdfContent(t *testing.T, data []byte) string {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("output is not a PDF: %q", data[:min(len(data), 16)])
+	}
+	var sb strings.Builder
+	rest := data
+	for {
+		i := bytes.Index(rest, []byte("stream\n"))
+		if i < 0 {
+			return sb.String()
+		}
+		rest = rest[i+len("stream\n"):]
+		j := bytes.Index(rest, []byte("endstream"))
+		if j < 0 {
+			t.Fatal("unterminated PDF stream")
+		}
+		if r, err := zlib.NewReader(bytes.NewReader(rest[:j])); err == nil {
+			b, _ := io.ReadAll(r)
+			sb.Write(b)
+		} else {
+			sb.Write(rest[:j])
+		}
+		sb.WriteByte('\n')
+		rest = rest[j+len("endstream"):]
+	}
+}
+
+func TestGeneratePDFColors(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	qr := g.DefaultOptions(testContent, SizeMedium)
+	qr.ForegroundColor = color.RGBA{R: 0x80, A: 0xff}
+	qr.BackgroundColor = color.RGBA{R: 0xff, G: 0xff, B: 0xcc, A: 0xff}
+
+	data, err := g.GeneratePDF(PDFOptions{QR: qr, Page: PageA6, Title: "VietQR"})
+	if err != nil {
+		t.Fatalf("GeneratePDF: %v", err)
+	}
+	content := pdfContent(t, data)
+	for _, op := range []string{"0.502 0.000 0.000 rg", "1.000 1.000 0.800 rg"} {
+		if !strings.Contains(content, op) {
+			t.Errorf("PDF does not set fill %q", op)
+		}
+	}
+	if bytes.Contains(data, []byte("/Subtype /Image")) {
+		t.Error("plain code was rasterised")
+	}
+
+	// A transparent background paints no rectangle behind the code
+	qr.BackgroundColor = color.Transparent
+	data, err = g.GeneratePDF(PDFOptions{QR: qr})
+	if err != nil {
+		t.Fatalf("GeneratePDF(transparent): %v", err)
+	}
+	if strings.Contains(pdfContent(t, data), "1.000 1.000 0.800 rg") {
+		t.Error("transparent background was painted")
+	}
+}
+
+func TestGeneratePDFStyledAndLogo(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	logo := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for i := range logo.Pix {
+		logo.Pix[i] = 0xff
+	}
+
+	tests := map[string]func(*GenerateOptions){
+		"style": func(o *GenerateOptions) { o.Style = &Style{Shape: ShapeDot, Eye: EyeCircle} },
+		"logo":  func(o *GenerateOptions) { o.Logo = logo },
+	}
+	for name, set := range tests {
+		qr := g.DefaultOptions(testContent, SizeMedium)
+		set(&qr)
+		data, err := g.GeneratePDF(PDFOptions{QR: qr, Lines: []PDFLine{{Label: "Số tài khoản", Value: "1234567890"}}})
+		if err != nil {
+			t.Fatalf("%s: GeneratePDF: %v", name, err)
+		}
+		pdfContent(t, data)
+		if !bytes.Contains(data, []byte("/Subtype /Image")) {
+			t.Errorf("%s: PDF embeds no image of the code", name)
+		}
+	}
+}
+
+func TestGeneratePDFTextStaysOnPage(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	message := strings.Repeat("Thanh toán hóa đơn tiền điện tháng mười hai ", 12)
+	lines := []PDFLine{
+		{Label: "Ngân hàng", Value: "Ngân hàng TMCP Ngoại Thương Việt Nam"},
+		{Label: "Chủ tài khoản", Value: strings.Repeat("CONG TY TNHH THUONG MAI DICH VU ", 4)},
+		{Label: "Số tài khoản", Value: "1234567890"},
+		{Label: "Nội dung", Value: message},
+	}
+
+	data, err := g.GeneratePDF(PDFOptions{
+		QR:    g.DefaultOptions(testContent, SizeMedium),
+		Page:  PageA6,
+		Title: strings.Repeat("Quầy thanh toán số một ", 8),
+		Lines: lines,
+	})
+	if err != nil {
+		t.Fatalf("GeneratePDF: %v", err)
+	}
+	content := pdfContent(t, data)
+
+	// Text positions are baselines in points from the bottom-left corner
+	const pt = 72 / 25.4
+	margin := PageA6.Width * 0.08 * pt
+	texts := 0
+	for _, op := range strings.Split(content, "BT ")[1:] {
+		var x, y float64
+		if _, err := fmt.Sscanf(op, "%f %f Td", &x, &y); err != nil {
+			continue
+		}
+		texts++
+		if y < margin || y > PageA6.Height*pt || x < 0 || x > PageA6.Width*pt {
+			t.Errorf("text at (%.1f, %.1f)pt is outside the %.0fx%.0fmm page margins", x, y, PageA6.Width, PageA6.Height)
+		}
+	}
+	if texts == 0 {
+		t.Fatal("PDF has no text")
+	}
+	if !strings.Contains(content, "\x20\x26") {
+		t.Error("overflowing text was not cut short with an ellipsis")
+	}
+}
+
+func TestLayoutPDFLines(t *testing.T) {
+	pdf := newPDF(PageA6)
+	pdf.AddPage()
+	lines := []PDFLine{
+		{Label: "Số tài khoản", Value: "1234567890"},
+		{Label: "Bỏ qua", Value: " "},
+		{Label: "Nội dung", Value: strings.Repeat("thanh toan ", 20)},
+	}
+
+	height := func(rows []pdfRow) float64 {
+		total := 0.0
+		for _, row := range rows {
+			total += row.height + row.gap
+		}
+		return total
+	}
+
+	// Room to spare keeps full size
+	rows := layoutPDFLines(pdf, lines, 80, 200, 0.5)
+	if rows[0].size != 5 || !rows[0].label || rows[1].text != "1234567890" {
+		t.Errorf("rows = %+v", rows[:2])
+	}
+	for _, row := range rows {
+		if row.text == "Bỏ qua" {
+			t.Error("blank value kept its label")
+		}
+		pdf.SetFont(pdfFont, "", row.size)
+		if w := pdf.GetStringWidth(row.text); w > 80 {
+			t.Errorf("row %q is %.1fmm wide", row.text, w)
+		}
+	}
+
+	// Less room shrinks the text before cutting it
+	full := height(rows)
+	shrunk := layoutPDFLines(pdf, lines, 80, full*0.85, 0.5)
+	if got := height(shrunk); got > full*0.85 || shrunk[0].size >= 5 {
+		t.Errorf("shrunk layout is %.1fmm at %.1fpt, want under %.1fmm", got, shrunk[0].size, full*0.85)
+	}
+	if last := shrunk[len(shrunk)-1]; strings.HasSuffix(last.text, "…") {
+		t.Errorf("text was cut although shrinking fits: %q", last.text)
+	}
+
+	cut := layoutPDFLines(pdf, lines, 80, 12, 0.5)
+	if got := height(cut); got > 12 {
+		t.Errorf("cut layout is %.1fmm, want at most 12mm", got)
+	}
+	if last := cut[len(cut)-1]; last.label || !strings.HasSuffix(last.text, "…") {
+		t.Errorf("last row %+v, want a value ending in an ellipsis", last)
+	}
+	if rows := layoutPDFLines(pdf, lines, 80, 1, 0.5); rows != nil {
+		t.Errorf("no room: rows = %+v", rows)
+	}
+}
+
+func TestWrapPDFText(t *testing.T) {
+	pdf := newPDF(PageA6)
+	pdf.AddPage()
+	pdf.SetFont(pdfFont, "", 12)
+
+	if got := wrapPDFText(pdf, "Ban 01", 50, 0); len(got) != 1 || got[0] != "Ban 01" {
+		t.Errorf("short text = %q", got)
+	}
+	long := strings.Repeat("thanh toán ", 10) + strings.Repeat("x", 60) + "\nDòng mới"
+	got := wrapPDFText(pdf, long, 40, 0)
+	if len(got) < 4 || got[len(got)-1] != "Dòng mới" {
+		t.Errorf("wrapped = %q", got)
+	}
+	for _, line := range got {
+		if w := pdf.GetStringWidth(line); w > 40 {
+			t.Errorf("line %q is %.1fmm wide", line, w)
+		}
+	}
+	limited := wrapPDFText(pdf, long, 40, 2)
+	if len(limited) != 2 || !strings.HasSuffix(limited[1], "…") || pdf.GetStringWidth(limited[1]) > 40 {
+		t.Errorf("limited to two lines = %q", limited)
+	}
+}
@@ -0,0 +1,124 @@
+package qrgen
+
+import (
+	"errors"
+	"math"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Common thermal printer paper widths in millimetres
+const (
+	Paper58mm = 58
+	Paper80mm = 80
+)
+
+// Default printer settings
+const (
+	DefaultPrinterDPI   = 203 // 8 dots/mm, the usual thermal print head
+	DefaultPaperWidth   = Paper80mm
+	MinPrinterDPI       = 100
+	MaxPrinterDPI       = 600
+	MaxPaperWidth       = 300
+	printerPaperMargin  = 5   // Unprintable margin per side on receipt printers (mm)
+	printerModuleTarget = 1.0 // Preferred module size on paper (mm)
+)
+
+// Printer errors
+var (
+	ErrInvalidPrinterDPI    = errors.New("dpi must be between 100 and 600")
+	ErrInvalidPaperWidth    = errors.New("printer width must be between 20 and 300 mm")
+	ErrPrinterWidthTooSmall = errors.New("QR code does not fit the printer width")
+)
+
+// PrinterOptions holds options for thermal and label printer output
+type PrinterOptions struct {
+	Content       string
+	RecoveryLevel qrcode.RecoveryLevel
+	PaperWidth    float64 // Paper or label width in mm (default 80)
+	DPI           int     // Print head density in dots per inch (default 203)
+	Raster        bool    // Send a bitmap instead of the printer's native QR command
+}
+
+// printerLayout is the QR geometry in printer dots
+type printerLayout struct {
+	matrix    [][]bool
+	module    int // Dots per module
+	quietZone int // Quiet zone in modules
+	width     int // Printable width in dots
+}
+
+// size returns the side of the code including its quiet zone, in dots
+func (l printerLayout) size() int {
+	return (len(l.matrix) + 2*l.quietZone) * l.module
+}
+
+// layoutPrinter picks the module size in dots: close to 1 mm on paper,
+// never larger than maxModule and small enough for the code to fit the
+// printable width
+func layoutPrinter(opts PrinterOptions, margin float64, maxModule int) (printerLayout, error) {
+	if opts.DPI == 0 {
+		opts.DPI = DefaultPrinterDPI
+	}
+	if opts.PaperWidth == 0 {
+		opts.PaperWidth = DefaultPaperWidth
+	}
+	if opts.DPI < MinPrinterDPI || opts.DPI > MaxPrinterDPI {
+		return printerLayout{}, ErrInvalidPrinterDPI
+	}
+	if opts.PaperWidth < 20 || opts.PaperWidth > MaxPaperWidth {
+		return printerLayout{}, ErrInvalidPaperWidth
+	}
+
+	matrix, err := Matrix(opts.Content, opts.RecoveryLevel)
+	if err != nil {
+		return printerLayout{}, err
+	}
+
+	width := int((opts.PaperWidth - 2*margin) / 25.4 * float64(opts.DPI))
+	layout := printerLayout{matrix: matrix, quietZone: DefaultQuietZone, width: width}
+
+	module := int(math.Round(printerModuleTarget / 25.4 * float64(opts.DPI)))
+	if fit := width / (len(matrix) + 2*DefaultQuietZone); module > fit {
+		module = fit
+	}
+	if module > maxModule {
+		module = maxModule
+	}
+	if module < 1 {
+		return printerLayout{}, ErrPrinterWidthTooSmall
+	}
+	layout.module = module
+
+	return layout, nil
+}
+
+// packRows renders the layout as 1-bit rows, most significant bit first,
+// with the code centred horizontally in width dots
+func (l printerLayout) packRows(width int) (rows [][]byte, rowBytes int) {
+	rowBytes = (width + 7) / 8
+	offset := (width - l.size()) / 2
+	if offset < 0 {
+		offset = 0
+	}
+
+	size := l.size()
+	rows = make([][]byte, size)
+	for y := 0; y < size; y++ {
+		row := make([]byte, rowBytes)
+		my := y/l.module - l.quietZone
+		if my >= 0 && my < len(l.matrix) {
+			for mx, dark := range l.matrix[my] {
+				if !dark {
+					continue
+				}
+				start := offset + (mx+l.quietZone)*l.module
+				for x := start; x < start+l.module && x < width; x++ {
+					row[x/8] |= 0x80 >> (x % 8)
+				}
+			}
+		}
+		rows[y] = row
+	}
+	return rows, rowBytes
+}
@@ -0,0 +1,182 @@
+package qrgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+)
+
+func TestLayoutPrinter(t *testing.T) {
+	opts := PrinterOptions{Content: testContent, RecoveryLevel: qrcode.Medium}
+	layout, err := layoutPrinter(opts, printerPaperMargin, escposMaxModule)
+	if err != nil {
+		t.Fatalf("layoutPrinter: %v", err)
+	}
+	// 70mm printable on 80mm paper is 559 dots at 203 dpi; 1mm modules are 8 dots
+	if want := 559; layout.width != want {
+		t.Errorf("width = %d dots, want %d", layout.width, want)
+	}
+	if layout.module != 8 {
+		t.Errorf("module = %d dots, want 8", layout.module)
+	}
+	if layout.size() != (len(layout.matrix)+2*DefaultQuietZone)*8 {
+		t.Errorf("size = %d, want the matrix and quiet zone at 8 dots", layout.size())
+	}
+
+	// Narrow labels shrink the module so the code fits
+	opts.PaperWidth = 25
+	layout, err = layoutPrinter(opts, 0, zplMaxMagnification)
+	if err != nil {
+		t.Fatalf("layoutPrinter(25mm): %v", err)
+	}
+	if layout.size() > layout.width || layout.module >= 8 {
+		t.Errorf("25mm label: %d-dot code with %d-dot modules in %d dots", layout.size(), layout.module, layout.width)
+	}
+
+	// The module never exceeds the command's limit
+	opts.PaperWidth, opts.DPI = 300, 600
+	if layout, err = layoutPrinter(opts, 0, zplMaxMagnification); err != nil || layout.module != zplMaxMagnification {
+		t.Errorf("600 dpi module = %d, %v; want %d", layout.module, err, zplMaxMagnification)
+	}
+
+	tests := []struct {
+		opts PrinterOptions
+		want error
+	}{
+		{PrinterOptions{Content: testContent, DPI: 99}, ErrInvalidPrinterDPI},
+		{PrinterOptions{Content: testContent, DPI: 601}, ErrInvalidPrinterDPI},
+		{PrinterOptions{Content: testContent, PaperWidth: 19}, ErrInvalidPaperWidth},
+		{PrinterOptions{Content: testContent, PaperWidth: 301}, ErrInvalidPaperWidth},
+		{PrinterOptions{Content: testContent, PaperWidth: 20, DPI: 100}, ErrPrinterWidthTooSmall},
+	}
+	for _, tt := range tests {
+		if _, err := layoutPrinter(tt.opts, printerPaperMargin, escposMaxModule); !errors.Is(err, tt.want) {
+			t.Errorf("%+v: error = %v, want %v", tt.opts, err, tt.want)
+		}
+	}
+}
+
+func TestPackRows(t *testing.T) {
+	layout, err := layoutPrinter(PrinterOptions{Content: testContent, PaperWidth: 58}, printerPaperMargin, escposMaxModule)
+	if err != nil {
+		t.Fatalf("layoutPrinter: %v", err)
+	}
+	width := layout.size() + 13 // Not a multiple of 8, and off-centre by an odd dot
+	rows, rowBytes := layout.packRows(width)
+	if rowBytes != (width+7)/8 || len(rows) != layout.size() {
+		t.Fatalf("packRows = %d rows of %d bytes, want %d of %d", len(rows), rowBytes, layout.size(), (width+7)/8)
+	}
+
+	offset := (width - layout.size()) / 2
+	bit := func(x, y int) bool { return rows[y][x/8]&(0x80>>(x%8)) != 0 }
+	n := len(layout.matrix)
+	for y := 0; y < layout.size(); y++ {
+		for x := 0; x < rowBytes*8; x++ {
+			want := false
+			mx, my := (x-offset)/layout.module-layout.quietZone, y/layout.module-layout.quietZone
+			if x >= offset && x < width && mx >= 0 && mx < n && my >= 0 && my < n {
+				want = layout.matrix[my][mx]
+			}
+			if bit(x, y) != want {
+				t.Fatalf("dot (%d,%d) = %v, want %v", x, y, bit(x, y), want)
+			}
+		}
+	}
+}
+
+func TestZPLEscape(t *testing.T) {
+	tests := map[string]string{
+		"plain":      "plain",
+		"a^b~c_d":    "a_5Eb_7Ec_5Fd",
+		"Cà phê ^FS": "Cà phê _5EFS",
+		"__":         "_5F_5F",
+	}
+	for in, want := range tests {
+		if got := zplEscape(in); got != want {
+			t.Errorf("zplEscape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGenerateZPL(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	content := testContent + "~^"
+
+	data, err := g.GenerateZPL(PrinterOptions{Content: content, RecoveryLevel: qrcode.High, PaperWidth: 50})
+	if err != nil {
+		t.Fatalf("GenerateZPL: %v", err)
+	}
+	zpl := string(data)
+	if !strings.HasPrefix(zpl, "^XA\n^CI28\n") || !strings.HasSuffix(zpl, "^XZ\n") {
+		t.Errorf("label is not ^XA ^CI28 ... ^XZ:\n%s", zpl)
+	}
+	if want := "^FH^FDQA," + zplEscape(content) + "^FS"; !strings.Contains(zpl, want) {
+		t.Errorf("label lacks %q:\n%s", want, zpl)
+	}
+
+	data, err = g.GenerateZPL(PrinterOptions{Content: testContent, PaperWidth: 50, Raster: true})
+	if err != nil {
+		t.Fatalf("GenerateZPL(raster): %v", err)
+	}
+	if strings.Contains(string(data), "^BQ") || !strings.Contains(string(data), "^GFA,") {
+		t.Errorf("raster label does not use ^GF:\n%.80s", data)
+	}
+}
+
+func TestGenerateESCPOSFraming(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	prefix := []byte{0x1b, 0x40, 0x1b, 0x61, 0x01}
+	suffix := []byte{0x0a, 0x1b, 0x61, 0x00}
+
+	data, err := g.GenerateESCPOS(PrinterOptions{Content: testContent, RecoveryLevel: qrcode.Highest})
+	if err != nil {
+		t.Fatalf("GenerateESCPOS: %v", err)
+	}
+	if !bytes.HasPrefix(data, prefix) || !bytes.HasSuffix(data, suffix) {
+		t.Fatalf("native output is not framed by ESC @, ESC a 1 and LF, ESC a 0: % x", data)
+	}
+
+	// Walk the GS ( k blocks by their length fields
+	body := data[len(prefix) : len(data)-len(suffix)]
+	var functions []byte
+	for len(body) > 0 {
+		if len(body) < 7 || !bytes.Equal(body[:3], []byte{0x1d, 0x28, 0x6b}) || body[5] != 0x31 {
+			t.Fatalf("malformed GS ( k block: % x", body[:min(len(body), 8)])
+		}
+		n := int(binary.LittleEndian.Uint16(body[3:5]))
+		fn := body[6]
+		functions = append(functions, fn)
+		switch fn {
+		case 0x45:
+			if body[7] != 51 {
+				t.Errorf("error correction = %d, want 51 (H)", body[7])
+			}
+		case 0x50:
+			if got := string(body[8 : 5+n]); got != testContent {
+				t.Errorf("stored data = %q, want the payload", got)
+			}
+		}
+		body = body[5+n:]
+	}
+	if want := []byte{0x41, 0x43, 0x45, 0x50, 0x51}; !bytes.Equal(functions, want) {
+		t.Errorf("GS ( k functions = % x, want % x", functions, want)
+	}
+
+	data, err = g.GenerateESCPOS(PrinterOptions{Content: testContent, Raster: true})
+	if err != nil {
+		t.Fatalf("GenerateESCPOS(raster): %v", err)
+	}
+	body = data[len(prefix) : len(data)-len(suffix)]
+	if !bytes.Equal(body[:4], []byte{0x1d, 0x76, 0x30, 0x00}) {
+		t.Fatalf("raster output does not start with GS v 0: % x", body[:4])
+	}
+	rowBytes := int(binary.LittleEndian.Uint16(body[4:6]))
+	rows := int(binary.LittleEndian.Uint16(body[6:8]))
+	if len(body) != 8+rowBytes*rows {
+		t.Errorf("GS v 0 declares %dx%d bytes but carries %d", rowBytes, rows, len(body)-8)
+	}
+}
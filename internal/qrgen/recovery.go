@@ -0,0 +1,57 @@
+package qrgen
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// ErrInvalidRecoveryLevel is returned for unknown error correction levels
+var ErrInvalidRecoveryLevel = errors.New("ecc must be L, M, Q or H")
+
+// recoveryLevels maps the ISO/IEC 18004 level letters to go-qrcode levels
+var recoveryLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,     // ~7% recovery
+	"M": qrcode.Medium,  // ~15%
+	"Q": qrcode.High,    // ~25%
+	"H": qrcode.Highest, // ~30%
+}
+
+// ParseRecoveryLevel parses an error correction level letter (L, M, Q or H,
+// case-insensitive)
+func ParseRecoveryLevel(s string) (qrcode.RecoveryLevel, error) {
+	if level, ok := recoveryLevels[strings.ToUpper(strings.TrimSpace(s))]; ok {
+		return level, nil
+	}
+	return 0, ErrInvalidRecoveryLevel
+}
+
+// RecoveryLevelName returns the level letter (L, M, Q or H)
+func RecoveryLevelName(level qrcode.RecoveryLevel) string {
+	for name, l := range recoveryLevels {
+		if l == level {
+			return name
+		}
+	}
+	return ""
+}
+
+// Recovery returns the level the options are rendered at: RecoveryLevel,
+// raised to Highest when a logo covers part of the symbol and to at least
+// Q for dot or rounded modules and gradients
+func (opts GenerateOptions) Recovery() qrcode.RecoveryLevel {
+	if opts.Logo != nil {
+		return qrcode.Highest
+	}
+	return opts.Style.styledRecovery(opts.RecoveryLevel)
+}
+
+// Version returns the QR version (1-40) content is encoded with at level
+func Version(content string, level qrcode.RecoveryLevel) (int, error) {
+	qr, err := qrcode.New(content, level)
+	if err != nil {
+		return 0, err
+	}
+	return qr.VersionNumber, nil
+}
@@ -0,0 +1,86 @@
+package qrgen
+
+import (
+	"image"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+)
+
+func TestParseRecoveryLevel(t *testing.T) {
+	tests := []struct {
+		in    string
+		level qrcode.RecoveryLevel
+		name  string
+	}{
+		{"L", qrcode.Low, "L"},
+		{"m", qrcode.Medium, "M"},
+		{" Q ", qrcode.High, "Q"},
+		{"h", qrcode.Highest, "H"},
+	}
+	for _, tt := range tests {
+		level, err := ParseRecoveryLevel(tt.in)
+		if err != nil || level != tt.level {
+			t.Errorf("ParseRecoveryLevel(%q) = %v, %v, want %v", tt.in, level, err, tt.level)
+		}
+		if name := RecoveryLevelName(level); name != tt.name {
+			t.Errorf("RecoveryLevelName(%v) = %q, want %q", level, name, tt.name)
+		}
+	}
+	for _, in := range []string{"", "X", "LM", "low", "0"} {
+		if _, err := ParseRecoveryLevel(in); err != ErrInvalidRecoveryLevel {
+			t.Errorf("ParseRecoveryLevel(%q) error = %v, want ErrInvalidRecoveryLevel", in, err)
+		}
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	levels := []qrcode.RecoveryLevel{qrcode.Low, qrcode.Medium, qrcode.High, qrcode.Highest}
+
+	tests := []struct {
+		name  string
+		set   func(*GenerateOptions)
+		floor qrcode.RecoveryLevel // Lowest level the options render at
+	}{
+		{"plain", func(*GenerateOptions) {}, qrcode.Low},
+		{"logo", func(o *GenerateOptions) { o.Logo = logo }, qrcode.Highest},
+		{"logo and style", func(o *GenerateOptions) { o.Logo = logo; o.Style = &Style{Shape: ShapeDot} }, qrcode.Highest},
+		{"dot", func(o *GenerateOptions) { o.Style = &Style{Shape: ShapeDot} }, qrcode.High},
+		{"rounded", func(o *GenerateOptions) { o.Style = &Style{Shape: ShapeRounded} }, qrcode.High},
+		{"gradient", func(o *GenerateOptions) { o.Style = &Style{Gradient: GradientRadial} }, qrcode.High},
+		{"square style", func(o *GenerateOptions) { o.Style = &Style{Shape: ShapeSquare} }, qrcode.Low},
+		{"eyes only", func(o *GenerateOptions) { o.Style = &Style{Eye: EyeCircle} }, qrcode.Low},
+	}
+	for _, tt := range tests {
+		for _, level := range levels {
+			opts := GenerateOptions{Content: testContent, RecoveryLevel: level}
+			tt.set(&opts)
+			if got, want := opts.Recovery(), max(level, tt.floor); got != want {
+				t.Errorf("%s at %s: Recovery() = %s, want %s", tt.name, RecoveryLevelName(level), RecoveryLevelName(got), RecoveryLevelName(want))
+			}
+		}
+	}
+}
+
+func TestDefaultRecoveryFromConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.DefaultRecovery != qrcode.Medium {
+		t.Errorf("default recovery = %s, want M", RecoveryLevelName(cfg.DefaultRecovery))
+	}
+	cfg.DefaultRecovery = qrcode.Low
+	opts := NewGenerator(cfg).DefaultOptions(testContent, SizeSmall)
+	if opts.Recovery() != qrcode.Low {
+		t.Errorf("options render at %s, want the configured L", RecoveryLevelName(opts.Recovery()))
+	}
+
+	// Higher levels need larger symbols for the same content
+	low, err := Version(testContent, qrcode.Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high, err := Version(testContent, qrcode.Highest)
+	if err != nil || high <= low {
+		t.Errorf("Version at L = %d, at H = %d (%v)", low, high, err)
+	}
+}
@@ -0,0 +1,186 @@
+package qrgen
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// PrintDPI is the resolution print sizes are converted at when they are
+// checked against pixel limits
+const PrintDPI = 300
+
+// ptToMM converts font sizes in points to millimetres
+const ptToMM = 25.4 / 72
+
+// sheetTextPadding is the space in mm kept between text and the cell edges
+const sheetTextPadding = 1.0
+
+// Sheet grid limits
+const (
+	MaxSheetColumns = 10
+	MaxSheetRows    = 20
+)
+
+// Sheet errors
+var (
+	ErrEmptySheet     = errors.New("sheet has no QR codes")
+	ErrInvalidColumns = errors.New("columns must be 1-10")
+	ErrInvalidRows    = errors.New("rows must be 1-20")
+	ErrInvalidMargin  = errors.New("margin must be positive and leave room for the grid")
+	ErrCellTooNarrow  = errors.New("grid cells are too narrow for the page; use fewer columns or a smaller margin")
+	ErrCellTooShort   = errors.New("grid cells are too short for the page; use fewer rows or a smaller margin")
+)
+
+// SheetItem is one QR code on a sheet
+type SheetItem struct {
+	Content string
+	Label   string // Printed under the QR code (e.g., "Ban 01")
+	Caption string // Smaller second line (e.g., the amount)
+}
+
+// SheetOptions holds the layout of a multi-QR sheet
+type SheetOptions struct {
+	Page          PageSize
+	Columns       int
+	Rows          int
+	Margin        float64 // Page margin in mm (0 for 10mm)
+	CutMarks      bool    // Draw crop marks on the grid lines
+	MaxQRSide     float64 // Largest QR side in mm (0 for no limit)
+	RecoveryLevel qrcode.RecoveryLevel
+	Items         []SheetItem
+}
+
+// MillimetresForPixels returns the printed size of px pixels at dpi
+func MillimetresForPixels(px, dpi int) float64 {
+	return float64(px) / float64(dpi) * 25.4
+}
+
+// GenerateSheet lays the items out in a Columns x Rows grid, adding pages
+// as needed. Each cell holds a vector QR code centred above its label and
+// caption; cut marks are drawn on the grid lines outside the codes.
+func (g *Generator) GenerateSheet(opts SheetOptions) ([]byte, error) {
+	if len(opts.Items) == 0 {
+		return nil, ErrEmptySheet
+	}
+	if opts.Columns < 1 || opts.Columns > MaxSheetColumns {
+		return nil, ErrInvalidColumns
+	}
+	if opts.Rows < 1 || opts.Rows > MaxSheetRows {
+		return nil, ErrInvalidRows
+	}
+
+	page := opts.Page
+	if page.Width == 0 || page.Height == 0 {
+		page = PageA4
+	}
+	margin := opts.Margin
+	if margin == 0 {
+		margin = 10
+	}
+	if margin < 0 || 2*margin >= min(page.Width, page.Height) {
+		return nil, ErrInvalidMargin
+	}
+
+	cellW := (page.Width - 2*margin) / float64(opts.Columns)
+	cellH := (page.Height - 2*margin) / float64(opts.Rows)
+	labelSize := cellW * 0.35 // Label font size in pt, scaled to the cell
+	if labelSize > 14 {
+		labelSize = 14
+	}
+	labelH := labelSize * ptToMM * 1.3
+	captionH := labelSize * ptToMM * 1.1
+	textH := labelH + captionH
+
+	// The square left for each code, quiet zone included
+	const minBox = 15
+	if cellW < minBox {
+		return nil, ErrCellTooNarrow
+	}
+	if cellH-textH < minBox {
+		return nil, ErrCellTooShort
+	}
+	box := min(cellW, cellH-textH)
+
+	pdf := newPDF(page)
+	perPage := opts.Columns * opts.Rows
+	for i, item := range opts.Items {
+		if i%perPage == 0 {
+			pdf.AddPage()
+			if opts.CutMarks {
+				drawCutMarks(pdf, page, margin, cellW, cellH, opts.Columns, opts.Rows)
+			}
+		}
+
+		matrix, err := Matrix(item.Content, opts.RecoveryLevel)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keep a full quiet zone inside the cell so cutting never eats into it
+		modules := float64(len(matrix))
+		side := box * modules / (modules + 2*DefaultQuietZone)
+		if opts.MaxQRSide > 0 && side > opts.MaxQRSide {
+			side = opts.MaxQRSide
+		}
+
+		slot := i % perPage
+		cellX := margin + float64(slot%opts.Columns)*cellW
+		cellY := margin + float64(slot/opts.Columns)*cellH
+		blockH := side + textH
+		y := cellY + (cellH-blockH)/2
+
+		drawPDFMatrix(pdf, matrix, cellX+(cellW-side)/2, y, side, g.config.ForegroundColor)
+		y += side
+
+		// Text is cut to the cell so long labels never run into neighbours
+		textW := cellW - 2*sheetTextPadding
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetFont(pdfFont, "", labelSize)
+		pdf.SetXY(cellX, y)
+		pdf.CellFormat(cellW, labelH, fitPDFText(pdf, item.Label, textW), "", 0, "C", false, 0, "")
+		if item.Caption != "" {
+			pdf.SetTextColor(90, 90, 90)
+			pdf.SetFont(pdfFont, "", labelSize*0.75)
+			pdf.SetXY(cellX, y+labelH)
+			pdf.CellFormat(cellW, captionH, fitPDFText(pdf, item.Caption, textW), "", 0, "C", false, 0, "")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawCutMarks draws crop marks where the grid lines meet the page margin
+// and small crosses where they meet each other
+func drawCutMarks(pdf *gofpdf.Fpdf, page PageSize, margin, cellW, cellH float64, columns, rows int) {
+	const mark = 3.0 // Crop mark length in mm
+	gap := margin * 0.2
+
+	pdf.SetDrawColor(150, 150, 150)
+	pdf.SetLineWidth(0.15)
+
+	for col := 0; col <= columns; col++ {
+		x := margin + float64(col)*cellW
+		pdf.Line(x, gap, x, margin-gap)
+		pdf.Line(x, page.Height-margin+gap, x, page.Height-gap)
+	}
+	for row := 0; row <= rows; row++ {
+		y := margin + float64(row)*cellH
+		pdf.Line(gap, y, margin-gap, y)
+		pdf.Line(page.Width-margin+gap, y, page.Width-gap, y)
+	}
+	for col := 1; col < columns; col++ {
+		for row := 1; row < rows; row++ {
+			x := margin + float64(col)*cellW
+			y := margin + float64(row)*cellH
+			pdf.Line(x-mark/2, y, x+mark/2, y)
+			pdf.Line(x, y-mark/2, x, y+mark/2)
+		}
+	}
+}
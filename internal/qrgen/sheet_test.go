@@ -0,0 +1,79 @@
+package qrgen
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSheetErrors(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	items := []SheetItem{{Content: testContent, Label: "Ban 01"}}
+
+	tests := []struct {
+		name string
+		opts SheetOptions
+		want error
+	}{
+		{"no items", SheetOptions{Columns: 3, Rows: 4}, ErrEmptySheet},
+		{"no columns", SheetOptions{Columns: 0, Rows: 4, Items: items}, ErrInvalidColumns},
+		{"too many columns", SheetOptions{Columns: 11, Rows: 4, Items: items}, ErrInvalidColumns},
+		{"no rows", SheetOptions{Columns: 3, Rows: 0, Items: items}, ErrInvalidRows},
+		{"too many rows", SheetOptions{Columns: 3, Rows: 21, Items: items}, ErrInvalidRows},
+		{"negative margin", SheetOptions{Columns: 3, Rows: 4, Margin: -1, Items: items}, ErrInvalidMargin},
+		{"margin fills page", SheetOptions{Columns: 3, Rows: 4, Margin: 105, Items: items}, ErrInvalidMargin},
+		{"narrow cells", SheetOptions{Columns: 10, Rows: 1, Page: PageA6, Items: items}, ErrCellTooNarrow},
+		{"short cells", SheetOptions{Columns: 1, Rows: 20, Page: PageA6, Items: items}, ErrCellTooShort},
+	}
+	for _, tt := range tests {
+		if _, err := g.GenerateSheet(tt.opts); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestGenerateSheetPages(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	items := make([]SheetItem, 7)
+	for i := range items {
+		items[i] = SheetItem{Content: testContent, Label: "Ban", Caption: "99.000 ₫"}
+	}
+
+	data, err := g.GenerateSheet(SheetOptions{Columns: 2, Rows: 2, CutMarks: true, Items: items})
+	if err != nil {
+		t.Fatalf("GenerateSheet: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatal("output is not a PDF")
+	}
+	if pages := bytes.Count(data, []byte("/Type /Page\n")); pages != 2 {
+		t.Errorf("7 codes on a 2x2 grid gave %d pages, want 2", pages)
+	}
+}
+
+func TestFitPDFText(t *testing.T) {
+	pdf := newPDF(PageA4)
+	pdf.SetFont(pdfFont, "", 10)
+
+	if got := fitPDFText(pdf, "Ban 01", 50); got != "Ban 01" {
+		t.Errorf("short text = %q, want it unchanged", got)
+	}
+
+	long := strings.Repeat("Bàn sân vườn ", 10)
+	got := fitPDFText(pdf, long, 30)
+	if !strings.HasSuffix(got, "…") || !strings.HasPrefix(long, strings.TrimSuffix(got, "…")) {
+		t.Errorf("long text = %q, want a prefix with an ellipsis", got)
+	}
+	if w := pdf.GetStringWidth(got); w > 30 {
+		t.Errorf("truncated text is %.1fmm wide, want at most 30mm", w)
+	}
+	// One more rune would not have fitted
+	if next := []rune(long)[:len([]rune(got))]; pdf.GetStringWidth(string(next)+"…") <= 30 {
+		t.Errorf("text was cut shorter than needed: %q", got)
+	}
+
+	if got := fitPDFText(pdf, long, 0.1); got != "" {
+		t.Errorf("text in a 0.1mm cell = %q, want empty", got)
+	}
+}
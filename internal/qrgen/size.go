@@ -0,0 +1,116 @@
+package qrgen
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinSize is the smallest pixel size accepted by ParseSizeDPI
+const MinSize QRSize = 64
+
+// Image resolution limits and the default for physical sizes
+const (
+	MinDPI     = 72
+	MaxDPI     = 1200
+	DefaultDPI = PrintDPI
+)
+
+// Size errors
+var (
+	ErrInvalidSize  = errors.New("size must be small, medium, large, xlarge, pixels (e.g., 640) or a physical size (e.g., 30mm, 1.5in)")
+	ErrSizeTooLarge = errors.New("size exceeds the maximum image size")
+	ErrInvalidDPI   = fmt.Errorf("dpi must be between %d and %d", MinDPI, MaxDPI)
+)
+
+// physicalUnits maps unit suffixes to inches per unit
+var physicalUnits = []struct {
+	suffix string
+	inches float64
+}{
+	{"inch", 1},
+	{"in", 1},
+	{"mm", 1 / 25.4},
+	{"cm", 1 / 2.54},
+	{"px", 0}, // Pixels, not a physical unit
+}
+
+// ParseSizeDPI parses a size parameter strictly: a preset name, a pixel
+// size ("640" or "640px") or a physical size in mm, cm or inches converted
+// at dpi (0 selects DefaultDPI). Sizes below MinSize or above maxSize
+// pixels (0 means no limit) are rejected; presets are clamped to maxSize
+// instead. physical reports whether s was a physical size.
+func ParseSizeDPI(s string, dpi, maxSize int) (size QRSize, physical bool, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "small", "sm", "s", "medium", "md", "m", "large", "lg", "l", "xlarge", "xl", "x":
+		size = ParseSize(s)
+		if maxSize > 0 && int(size) > maxSize {
+			size = QRSize(maxSize)
+		}
+		return size, false, nil
+	}
+
+	if dpi == 0 {
+		dpi = DefaultDPI
+	}
+	if dpi < MinDPI || dpi > MaxDPI {
+		return 0, false, ErrInvalidDPI
+	}
+
+	value, inches := s, 0.0
+	for _, unit := range physicalUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			value, inches = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.inches
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 || math.IsInf(n, 0) {
+		return 0, false, ErrInvalidSize
+	}
+
+	px := n
+	if inches > 0 {
+		px = math.Round(n * inches * float64(dpi))
+		physical = true
+	} else if n != math.Trunc(n) {
+		return 0, false, ErrInvalidSize
+	}
+
+	if px < float64(MinSize) {
+		return 0, false, fmt.Errorf("%w: %.0f px is below the minimum of %d px", ErrInvalidSize, px, MinSize)
+	}
+	if maxSize > 0 && px > float64(maxSize) {
+		return 0, false, fmt.Errorf("%w: %.0f px is above the limit of %d px", ErrSizeTooLarge, px, maxSize)
+	}
+	return QRSize(px), physical, nil
+}
+
+// withDPI inserts a pHYs chunk recording dpi right after the IHDR chunk of
+// an encoded PNG, so print software sizes the image correctly
+func withDPI(data []byte, dpi int) []byte {
+	// 8-byte signature, then IHDR: length, type, 13 bytes of data, CRC
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+	if dpi <= 0 || len(data) < ihdrEnd {
+		return data
+	}
+
+	ppm := uint32(math.Round(float64(dpi) / 0.0254)) // Pixels per metre
+	chunk := make([]byte, 4+4+9+4)
+	binary.BigEndian.PutUint32(chunk[0:], 9)
+	copy(chunk[4:], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:], ppm)
+	binary.BigEndian.PutUint32(chunk[12:], ppm)
+	chunk[16] = 1 // Unit: metre
+	binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+
+	out := make([]byte, 0, len(data)+len(chunk))
+	out = append(out, data[:ihdrEnd]...)
+	out = append(out, chunk...)
+	return append(out, data[ihdrEnd:]...)
+}
@@ -0,0 +1,132 @@
+package qrgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image/png"
+	"slices"
+	"testing"
+)
+
+func TestParseSizeDPI(t *testing.T) {
+	tests := []struct {
+		in       string
+		dpi      int
+		max      int
+		want     QRSize
+		physical bool
+		err      error
+	}{
+		{in: "small", want: SizeSmall},
+		{in: " XL ", want: SizeXLarge},
+		{in: "large", max: 300, want: 300}, // Presets are clamped
+		{in: "640", want: 640},
+		{in: "640px", want: 640},
+		{in: "64", want: MinSize},
+		{in: "100000", want: 100000},
+		{in: "30mm", want: 354, physical: true},           // 30 / 25.4 * 300
+		{in: "30mm", dpi: 600, want: 709, physical: true}, // 30 / 25.4 * 600
+		{in: "3cm", want: 354, physical: true},
+		{in: "1.5in", want: 450, physical: true},
+		{in: "1 inch", dpi: 96, want: 96, physical: true},
+		{in: "63", err: ErrInvalidSize},
+		{in: "5mm", err: ErrInvalidSize}, // 59 px
+		{in: "640.5", err: ErrInvalidSize},
+		{in: "0", err: ErrInvalidSize},
+		{in: "-300", err: ErrInvalidSize},
+		{in: "huge", err: ErrInvalidSize},
+		{in: "", err: ErrInvalidSize},
+		{in: "1e400", err: ErrInvalidSize},
+		{in: "2001", max: 2000, err: ErrSizeTooLarge},
+		{in: "200mm", max: 2000, err: ErrSizeTooLarge},
+		{in: "30mm", dpi: 50, err: ErrInvalidDPI},
+		{in: "30mm", dpi: 1201, err: ErrInvalidDPI},
+	}
+	for _, tt := range tests {
+		size, physical, err := ParseSizeDPI(tt.in, tt.dpi, tt.max)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ParseSizeDPI(%q, %d, %d) error = %v, want %v", tt.in, tt.dpi, tt.max, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || size != tt.want || physical != tt.physical {
+			t.Errorf("ParseSizeDPI(%q, %d, %d) = %d, %v, %v, want %d, %v", tt.in, tt.dpi, tt.max, size, physical, err, tt.want, tt.physical)
+		}
+	}
+}
+
+// pngChunks returns the chunk types of a PNG in order, checking each CRC
+func pngChunks(t *testing.T, data []byte) ([]string, map[string][]byte) {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Fatal("not a PNG")
+	}
+	var types []string
+	bodies := make(map[string][]byte)
+	for rest := data[8:]; len(rest) > 0; {
+		if len(rest) < 12 {
+			t.Fatalf("truncated chunk after %v", types)
+		}
+		n := int(binary.BigEndian.Uint32(rest))
+		typ, body := string(rest[4:8]), rest[8:8+n]
+		if crc := binary.BigEndian.Uint32(rest[8+n:]); crc != crc32.ChecksumIEEE(rest[4:8+n]) {
+			t.Errorf("%s chunk has a bad CRC", typ)
+		}
+		types = append(types, typ)
+		bodies[typ] = body
+		rest = rest[12+n:]
+	}
+	return types, bodies
+}
+
+func TestWithDPI(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	opts := g.DefaultOptions(testContent, SizeSmall)
+	plain, err := g.GeneratePNGWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types, _ := pngChunks(t, plain); slices.Contains(types, "pHYs") {
+		t.Error("PNG without a DPI has a pHYs chunk")
+	}
+
+	opts.DPI = 300
+	data, err := g.GeneratePNGWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, bodies := pngChunks(t, data)
+	if len(types) < 3 || types[0] != "IHDR" || types[1] != "pHYs" || types[len(types)-1] != "IEND" {
+		t.Fatalf("chunks = %v, want IHDR, pHYs, ..., IEND", types)
+	}
+	phys := bodies["pHYs"]
+	if len(phys) != 9 || binary.BigEndian.Uint32(phys) != 11811 || binary.BigEndian.Uint32(phys[4:]) != 11811 || phys[8] != 1 {
+		t.Errorf("pHYs = % x, want 11811 pixels per metre on both axes", phys)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("PNG with pHYs does not decode: %v", err)
+	}
+
+	// Nothing to do without a DPI or a complete IHDR
+	if got := withDPI(plain, 0); !bytes.Equal(got, plain) {
+		t.Error("withDPI(0) changed the PNG")
+	}
+	if got := withDPI(plain[:20], 300); !bytes.Equal(got, plain[:20]) {
+		t.Error("withDPI changed a truncated PNG")
+	}
+}
+
+func TestContentHashFullSize(t *testing.T) {
+	if ContentHash("x", 300) == ContentHash("x", 300+65536) {
+		t.Error("sizes 65536 apart share a hash")
+	}
+	opts := GenerateOptions{Content: testContent, Size: 300}
+	big := opts
+	big.Size += 1 << 16
+	if opts.CacheKey() == big.CacheKey() {
+		t.Error("sizes 65536 apart share a cache key")
+	}
+}
@@ -0,0 +1,486 @@
+package qrgen
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/vector"
+)
+
+// ModuleShape is how dark data modules are drawn
+type ModuleShape string
+
+const (
+	ShapeSquare  ModuleShape = "square"
+	ShapeRounded ModuleShape = "rounded" // Corners without dark neighbours are rounded
+	ShapeDot     ModuleShape = "dot"
+)
+
+// EyeStyle is how the three finder patterns are drawn. Every style keeps
+// the 1:1:3:1:1 dark/light profile through the centre that scanners look for.
+type EyeStyle string
+
+const (
+	EyeSquare  EyeStyle = "square"
+	EyeRounded EyeStyle = "rounded"
+	EyeCircle  EyeStyle = "circle"
+)
+
+// GradientKind selects the module fill
+type GradientKind string
+
+const (
+	GradientNone   GradientKind = "none"
+	GradientLinear GradientKind = "linear"
+	GradientRadial GradientKind = "radial" // From the centre of the symbol to its corners
+)
+
+// minStyledModule is the smallest module in pixels shapes are drawn at;
+// below it rounded finder patterns blur into blobs, so the image grows
+const minStyledModule = 3
+
+// Style errors
+var (
+	ErrInvalidShape         = errors.New("shape must be square, rounded or dot")
+	ErrInvalidEyeStyle      = errors.New("eye must be square, rounded or circle")
+	ErrInvalidGradient      = errors.New("gradient must be none, linear or radial")
+	ErrInvalidGradientAngle = errors.New("gradient_angle must be a number of degrees")
+	ErrGradientColorMissing = errors.New("gradient needs an end color (gradient_to)")
+	ErrUnscannableEye       = errors.New("eye color must be darker than the background with at least 3:1 contrast, or scanners cannot find the finder patterns")
+	ErrUnscannableGradient  = errors.New("every gradient color must be darker than the background with at least 3:1 contrast")
+)
+
+// Style describes a styled rendering of the symbol. The zero value draws
+// plain square modules.
+type Style struct {
+	Shape         ModuleShape
+	Eye           EyeStyle
+	EyeColor      color.Color // Finder pattern colour (nil = same fill as the modules)
+	Gradient      GradientKind
+	GradientTo    color.Color // End colour; gradients start at the foreground colour
+	GradientAngle float64     // Linear direction in degrees clockwise from left-to-right
+}
+
+// ParseModuleShape parses a module shape name ("" selects square)
+func ParseModuleShape(s string) (ModuleShape, error) {
+	switch shape := ModuleShape(strings.ToLower(strings.TrimSpace(s))); shape {
+	case "":
+		return ShapeSquare, nil
+	case ShapeSquare, ShapeRounded, ShapeDot:
+		return shape, nil
+	}
+	return "", ErrInvalidShape
+}
+
+// ParseEyeStyle parses a finder pattern style name ("" selects square)
+func ParseEyeStyle(s string) (EyeStyle, error) {
+	switch eye := EyeStyle(strings.ToLower(strings.TrimSpace(s))); eye {
+	case "":
+		return EyeSquare, nil
+	case EyeSquare, EyeRounded, EyeCircle:
+		return eye, nil
+	}
+	return "", ErrInvalidEyeStyle
+}
+
+// ParseGradient parses a gradient kind ("" selects none)
+func ParseGradient(s string) (GradientKind, error) {
+	switch kind := GradientKind(strings.ToLower(strings.TrimSpace(s))); kind {
+	case "":
+		return GradientNone, nil
+	case GradientNone, GradientLinear, GradientRadial:
+		return kind, nil
+	}
+	return "", ErrInvalidGradient
+}
+
+// plain reports whether s renders exactly like the plain renderer
+func (s *Style) plain() bool {
+	return s == nil || (s.shape() == ShapeSquare && s.eye() == EyeSquare &&
+		s.EyeColor == nil && s.gradient() == GradientNone)
+}
+
+// reducesContrast reports whether s shrinks the dark area of data modules
+// or lightens part of them, which costs scanners some margin
+func (s *Style) reducesContrast() bool {
+	return s != nil && (s.shape() != ShapeSquare || s.gradient() != GradientNone)
+}
+
+func (s *Style) shape() ModuleShape {
+	if s.Shape == "" {
+		return ShapeSquare
+	}
+	return s.Shape
+}
+
+func (s *Style) eye() EyeStyle {
+	if s.Eye == "" {
+		return EyeSquare
+	}
+	return s.Eye
+}
+
+func (s *Style) gradient() GradientKind {
+	if s.Gradient == "" {
+		return GradientNone
+	}
+	return s.Gradient
+}
+
+// Check is the scannability guard: it refuses styles whose finder patterns
+// or gradient would not stand out from bg. The foreground colour, where
+// gradients start, is checked separately with CheckContrast.
+func (s *Style) Check(bg color.Color) error {
+	if s == nil {
+		return nil
+	}
+	if s.EyeColor != nil && CheckContrast(s.EyeColor, bg) != nil {
+		return fmt.Errorf("%w (contrast %.2f:1)", ErrUnscannableEye, ContrastRatio(s.EyeColor, bg))
+	}
+	if s.gradient() == GradientNone {
+		return nil
+	}
+	if s.GradientTo == nil {
+		return ErrGradientColorMissing
+	}
+	if math.IsNaN(s.GradientAngle) || math.IsInf(s.GradientAngle, 0) {
+		return ErrInvalidGradientAngle
+	}
+	if CheckContrast(s.GradientTo, bg) != nil {
+		return fmt.Errorf("%w (contrast %.2f:1)", ErrUnscannableGradient, ContrastRatio(s.GradientTo, bg))
+	}
+	return nil
+}
+
+// key returns the cache key component of s
+func (s *Style) key() string {
+	if s.plain() {
+		return ""
+	}
+	return strings.Join([]string{
+		string(s.shape()), string(s.eye()), colorKey(s.EyeColor),
+		string(s.gradient()), colorKey(s.GradientTo), strconv.FormatFloat(s.GradientAngle, 'g', -1, 64),
+	}, ",")
+}
+
+// pather receives outlines in module units
+type pather interface {
+	MoveTo(x, y float64)
+	LineTo(x, y float64)
+	CubeTo(x1, y1, x2, y2, x, y float64)
+	Close()
+}
+
+// rasterPather scales outlines onto a vector rasterizer
+type rasterPather struct {
+	z      *vector.Rasterizer
+	scale  float64
+	offset float64
+}
+
+func (p rasterPather) pt(x, y float64) (float32, float32) {
+	return float32(p.offset + x*p.scale), float32(p.offset + y*p.scale)
+}
+
+func (p rasterPather) MoveTo(x, y float64) { p.z.MoveTo(p.pt(x, y)) }
+func (p rasterPather) LineTo(x, y float64) { p.z.LineTo(p.pt(x, y)) }
+func (p rasterPather) Close()              { p.z.ClosePath() }
+
+func (p rasterPather) CubeTo(x1, y1, x2, y2, x, y float64) {
+	ax, ay := p.pt(x1, y1)
+	bx, by := p.pt(x2, y2)
+	cx, cy := p.pt(x, y)
+	p.z.CubeTo(ax, ay, bx, by, cx, cy)
+}
+
+// svgPather writes outlines as SVG path data
+type svgPather struct {
+	sb     *strings.Builder
+	offset float64
+}
+
+func (p svgPather) MoveTo(x, y float64) {
+	fmt.Fprintf(p.sb, "M%s %s", svgNum(x+p.offset), svgNum(y+p.offset))
+}
+
+func (p svgPather) LineTo(x, y float64) {
+	fmt.Fprintf(p.sb, "L%s %s", svgNum(x+p.offset), svgNum(y+p.offset))
+}
+
+func (p svgPather) CubeTo(x1, y1, x2, y2, x, y float64) {
+	fmt.Fprintf(p.sb, "C%s %s %s %s %s %s",
+		svgNum(x1+p.offset), svgNum(y1+p.offset), svgNum(x2+p.offset), svgNum(y2+p.offset),
+		svgNum(x+p.offset), svgNum(y+p.offset))
+}
+
+func (p svgPather) Close() { p.sb.WriteByte('z') }
+
+// svgNum formats v with at most three decimals
+func svgNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// arcK places cubic control points for quarter-circle corners: 1 - 0.5523
+const arcK = 0.4477
+
+// roundRect traces a rectangle clockwise with corner radii r (top-left,
+// top-right, bottom-right, bottom-left). A negative w mirrors the outline,
+// reversing its winding so it cuts a hole in an enclosing shape.
+func roundRect(p pather, x, y, w, h float64, r [4]float64) {
+	sx := 1.0
+	if w < 0 {
+		sx = -1
+	}
+	// Edges fully taken by their corners are skipped
+	aw := sx * w
+	p.MoveTo(x+sx*r[0], y)
+	if aw > r[0]+r[1] {
+		p.LineTo(x+w-sx*r[1], y)
+	}
+	if r[1] > 0 {
+		p.CubeTo(x+w-sx*r[1]*arcK, y, x+w, y+r[1]*arcK, x+w, y+r[1])
+	}
+	if h > r[1]+r[2] {
+		p.LineTo(x+w, y+h-r[2])
+	}
+	if r[2] > 0 {
+		p.CubeTo(x+w, y+h-r[2]*arcK, x+w-sx*r[2]*arcK, y+h, x+w-sx*r[2], y+h)
+	}
+	if aw > r[2]+r[3] {
+		p.LineTo(x+sx*r[3], y+h)
+	}
+	if r[3] > 0 {
+		p.CubeTo(x+sx*r[3]*arcK, y+h, x, y+h-r[3]*arcK, x, y+h-r[3])
+	}
+	if r[0] > 0 {
+		if h > r[3]+r[0] {
+			p.LineTo(x, y+r[0])
+		}
+		p.CubeTo(x, y+r[0]*arcK, x+sx*r[0]*arcK, y, x+sx*r[0], y)
+	}
+	p.Close()
+}
+
+// square traces a w-wide square at x, y with all corners at radius r
+func square(p pather, x, y, w, r float64) {
+	roundRect(p, x, y, w, w, [4]float64{r, r, r, r})
+}
+
+// isFinder reports whether module x, y of an n-module symbol belongs to one
+// of the 7x7 finder patterns
+func isFinder(x, y, n int) bool {
+	return (x < 7 && y < 7) || (x >= n-7 && y < 7) || (x < 7 && y >= n-7)
+}
+
+// traceModules traces every dark module outside the finder patterns
+func (s *Style) traceModules(p pather, matrix [][]bool) {
+	n := len(matrix)
+	dark := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < n && y < n && matrix[y][x] && !isFinder(x, y, n)
+	}
+	for y := range matrix {
+		for x := range matrix[y] {
+			if !dark(x, y) {
+				continue
+			}
+			fx, fy := float64(x), float64(y)
+			switch s.shape() {
+			case ShapeDot:
+				square(p, fx+0.05, fy+0.05, 0.9, 0.45)
+			case ShapeRounded:
+				// Round a corner only where both modules beside it are light,
+				// so runs of modules stay joined
+				up, down, left, right := dark(x, y-1), dark(x, y+1), dark(x-1, y), dark(x+1, y)
+				var r [4]float64
+				if !up && !left {
+					r[0] = 0.5
+				}
+				if !up && !right {
+					r[1] = 0.5
+				}
+				if !down && !right {
+					r[2] = 0.5
+				}
+				if !down && !left {
+					r[3] = 0.5
+				}
+				roundRect(p, fx, fy, 1, 1, r)
+			default:
+				square(p, fx, fy, 1, 0)
+			}
+		}
+	}
+}
+
+// traceEyes traces the three finder patterns of an n-module symbol: a ring
+// with a 7-module outer and 5-module inner side around a 3-module ball
+func (s *Style) traceEyes(p pather, n int) {
+	for _, o := range [][2]float64{{0, 0}, {float64(n - 7), 0}, {0, float64(n - 7)}} {
+		x, y := o[0], o[1]
+		var outer, inner, ball float64
+		switch s.eye() {
+		case EyeRounded:
+			outer, inner, ball = 2, 1, 1
+		case EyeCircle:
+			outer, inner, ball = 3.5, 2.5, 1.5
+		}
+		square(p, x, y, 7, outer)
+		roundRect(p, x+6, y+1, -5, 5, [4]float64{inner, inner, inner, inner})
+		square(p, x+2, y+2, 3, ball)
+	}
+}
+
+// gradientLine returns the end points of a linear gradient at angle degrees
+// across a side-wide square at origin o, reaching both far corners
+func gradientLine(o, side, angle float64) (x0, y0, x1, y1 float64) {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	half := (math.Abs(cos) + math.Abs(sin)) * side / 2
+	c := o + side/2
+	return c - cos*half, c - sin*half, c + cos*half, c + sin*half
+}
+
+// gradientFill is an image whose colour runs from `from` to `to` along a
+// linear or radial gradient, in pixel coordinates
+type gradientFill struct {
+	from, to       color.NRGBA
+	radial         bool
+	x0, y0, x1, y1 float64 // Linear: start and end; radial: centre and a point on the end circle
+}
+
+func (g *gradientFill) ColorModel() color.Model { return color.NRGBAModel }
+
+func (g *gradientFill) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+func (g *gradientFill) At(x, y int) color.Color {
+	px, py := float64(x)+0.5, float64(y)+0.5
+	dx, dy := g.x1-g.x0, g.y1-g.y0
+	var t float64
+	if g.radial {
+		t = math.Hypot(px-g.x0, py-g.y0) / math.Hypot(dx, dy)
+	} else {
+		t = ((px-g.x0)*dx + (py-g.y0)*dy) / (dx*dx + dy*dy)
+	}
+	t = math.Max(0, math.Min(1, t))
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return color.NRGBA{
+		R: lerp(g.from.R, g.to.R), G: lerp(g.from.G, g.to.G),
+		B: lerp(g.from.B, g.to.B), A: lerp(g.from.A, g.to.A),
+	}
+}
+
+// fill returns the module fill of a symbol side units wide at origin o,
+// with coordinates multiplied by scale
+func (s *Style) fill(fg color.Color, o, side, scale float64) image.Image {
+	if s.gradient() == GradientNone {
+		return image.NewUniform(fg)
+	}
+	g := &gradientFill{
+		from:   color.NRGBAModel.Convert(fg).(color.NRGBA),
+		to:     color.NRGBAModel.Convert(s.GradientTo).(color.NRGBA),
+		radial: s.gradient() == GradientRadial,
+	}
+	if g.radial {
+		c := (o + side/2) * scale
+		g.x0, g.y0, g.x1, g.y1 = c, c, (o+side)*scale, (o+side)*scale
+	} else {
+		x0, y0, x1, y1 := gradientLine(o, side, s.GradientAngle)
+		g.x0, g.y0, g.x1, g.y1 = x0*scale, y0*scale, x1*scale, y1*scale
+	}
+	return g
+}
+
+// renderStyled draws matrix with s into a size-pixel square image using
+// module-pixel modules starting at offset, like the plain renderer
+func (s *Style) renderStyled(matrix [][]bool, size, module, offset int, fg, bg color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	n := len(matrix)
+	fill := s.fill(fg, float64(offset), float64(n*module), 1)
+	paint := func(trace func(pather), src image.Image) {
+		z := vector.NewRasterizer(size, size)
+		z.DrawOp = draw.Over
+		trace(rasterPather{z: z, scale: float64(module), offset: float64(offset)})
+		z.Draw(img, img.Bounds(), src, image.Point{})
+	}
+
+	paint(func(p pather) { s.traceModules(p, matrix) }, fill)
+	eyeFill := fill
+	if s.EyeColor != nil {
+		eyeFill = image.NewUniform(s.EyeColor)
+	}
+	paint(func(p pather) { s.traceEyes(p, n) }, eyeFill)
+	return img
+}
+
+// styledSVG writes the body of a styled SVG document: an optional gradient
+// definition and the module and finder pattern paths, in module units with
+// the symbol at offset
+func (s *Style) styledSVG(sb *strings.Builder, matrix [][]bool, offset int, fg color.Color) {
+	n := len(matrix)
+	moduleFill := svgFill(fg)
+	if s.gradient() != GradientNone {
+		moduleFill = ` fill="url(#qr-fill)"`
+		sb.WriteString("<defs>")
+		if s.gradient() == GradientRadial {
+			c := float64(offset) + float64(n)/2
+			fmt.Fprintf(sb, `<radialGradient id="qr-fill" gradientUnits="userSpaceOnUse" cx="%s" cy="%s" r="%s">`,
+				svgNum(c), svgNum(c), svgNum(float64(n)/math.Sqrt2))
+		} else {
+			x0, y0, x1, y1 := gradientLine(float64(offset), float64(n), s.GradientAngle)
+			fmt.Fprintf(sb, `<linearGradient id="qr-fill" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s">`,
+				svgNum(x0), svgNum(y0), svgNum(x1), svgNum(y1))
+		}
+		sb.WriteString(svgStop(0, fg) + svgStop(1, s.GradientTo))
+		if s.gradient() == GradientRadial {
+			sb.WriteString("</radialGradient>")
+		} else {
+			sb.WriteString("</linearGradient>")
+		}
+		sb.WriteString("</defs>\n")
+	}
+
+	p := svgPather{sb: sb, offset: float64(offset)}
+	sb.WriteString(`<path` + moduleFill + ` d="`)
+	s.traceModules(p, matrix)
+	if s.EyeColor == nil {
+		s.traceEyes(p, n)
+	}
+	sb.WriteString(`"/>` + "\n")
+
+	if s.EyeColor != nil {
+		sb.WriteString(`<path` + svgFill(s.EyeColor) + ` d="`)
+		s.traceEyes(p, n)
+		sb.WriteString(`"/>` + "\n")
+	}
+}
+
+// svgStop returns a gradient stop for c at offset (0-1)
+func svgStop(offset float64, c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	stop := fmt.Sprintf(`<stop offset="%s" stop-color="#%02x%02x%02x"`, svgNum(offset), n.R, n.G, n.B)
+	if n.A < 0xff {
+		stop += ` stop-opacity="` + strconv.FormatFloat(float64(n.A)/0xff, 'f', 3, 64) + `"`
+	}
+	return stop + "/>"
+}
+
+// styledRecovery returns level raised to at least Q (qrcode.High) when s
+// shrinks or lightens the modules
+func (s *Style) styledRecovery(level qrcode.RecoveryLevel) qrcode.RecoveryLevel {
+	if s.reducesContrast() && level < qrcode.High {
+		return qrcode.High
+	}
+	return level
+}
@@ -0,0 +1,142 @@
+package qrgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	zxqrcode "github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/skip2/go-qrcode"
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+func TestStyleCheck(t *testing.T) {
+	navy := color.NRGBA{0x00, 0x33, 0x66, 0xff}
+	grey := color.NRGBA{0xbb, 0xbb, 0xbb, 0xff}
+
+	tests := []struct {
+		name  string
+		style *Style
+		bg    color.Color
+		want  error
+	}{
+		{"nil", nil, color.White, nil},
+		{"shapes only", &Style{Shape: ShapeDot, Eye: EyeCircle}, color.White, nil},
+		{"dark eyes", &Style{EyeColor: navy}, color.White, nil},
+		{"pale eyes", &Style{EyeColor: grey}, color.White, ErrUnscannableEye},
+		{"eyes lighter than the background", &Style{EyeColor: color.White}, color.NRGBA{0x20, 0x20, 0x20, 0xff}, ErrUnscannableEye},
+		{"transparent eyes", &Style{EyeColor: color.Transparent}, color.White, ErrUnscannableEye},
+		{"pale eyes on transparent", &Style{EyeColor: grey}, color.Transparent, ErrUnscannableEye},
+		{"dark gradient", &Style{Gradient: GradientLinear, GradientTo: navy, GradientAngle: 45}, color.White, nil},
+		{"pale gradient", &Style{Gradient: GradientRadial, GradientTo: grey}, color.White, ErrUnscannableGradient},
+		{"gradient without an end", &Style{Gradient: GradientLinear}, color.White, ErrGradientColorMissing},
+		{"NaN angle", &Style{Gradient: GradientLinear, GradientTo: navy, GradientAngle: math.NaN()}, color.White, ErrInvalidGradientAngle},
+		{"infinite angle", &Style{Gradient: GradientLinear, GradientTo: navy, GradientAngle: math.Inf(1)}, color.White, ErrInvalidGradientAngle},
+	}
+	for _, tt := range tests {
+		if err := tt.style.Check(tt.bg); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Check = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestStyledRecovery(t *testing.T) {
+	tests := []struct {
+		style *Style
+		level qrcode.RecoveryLevel
+		want  qrcode.RecoveryLevel
+	}{
+		{nil, qrcode.Low, qrcode.Low},
+		{&Style{Eye: EyeRounded, EyeColor: color.Black}, qrcode.Medium, qrcode.Medium},
+		{&Style{Shape: ShapeRounded}, qrcode.Low, qrcode.High},
+		{&Style{Shape: ShapeDot}, qrcode.Medium, qrcode.High},
+		{&Style{Gradient: GradientLinear}, qrcode.Medium, qrcode.High},
+		{&Style{Shape: ShapeDot}, qrcode.Highest, qrcode.Highest},
+	}
+	for _, tt := range tests {
+		if got := tt.style.styledRecovery(tt.level); got != tt.want {
+			t.Errorf("%+v at %s: styledRecovery = %s, want %s", tt.style, RecoveryLevelName(tt.level), RecoveryLevelName(got), RecoveryLevelName(tt.want))
+		}
+	}
+}
+
+// scan decodes the QR code in img
+func scan(img image.Image) (string, error) {
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", err
+	}
+	result, err := zxqrcode.NewQRCodeReader().Decode(bmp, nil)
+	if err != nil {
+		return "", err
+	}
+	return result.GetText(), nil
+}
+
+// rasterizeTestSVG renders an SVG document at size pixels on white
+func rasterizeTestSVG(t *testing.T, doc string, size int) image.Image {
+	t.Helper()
+	icon, err := oksvg.ReadIconStream(bytes.NewReader([]byte(doc)))
+	if err != nil {
+		t.Fatalf("oksvg: %v", err)
+	}
+	icon.SetTarget(0, 0, float64(size), float64(size))
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	icon.Draw(rasterx.NewDasher(size, size, rasterx.NewScannerGV(size, size, img, img.Bounds())), 1)
+	return img
+}
+
+func TestStyledCodesScan(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	navy := color.NRGBA{0x00, 0x33, 0x66, 0xff}
+	maroon := color.NRGBA{0x80, 0x10, 0x20, 0xff}
+
+	var styles []*Style
+	for _, shape := range []ModuleShape{ShapeSquare, ShapeRounded, ShapeDot} {
+		for _, eye := range []EyeStyle{EyeSquare, EyeRounded, EyeCircle} {
+			styles = append(styles, &Style{Shape: shape, Eye: eye})
+		}
+	}
+	styles = append(styles,
+		&Style{Shape: ShapeDot, Eye: EyeCircle, EyeColor: maroon},
+		&Style{Shape: ShapeRounded, Gradient: GradientLinear, GradientTo: navy, GradientAngle: 30},
+		&Style{Shape: ShapeDot, Eye: EyeRounded, Gradient: GradientRadial, GradientTo: navy},
+	)
+
+	for _, style := range styles {
+		name := fmt.Sprintf("%s/%s", style.shape(), style.eye())
+		if style.EyeColor != nil {
+			name += "/eye_color"
+		}
+		if style.gradient() != GradientNone {
+			name += "/" + string(style.gradient())
+		}
+
+		opts := g.DefaultOptions(testContent, SizeMedium)
+		opts.Style = style
+		img, err := g.GenerateImageWithOptions(opts)
+		if err != nil {
+			t.Fatalf("%s: GenerateImageWithOptions: %v", name, err)
+		}
+		if text, err := scan(img); err != nil || text != testContent {
+			t.Errorf("%s: PNG does not scan: %q, %v", name, text, err)
+		}
+
+		doc, err := g.GenerateSVGWithOptions(opts)
+		if err != nil {
+			t.Fatalf("%s: GenerateSVGWithOptions: %v", name, err)
+		}
+		if text, err := scan(rasterizeTestSVG(t, doc, 400)); err != nil || text != testContent {
+			t.Errorf("%s: SVG does not scan: %q, %v", name, text, err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package qrgen
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// DefaultQuietZone is the margin, in modules, required by ISO/IEC 18004
+const DefaultQuietZone = 4
+
+// Matrix returns the QR module matrix for content without any quiet zone.
+// matrix[y][x] is true for a dark module.
+func Matrix(content string, level qrcode.RecoveryLevel) ([][]bool, error) {
+	qr, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+	qr.DisableBorder = true
+	return qr.Bitmap(), nil
+}
+
+// GenerateSVG generates a QR code as an SVG document
+func (g *Generator) GenerateSVG(content string, size QRSize) (string, error) {
+	return g.GenerateSVGWithOptions(g.DefaultOptions(content, size))
+}
+
+// GenerateSVGWithOptions renders the module matrix as a single SVG path.
+// Horizontal runs of dark modules are merged into one rectangle each and
+// the viewBox is expressed in modules, so the image scales without loss;
+// Size only sets the default width and height (0 leaves them out). Styled
+// codes are written as outlined shapes instead; see Style. A Logo is
+// embedded as a PNG image over modules cleared like overlayLogo's plate.
+func (g *Generator) GenerateSVGWithOptions(opts GenerateOptions) (string, error) {
+	matrix, err := Matrix(opts.Content, opts.Recovery())
+	if err != nil {
+		return "", err
+	}
+
+	quietZone := opts.QuietZone
+	if quietZone < 0 {
+		quietZone = 0
+	}
+	dim := len(matrix) + 2*quietZone
+
+	var logo string
+	if opts.Logo != nil && !opts.Logo.Bounds().Empty() {
+		n := len(matrix)
+		if plate := svgLogoPlate(opts, n, dim); plate > 2 {
+			start := (n - plate) / 2
+			for y := start; y < start+plate; y++ {
+				for x := start; x < start+plate; x++ {
+					matrix[y][x] = false
+				}
+			}
+			if logo, err = svgLogoImage(opts.Logo, quietZone+start+1, plate-2); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	background := opts.BackgroundColor
+	if background == nil {
+		background = color.White
+	}
+	foreground := opts.ForegroundColor
+	if foreground == nil {
+		foreground = color.Black
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(matrix) * len(matrix) * 4)
+
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	sb.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" version="1.1"`)
+	if logo != "" {
+		sb.WriteString(` xmlns:xlink="http://www.w3.org/1999/xlink"`)
+	}
+	if opts.Size > 0 {
+		fmt.Fprintf(&sb, ` width="%d" height="%d"`, opts.Size, opts.Size)
+	}
+	fmt.Fprintf(&sb, ` viewBox="0 0 %d %d"`, dim, dim)
+	if opts.Style.plain() {
+		sb.WriteString(` shape-rendering="crispEdges"`)
+	}
+	sb.WriteString(">\n")
+
+	if fill := svgFill(background); fill != "" {
+		fmt.Fprintf(&sb, `<rect width="%d" height="%d"%s/>`+"\n", dim, dim, fill)
+	}
+
+	if opts.Style.plain() {
+		sb.WriteString(`<path`)
+		sb.WriteString(svgFill(foreground))
+		sb.WriteString(` d="`)
+		for y, row := range matrix {
+			for x := 0; x < len(row); {
+				if !row[x] {
+					x++
+					continue
+				}
+				run := 1
+				for x+run < len(row) && row[x+run] {
+					run++
+				}
+				fmt.Fprintf(&sb, "M%d %dh%dv1h-%dz", x+quietZone, y+quietZone, run, run)
+				x += run
+			}
+		}
+		sb.WriteString(`"/>` + "\n")
+	} else {
+		opts.Style.styledSVG(&sb, matrix, quietZone, foreground)
+	}
+
+	sb.WriteString(logo)
+	sb.WriteString("</svg>\n")
+
+	return sb.String(), nil
+}
+
+// svgLogoPlate returns the side in modules of the area cleared for a logo
+// on an n-module symbol drawn dim modules wide: MaxLogoRatio of the
+// symbol, or LogoSize pixels plus a module of clearance on each side when
+// that is smaller. The side has the parity of n so the plate is centred on
+// the module grid.
+func svgLogoPlate(opts GenerateOptions, n, dim int) int {
+	plate := int(float64(n) * MaxLogoRatio)
+	if opts.LogoSize > 0 && opts.Size > 0 {
+		plate = min(plate, int(math.Ceil(float64(opts.LogoSize)*float64(dim)/float64(opts.Size)))+2)
+	}
+	if plate%2 != n%2 {
+		plate--
+	}
+	return plate
+}
+
+// svgLogoImage returns an <image> element holding logo as a PNG data URL,
+// fitted into the side-module square at (at, at). Large logos are scaled
+// down to logoRasterSize first to keep the document small.
+func svgLogoImage(logo image.Image, at, side int) (string, error) {
+	b := logo.Bounds()
+	if longest := max(b.Dx(), b.Dy()); longest > logoRasterSize {
+		logo = resizeImage(logo, max(1, b.Dx()*logoRasterSize/longest), max(1, b.Dy()*logoRasterSize/longest))
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, logo); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`<image x="%d" y="%d" width="%d" height="%d" xlink:href="data:image/png;base64,%s"/>`+"\n",
+		at, at, side, side, base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+// svgFill returns the fill attributes for c, or "" when c is fully transparent
+func svgFill(c color.Color) string {
+	rgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if rgba.A == 0 {
+		return ""
+	}
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, rgba.R, rgba.G, rgba.B)
+	if rgba.A < 0xff {
+		fill += ` fill-opacity="` + strconv.FormatFloat(float64(rgba.A)/0xff, 'f', 3, 64) + `"`
+	}
+	return fill
+}
@@ -0,0 +1,136 @@
+package qrgen
+
+import (
+	"encoding/xml"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testContent is a VietQR transfer payload used across the qrgen tests
+const testContent = "00020101021138540010A00000072701240006970436011012345678900208QRIBFTTA53037045802VN6006Ha Noi62140810Thanh toan6304FDDA"
+
+// parseSVG checks that doc is well-formed XML and returns the attributes
+// of every element by name
+func parseSVG(t *testing.T, doc string) map[string][]map[string]string {
+	t.Helper()
+	elements := make(map[string][]map[string]string)
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return elements
+		}
+		if err != nil {
+			t.Fatalf("SVG is not well-formed: %v\n%s", err, doc)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			attrs := make(map[string]string, len(start.Attr))
+			for _, a := range start.Attr {
+				attrs[a.Name.Local] = a.Value
+			}
+			elements[start.Name.Local] = append(elements[start.Name.Local], attrs)
+		}
+	}
+}
+
+var runPattern = regexp.MustCompile(`M(\d+) (\d+)h(\d+)v1h-(\d+)z`)
+
+func TestGenerateSVGRunsReproduceMatrix(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	for _, quietZone := range []int{0, DefaultQuietZone} {
+		opts := g.DefaultOptions(testContent, SizeMedium)
+		opts.QuietZone = quietZone
+
+		doc, err := g.GenerateSVGWithOptions(opts)
+		if err != nil {
+			t.Fatalf("GenerateSVGWithOptions() error = %v", err)
+		}
+		elements := parseSVG(t, doc)
+
+		matrix, err := Matrix(testContent, opts.Recovery())
+		if err != nil {
+			t.Fatal(err)
+		}
+		dim := len(matrix) + 2*quietZone
+		svg := elements["svg"][0]
+		if want := "0 0 " + strconv.Itoa(dim) + " " + strconv.Itoa(dim); svg["viewBox"] != want {
+			t.Errorf("viewBox = %q, want %q", svg["viewBox"], want)
+		}
+		if svg["width"] != "300" || svg["shape-rendering"] != "crispEdges" {
+			t.Errorf("svg attributes = %v", svg)
+		}
+		if len(elements["path"]) != 1 {
+			t.Fatalf("got %d paths, want 1", len(elements["path"]))
+		}
+
+		d := elements["path"][0]["d"]
+		if rest := runPattern.ReplaceAllString(d, ""); rest != "" {
+			t.Fatalf("path data has unexpected commands: %q", rest)
+		}
+		got := make([][]bool, dim)
+		for y := range got {
+			got[y] = make([]bool, dim)
+		}
+		runs := runPattern.FindAllStringSubmatch(d, -1)
+		for _, m := range runs {
+			x, _ := strconv.Atoi(m[1])
+			y, _ := strconv.Atoi(m[2])
+			run, _ := strconv.Atoi(m[3])
+			if m[4] != m[3] {
+				t.Fatalf("run %q does not close", m[0])
+			}
+			for i := 0; i < run; i++ {
+				if got[y][x+i] {
+					t.Fatalf("module (%d, %d) drawn twice", x+i, y)
+				}
+				got[y][x+i] = true
+			}
+		}
+		// Runs are maximal: the modules on both ends are light
+		for _, m := range runs {
+			x, _ := strconv.Atoi(m[1])
+			y, _ := strconv.Atoi(m[2])
+			run, _ := strconv.Atoi(m[3])
+			if x > 0 && got[y][x-1] || x+run < dim && got[y][x+run] {
+				t.Errorf("run %q is not merged with its neighbour", m[0])
+			}
+		}
+
+		for y := 0; y < dim; y++ {
+			for x := 0; x < dim; x++ {
+				my, mx := y-quietZone, x-quietZone
+				want := my >= 0 && my < len(matrix) && mx >= 0 && mx < len(matrix) && matrix[my][mx]
+				if got[y][x] != want {
+					t.Fatalf("quiet zone %d: module (%d, %d) = %v, want %v", quietZone, x, y, got[y][x], want)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateSVGColors(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	opts := g.DefaultOptions(testContent, 0)
+	opts.ForegroundColor = color.NRGBA{0x12, 0x34, 0x56, 0x80}
+	opts.BackgroundColor = color.Transparent
+
+	doc, err := g.GenerateSVGWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elements := parseSVG(t, doc)
+	if _, ok := elements["svg"][0]["width"]; ok {
+		t.Error("Size 0 should leave out width and height")
+	}
+	if len(elements["rect"]) != 0 {
+		t.Error("transparent background should not draw a rect")
+	}
+	path := elements["path"][0]
+	if path["fill"] != "#123456" || path["fill-opacity"] != "0.502" {
+		t.Errorf("path fill = %q opacity %q", path["fill"], path["fill-opacity"])
+	}
+}
@@ -0,0 +1,276 @@
+package qrgen
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+)
+
+// Template selects how the QR code is framed
+type Template string
+
+const (
+	TemplateBare    Template = "bare"    // QR code only
+	TemplateCard    Template = "card"    // VietQR / NAPAS 247 card with bank, holder, account and amount
+	TemplateCompact Template = "compact" // QR code with a one-line caption
+)
+
+// ErrInvalidTemplate is returned for unknown template names
+var ErrInvalidTemplate = errors.New("template must be card, compact or bare")
+
+// ErrInvalidTheme is returned for unknown theme names
+var ErrInvalidTheme = errors.New("theme must be vietqr, light or dark")
+
+// ParseTemplate parses a template name ("" means bare)
+func ParseTemplate(s string) (Template, error) {
+	switch t := Template(strings.ToLower(strings.TrimSpace(s))); t {
+	case "":
+		return TemplateBare, nil
+	case TemplateBare, TemplateCard, TemplateCompact:
+		return t, nil
+	}
+	return "", ErrInvalidTemplate
+}
+
+// Theme holds the colours of a card template
+type Theme struct {
+	Name       string
+	Background color.Color // Card background
+	Header     color.Color // Header and footer bands
+	HeaderText color.Color
+	Text       color.Color // Account holder and number
+	Muted      color.Color // Message and labels
+	Accent     color.Color // Amount and bank name when there is no logo
+}
+
+// Themes are the built-in card themes keyed by name
+var Themes = map[string]Theme{
+	"vietqr": {
+		Name:       "vietqr",
+		Background: color.White,
+		Header:     color.RGBA{0x00, 0x5b, 0xaa, 0xff}, // NAPAS blue
+		HeaderText: color.White,
+		Text:       color.RGBA{0x1a, 0x1a, 0x1a, 0xff},
+		Muted:      color.RGBA{0x6b, 0x6b, 0x6b, 0xff},
+		Accent:     color.RGBA{0xed, 0x1c, 0x24, 0xff}, // VietQR red
+	},
+	"light": {
+		Name:       "light",
+		Background: color.White,
+		Header:     color.RGBA{0xf2, 0xf4, 0xf7, 0xff},
+		HeaderText: color.RGBA{0x1a, 0x1a, 0x1a, 0xff},
+		Text:       color.RGBA{0x1a, 0x1a, 0x1a, 0xff},
+		Muted:      color.RGBA{0x6b, 0x6b, 0x6b, 0xff},
+		Accent:     color.RGBA{0x00, 0x5b, 0xaa, 0xff},
+	},
+	"dark": {
+		Name:       "dark",
+		Background: color.RGBA{0x14, 0x1b, 0x2d, 0xff},
+		Header:     color.RGBA{0x0b, 0x10, 0x1c, 0xff},
+		HeaderText: color.White,
+		Text:       color.White,
+		Muted:      color.RGBA{0xa8, 0xb0, 0xc0, 0xff},
+		Accent:     color.RGBA{0xff, 0x5a, 0x5f, 0xff},
+	},
+}
+
+// GetTheme returns the theme with the given name ("" means vietqr)
+func GetTheme(name string) (Theme, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = "vietqr"
+	}
+	if theme, ok := Themes[name]; ok {
+		return theme, nil
+	}
+	return Theme{}, ErrInvalidTheme
+}
+
+// CardInfo is the text printed around the QR code
+type CardInfo struct {
+	BankName      string      // Full bank name (footer)
+	BankShortName string      // Shown in place of the logo when Logo is nil
+	Logo          image.Image // Bank logo (optional)
+	AccountName   string
+	AccountNumber string
+	Amount        string // Formatted amount (e.g., "100.000 VND")
+	Message       string
+}
+
+// GenerateCard renders the QR code inside a template and returns PNG bytes.
+// TemplateBare returns the plain code; the QR code itself always comes from
+// GenerateImage so every template scans exactly like the bare image.
+func (g *Generator) GenerateCard(content string, size QRSize, tmpl Template, theme Theme, info CardInfo) ([]byte, error) {
+	return g.GenerateCardWithOptions(g.DefaultOptions(content, size), tmpl, theme, info)
+}
+
+// GenerateCardWithOptions is GenerateCard with custom QR options (colours,
+// quiet zone, centre logo), encoded in opts.Format. A transparent
+// background is flattened onto white before the code is placed on the
+// card, so the code keeps the contrast CheckContrast measured rather than
+// taking on the theme's background.
+func (g *Generator) GenerateCardWithOptions(opts GenerateOptions, tmpl Template, theme Theme, info CardInfo) ([]byte, error) {
+	if tmpl == "" || tmpl == TemplateBare {
+		return g.GenerateRasterWithOptions(opts)
+	}
+
+	img, err := g.GenerateImageWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	qrImage := flatten(img)
+
+	var card *image.RGBA
+	if tmpl == TemplateCompact {
+		card, err = composeCompact(qrImage, theme, info)
+	} else {
+		card, err = composeCard(qrImage, theme, info)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return g.encode(card, opts.Format, opts.DPI)
+}
+
+// composeCard lays out a header band (VietQR | napas 247), the bank logo,
+// the QR code, the holder, account number, amount and message, and a
+// footer band with the full bank name. Every dimension scales with the
+// QR image so all sizes share the same proportions.
+func composeCard(qrImage image.Image, theme Theme, info CardInfo) (*image.RGBA, error) {
+	side := qrImage.Bounds().Dx()
+	unit := float64(side) / 100
+
+	pad := int(6 * unit)
+	width := side + 2*pad
+	headerH := int(16 * unit)
+	logoH := int(12 * unit)
+	footerH := int(10 * unit)
+
+	type line struct {
+		text  string
+		size  float64
+		color color.Color
+	}
+	var lines []line
+	if info.AccountName != "" {
+		lines = append(lines, line{strings.ToUpper(info.AccountName), 6.5 * unit, theme.Text})
+	}
+	if info.AccountNumber != "" {
+		lines = append(lines, line{info.AccountNumber, 5.5 * unit, theme.Text})
+	}
+	if info.Amount != "" {
+		lines = append(lines, line{info.Amount, 6 * unit, theme.Accent})
+	}
+	if info.Message != "" {
+		lines = append(lines, line{info.Message, 4.5 * unit, theme.Muted})
+	}
+	textH := 0
+	for _, l := range lines {
+		textH += int(l.size * 1.5)
+	}
+
+	height := headerH + pad/2 + logoH + side + textH + pad/2 + footerH
+	card := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(card, card.Bounds(), image.NewUniform(theme.Background), image.Point{}, draw.Src)
+
+	// Header band
+	draw.Draw(card, image.Rect(0, 0, width, headerH), image.NewUniform(theme.Header), image.Point{}, draw.Src)
+	title, err := newFace(8 * unit)
+	if err != nil {
+		return nil, err
+	}
+	subtitle, err := newFace(4.5 * unit)
+	if err != nil {
+		return nil, err
+	}
+	drawText(card, title, "VietQR", width/2, int(8.5*unit), width-2*pad, theme.HeaderText)
+	drawText(card, subtitle, "napas 247", width/2, int(13.5*unit), width-2*pad, theme.HeaderText)
+
+	// Bank logo, or the bank's short name when there is none
+	y := headerH + pad/2
+	if info.Logo != nil {
+		logo := resizeImage(info.Logo, logoWidth(info.Logo, logoH), logoH)
+		x := (width - logo.Bounds().Dx()) / 2
+		draw.Draw(card, image.Rect(x, y, x+logo.Bounds().Dx(), y+logoH), logo, image.Point{}, draw.Over)
+	} else if info.BankShortName != "" {
+		face, err := newFace(7 * unit)
+		if err != nil {
+			return nil, err
+		}
+		drawText(card, face, info.BankShortName, width/2, y+int(9*unit), width-2*pad, theme.Accent)
+	}
+	y += logoH
+
+	// QR code
+	draw.Draw(card, image.Rect(pad, y, pad+side, y+side), qrImage, qrImage.Bounds().Min, draw.Over)
+	y += side
+
+	// Payment details
+	for _, l := range lines {
+		face, err := newFace(l.size)
+		if err != nil {
+			return nil, err
+		}
+		y += int(l.size * 1.5)
+		drawText(card, face, l.text, width/2, y-int(l.size*0.4), width-2*pad, l.color)
+	}
+
+	// Footer band
+	footerY := height - footerH
+	draw.Draw(card, image.Rect(0, footerY, width, height), image.NewUniform(theme.Header), image.Point{}, draw.Src)
+	if info.BankName != "" {
+		face, err := newFace(3.8 * unit)
+		if err != nil {
+			return nil, err
+		}
+		drawText(card, face, info.BankName, width/2, footerY+int(6.3*unit), width-2*pad, theme.HeaderText)
+	}
+
+	return card, nil
+}
+
+// composeCompact adds a caption under the QR code: the bank and account
+// number, and the amount on a second line when there is one
+func composeCompact(qrImage image.Image, theme Theme, info CardInfo) (*image.RGBA, error) {
+	side := qrImage.Bounds().Dx()
+	unit := float64(side) / 100
+	lineH := int(7 * unit)
+	captionH := lineH + int(3*unit)
+	if info.Amount != "" {
+		captionH += lineH
+	}
+
+	card := image.NewRGBA(image.Rect(0, 0, side, side+captionH))
+	draw.Draw(card, card.Bounds(), image.NewUniform(theme.Background), image.Point{}, draw.Src)
+	draw.Draw(card, image.Rect(0, 0, side, side), qrImage, qrImage.Bounds().Min, draw.Over)
+
+	parts := make([]string, 0, 2)
+	for _, part := range []string{info.BankShortName, info.AccountNumber} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	face, err := newFace(4.5 * unit)
+	if err != nil {
+		return nil, err
+	}
+	y := side + int(4*unit)
+	drawText(card, face, strings.Join(parts, " · "), side/2, y, side-int(8*unit), theme.Text)
+	if info.Amount != "" {
+		drawText(card, face, info.Amount, side/2, y+lineH, side-int(8*unit), theme.Accent)
+	}
+
+	return card, nil
+}
+
+// logoWidth returns the width that keeps the logo's aspect ratio at height
+func logoWidth(logo image.Image, height int) int {
+	b := logo.Bounds()
+	if b.Dy() == 0 {
+		return height
+	}
+	return b.Dx() * height / b.Dy()
+}
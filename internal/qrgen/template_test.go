@@ -0,0 +1,233 @@
+package qrgen
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+)
+
+func TestParseTemplateAndTheme(t *testing.T) {
+	for in, want := range map[string]Template{"": TemplateBare, "bare": TemplateBare, " Card ": TemplateCard, "compact": TemplateCompact} {
+		if got, err := ParseTemplate(in); err != nil || got != want {
+			t.Errorf("ParseTemplate(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseTemplate("poster"); err != ErrInvalidTemplate {
+		t.Errorf("ParseTemplate(poster) error = %v, want ErrInvalidTemplate", err)
+	}
+
+	for in, want := range map[string]string{"": "vietqr", "VietQR": "vietqr", "light": "light", "dark": "dark"} {
+		if got, err := GetTheme(in); err != nil || got.Name != want {
+			t.Errorf("GetTheme(%q) = %q, %v, want %q", in, got.Name, err, want)
+		}
+	}
+	if _, err := GetTheme("neon"); err != ErrInvalidTheme {
+		t.Errorf("GetTheme(neon) error = %v, want ErrInvalidTheme", err)
+	}
+}
+
+// sameColor reports whether two colours are equal once converted to RGBA
+func sameColor(a, b color.Color) bool {
+	return color.RGBAModel.Convert(a) == color.RGBAModel.Convert(b)
+}
+
+// checkQRCopied checks that qr appears unchanged in img at (x, y)
+func checkQRCopied(t *testing.T, name string, img image.Image, qr image.Image, x, y int) {
+	t.Helper()
+	b := qr.Bounds()
+	for qy := b.Min.Y; qy < b.Max.Y; qy++ {
+		for qx := b.Min.X; qx < b.Max.X; qx++ {
+			if !sameColor(img.At(x+qx-b.Min.X, y+qy-b.Min.Y), qr.At(qx, qy)) {
+				t.Fatalf("%s: pixel (%d,%d) of the code differs in the template", name, qx, qy)
+			}
+		}
+	}
+}
+
+func TestComposeCardLayout(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	qr, err := g.GenerateImageWithOptions(g.DefaultOptions(testContent, SizeMedium))
+	if err != nil {
+		t.Fatalf("GenerateImageWithOptions: %v", err)
+	}
+	info := CardInfo{
+		BankName:      "Ngân hàng TMCP Ngoại thương Việt Nam",
+		BankShortName: "Vietcombank",
+		AccountName:   "Nguyen Van A",
+		AccountNumber: "1234567890",
+		Amount:        "100.000 VND",
+		Message:       "Thanh toan don hang",
+	}
+
+	// Dimensions follow the QR side: 300px gives 3px units
+	const side, pad, headerH, logoH, footerH = 300, 18, 48, 36, 30
+	for name, theme := range Themes {
+		card, err := composeCard(qr, theme, info)
+		if err != nil {
+			t.Fatalf("%s: composeCard: %v", name, err)
+		}
+		const textH = 29 + 24 + 27 + 20 // Each line is 1.5 times its font size
+		if w, h := card.Bounds().Dx(), card.Bounds().Dy(); w != side+2*pad || h != headerH+pad/2+logoH+side+textH+pad/2+footerH {
+			t.Errorf("%s: card is %dx%d", name, w, h)
+		}
+
+		checkQRCopied(t, name, card, qr, pad, headerH+pad/2+logoH)
+		if !sameColor(card.At(1, 1), theme.Header) {
+			t.Errorf("%s: header is %v, want %v", name, card.At(1, 1), theme.Header)
+		}
+		if !sameColor(card.At(1, card.Bounds().Dy()-2), theme.Header) {
+			t.Errorf("%s: footer is %v, want %v", name, card.At(1, card.Bounds().Dy()-2), theme.Header)
+		}
+		if !sameColor(card.At(1, headerH+pad), theme.Background) {
+			t.Errorf("%s: background is %v, want %v", name, card.At(1, headerH+pad), theme.Background)
+		}
+	}
+
+	// Without details the card shrinks to the header, logo, code and footer
+	card, err := composeCard(qr, Themes["light"], CardInfo{})
+	if err != nil {
+		t.Fatalf("composeCard(empty): %v", err)
+	}
+	if h := card.Bounds().Dy(); h != headerH+pad/2+logoH+side+pad/2+footerH {
+		t.Errorf("empty card height = %d", h)
+	}
+}
+
+func TestComposeCardTruncatesText(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	qr, err := g.GenerateImageWithOptions(g.DefaultOptions(testContent, SizeSmall))
+	if err != nil {
+		t.Fatalf("GenerateImageWithOptions: %v", err)
+	}
+	theme := Themes["vietqr"]
+	long := strings.Repeat("Thanh toan don hang ", 20)
+	card, err := composeCard(qr, theme, CardInfo{AccountName: long, Message: long, BankName: long})
+	if err != nil {
+		t.Fatalf("composeCard: %v", err)
+	}
+
+	// Text stops at the 12px padding, so the outer columns below the code
+	// and above the 20px footer stay blank
+	const pad, top, footerH = 12, 32 + 6 + 24 + 200, 20
+	width := card.Bounds().Dx()
+	for y := top; y < card.Bounds().Dy()-footerH; y++ {
+		for _, x := range []int{0, pad / 2, width - 1 - pad/2, width - 1} {
+			if !sameColor(card.At(x, y), theme.Background) {
+				t.Fatalf("text reaches the padding at (%d,%d)", x, y)
+			}
+		}
+	}
+}
+
+func TestComposeCompact(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	qr, err := g.GenerateImageWithOptions(g.DefaultOptions(testContent, SizeMedium))
+	if err != nil {
+		t.Fatalf("GenerateImageWithOptions: %v", err)
+	}
+	theme := Themes["dark"]
+
+	const lineH, captionH = 21, 21 + 9
+	for _, tt := range []struct {
+		amount string
+		height int
+	}{{"", 300 + captionH}, {"50.000 VND", 300 + captionH + lineH}} {
+		card, err := composeCompact(qr, theme, CardInfo{BankShortName: "VCB", AccountNumber: "1234567890", Amount: tt.amount})
+		if err != nil {
+			t.Fatalf("composeCompact: %v", err)
+		}
+		if w, h := card.Bounds().Dx(), card.Bounds().Dy(); w != 300 || h != tt.height {
+			t.Errorf("amount %q: compact card is %dx%d, want 300x%d", tt.amount, w, h, tt.height)
+		}
+		checkQRCopied(t, "compact", card, qr, 0, 0)
+		if !sameColor(card.At(0, card.Bounds().Dy()-1), theme.Background) {
+			t.Errorf("caption background is %v, want %v", card.At(0, card.Bounds().Dy()-1), theme.Background)
+		}
+	}
+}
+
+func TestGenerateCardBareIsPlainImage(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	opts := g.DefaultOptions(testContent, SizeSmall)
+	bare, err := g.GenerateCardWithOptions(opts, TemplateBare, Themes["vietqr"], CardInfo{AccountName: "A"})
+	if err != nil {
+		t.Fatalf("GenerateCardWithOptions: %v", err)
+	}
+	plain, err := g.GenerateRasterWithOptions(opts)
+	if err != nil {
+		t.Fatalf("GenerateRasterWithOptions: %v", err)
+	}
+	if !bytes.Equal(bare, plain) {
+		t.Error("bare template differs from the plain code")
+	}
+}
+
+func TestFitText(t *testing.T) {
+	face, err := newFace(20)
+	if err != nil {
+		t.Fatalf("newFace: %v", err)
+	}
+
+	if got := fitText(face, "Vietcombank", 500); got != "Vietcombank" {
+		t.Errorf("short text = %q, want it unchanged", got)
+	}
+
+	long := "Ngân hàng TMCP Ngoại thương Việt Nam"
+	got := fitText(face, long, 150)
+	if !strings.HasSuffix(got, "…") || !strings.HasPrefix(long, strings.TrimSuffix(got, "…")) {
+		t.Errorf("long text = %q, want a prefix with an ellipsis", got)
+	}
+	if w := font.MeasureString(face, got).Round(); w > 150 {
+		t.Errorf("truncated text is %dpx wide, want at most 150", w)
+	}
+	if next := []rune(long)[:len([]rune(got))]; font.MeasureString(face, string(next)+"…").Round() <= 150 {
+		t.Errorf("text was cut shorter than needed: %q", got)
+	}
+
+	if got := fitText(face, long, 1); got != "" {
+		t.Errorf("text in 1px = %q, want empty", got)
+	}
+}
+
+func TestCardFlattensTransparentBackground(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	opts := g.DefaultOptions(testContent, SizeMedium)
+	opts.BackgroundColor = color.Transparent
+
+	for _, tmpl := range []Template{TemplateCard, TemplateCompact} {
+		data, err := g.GenerateCardWithOptions(opts, tmpl, Themes["dark"], CardInfo{AccountNumber: "1234567890"})
+		if err != nil {
+			t.Fatalf("%s: GenerateCardWithOptions: %v", tmpl, err)
+		}
+		card, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl, err)
+		}
+
+		// The quiet zone is white and a dark module reaches full contrast
+		x, y := 0, 0
+		if tmpl == TemplateCard {
+			x, y = 18, 48+9+36
+		}
+		quiet := card.At(x+1, y+1)
+		if !sameColor(quiet, color.White) {
+			t.Errorf("%s: quiet zone is %v on the dark theme, want white", tmpl, quiet)
+		}
+		var darkest color.Color = color.White
+		for py := y; py < y+300; py++ {
+			for px := x; px < x+300; px++ {
+				if c := card.At(px, py); ContrastRatio(c, color.White) > ContrastRatio(darkest, color.White) {
+					darkest = c
+				}
+			}
+		}
+		if ratio := ContrastRatio(darkest, quiet); ratio < MinContrastRatio {
+			t.Errorf("%s: modules have %.2f:1 contrast, want at least %.0f:1", tmpl, ratio, MinContrastRatio)
+		}
+	}
+}
@@ -0,0 +1,546 @@
+package qrgen
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"math/bits"
+	"sort"
+)
+
+// Lossless WebP (VP8L) encoder covering what QR images need: a colour
+// indexing transform for images of up to 256 colours, packing two-colour
+// codes eight pixels per byte, prefix codes built from the pixel statistics
+// and LZ77 references to the previous pixel, the row above and earlier
+// repeats. There is no predictor transform or colour cache.
+
+const (
+	vp8lMaxDimension  = 1 << 14
+	vp8lMaxLength     = 4096    // Longest backward reference
+	vp8lMaxDistance   = 1 << 20 // Largest distance code the prefix coding reaches
+	vp8lMinMatch      = 3
+	vp8lLengthCodes   = 24
+	vp8lDistanceCodes = 40
+	vp8lPlaneCodes    = 120 // Distance codes 1-120 are offsets in the 2D neighbourhood
+	vp8lHashBits      = 16
+)
+
+// ErrWebPTooLarge is returned for images WebP cannot hold
+var ErrWebPTooLarge = errors.New("webp images are limited to 16384x16384 pixels")
+
+// codeLengthOrder is the order code length code lengths are written in
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// vp8lWriter writes bits least significant first
+type vp8lWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (w *vp8lWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nacc
+	w.nacc += n
+	for w.nacc >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nacc -= 8
+	}
+}
+
+func (w *vp8lWriter) flush() []byte {
+	if w.nacc > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nacc = 0, 0
+	}
+	return w.buf
+}
+
+// prefixCode is one prefix (Huffman) code: per-symbol bit-reversed codes
+// and their lengths
+type prefixCode struct {
+	codes   []uint32
+	lengths []uint8
+}
+
+func (p *prefixCode) put(w *vp8lWriter, symbol int) {
+	w.write(p.codes[symbol], uint(p.lengths[symbol]))
+}
+
+// vp8lToken is a literal ARGB pixel (length 0) or a backward reference
+type vp8lToken struct {
+	argb     uint32
+	length   int
+	distCode int
+}
+
+// encodeWebP encodes img as a lossless WebP file
+func encodeWebP(img image.Image) ([]byte, error) {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return nil, ErrWebPTooLarge
+	}
+
+	argb := make([]uint32, 0, width*height)
+	alpha := uint32(0)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			argb = append(argb, uint32(c.A)<<24|uint32(c.R)<<16|uint32(c.G)<<8|uint32(c.B))
+			if c.A != 0xff {
+				alpha = 1
+			}
+		}
+	}
+
+	w := &vp8lWriter{}
+	w.write(0x2f, 8) // Signature
+	w.write(uint32(width-1), 14)
+	w.write(uint32(height-1), 14)
+	w.write(alpha, 1)
+	w.write(0, 3) // Version
+
+	pixels, xsize := argb, width
+	if palette := vp8lPalette(argb); palette != nil {
+		w.write(1, 1) // Transform present
+		w.write(3, 2) // Colour indexing
+		w.write(uint32(len(palette)-1), 8)
+		// Palette entries are stored as differences from the previous one
+		deltas := make([]uint32, len(palette))
+		for i, c := range palette {
+			deltas[i] = c
+			if i > 0 {
+				deltas[i] = subPixels(c, palette[i-1])
+			}
+		}
+		writeEntropyImage(w, deltas, len(deltas), false)
+		pixels, xsize = bundlePixels(argb, width, height, palette)
+	}
+	w.write(0, 1) // No more transforms
+	writeEntropyImage(w, pixels, xsize, true)
+
+	data := w.flush()
+	pad := len(data) & 1
+	out := make([]byte, 0, 20+len(data)+pad)
+	out = append(out, "RIFF"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(4+8+len(data)+pad))
+	out = append(out, "WEBPVP8L"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(data)))
+	out = append(out, data...)
+	if pad == 1 {
+		out = append(out, 0)
+	}
+	return out, nil
+}
+
+// vp8lPalette returns the colours of argb when there are at most 256,
+// ordered by first appearance, or nil
+func vp8lPalette(argb []uint32) []uint32 {
+	seen := make(map[uint32]struct{}, 256)
+	var palette []uint32
+	for _, c := range argb {
+		if _, ok := seen[c]; ok {
+			continue
+		}
+		if len(palette) == 256 {
+			return nil
+		}
+		seen[c] = struct{}{}
+		palette = append(palette, c)
+	}
+	return palette
+}
+
+// subPixels subtracts b from a per channel, modulo 256
+func subPixels(a, b uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		out |= (((a >> shift) - (b >> shift)) & 0xff) << shift
+	}
+	return out
+}
+
+// bundlePixels replaces pixels with their palette index in the green
+// channel, packing 2, 4 or 8 indexes into each pixel for palettes of up to
+// 16, 4 or 2 colours. It returns the packed image and its width.
+func bundlePixels(argb []uint32, width, height int, palette []uint32) ([]uint32, int) {
+	index := make(map[uint32]uint32, len(palette))
+	for i, c := range palette {
+		index[c] = uint32(i)
+	}
+
+	xbits := 0
+	switch {
+	case len(palette) <= 2:
+		xbits = 3
+	case len(palette) <= 4:
+		xbits = 2
+	case len(palette) <= 16:
+		xbits = 1
+	}
+	bitsPerIndex := uint(8 >> xbits)
+	packedWidth := (width + 1<<xbits - 1) >> xbits
+
+	packed := make([]uint32, packedWidth*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*packedWidth + x>>xbits
+			packed[i] |= index[argb[y*width+x]] << (uint(x&(1<<xbits-1)) * bitsPerIndex)
+		}
+	}
+	for i, v := range packed {
+		packed[i] = 0xff000000 | v<<8
+	}
+	return packed, packedWidth
+}
+
+// writeEntropyImage writes pixels (xsize wide) as prefix-coded literals and
+// backward references with a single group of prefix codes
+func writeEntropyImage(w *vp8lWriter, pixels []uint32, xsize int, spatial bool) {
+	w.write(0, 1) // No colour cache
+	if spatial {
+		w.write(0, 1) // No meta prefix codes
+	}
+
+	tokens := backwardRefs(pixels, xsize)
+
+	green := make([]int, 256+vp8lLengthCodes)
+	red := make([]int, 256)
+	blue := make([]int, 256)
+	alpha := make([]int, 256)
+	dist := make([]int, vp8lDistanceCodes)
+	for _, t := range tokens {
+		if t.length == 0 {
+			green[t.argb>>8&0xff]++
+			red[t.argb>>16&0xff]++
+			blue[t.argb&0xff]++
+			alpha[t.argb>>24]++
+			continue
+		}
+		code, _, _ := prefixEncode(t.length)
+		green[256+code]++
+		code, _, _ = prefixEncode(t.distCode)
+		dist[code]++
+	}
+
+	codes := [5]*prefixCode{}
+	for i, freq := range [][]int{green, red, blue, alpha, dist} {
+		codes[i] = writePrefixCode(w, freq)
+	}
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			codes[0].put(w, int(t.argb>>8&0xff))
+			codes[1].put(w, int(t.argb>>16&0xff))
+			codes[2].put(w, int(t.argb&0xff))
+			codes[3].put(w, int(t.argb>>24))
+			continue
+		}
+		code, n, extra := prefixEncode(t.length)
+		codes[0].put(w, 256+code)
+		w.write(uint32(extra), n)
+		code, n, extra = prefixEncode(t.distCode)
+		codes[4].put(w, code)
+		w.write(uint32(extra), n)
+	}
+}
+
+// backwardRefs splits pixels into literals and greedy LZ77 matches against
+// the previous pixel, the pixel above and the last position with the same
+// two pixels
+func backwardRefs(pixels []uint32, xsize int) []vp8lToken {
+	n := len(pixels)
+	last := make([]int32, 1<<vp8lHashBits)
+	for i := range last {
+		last[i] = -1
+	}
+	hash := func(i int) uint32 {
+		return (pixels[i]*0x9e3779b1 ^ pixels[i+1]*0x85ebca6b) >> (32 - vp8lHashBits)
+	}
+	insert := func(i int) {
+		if i+1 < n {
+			last[hash(i)] = int32(i)
+		}
+	}
+
+	tokens := make([]vp8lToken, 0, n/4)
+	for i := 0; i < n; {
+		bestLen, bestDist := 0, 0
+		candidates := [3]int{i - 1, i - xsize, -1}
+		if i+1 < n {
+			candidates[2] = int(last[hash(i)])
+		}
+		for _, c := range candidates {
+			if c < 0 || c >= i || distanceCode(i-c, xsize) > vp8lMaxDistance {
+				continue
+			}
+			l := 0
+			for l < vp8lMaxLength && i+l < n && pixels[c+l] == pixels[i+l] {
+				l++
+			}
+			if l > bestLen {
+				bestLen, bestDist = l, i-c
+			}
+		}
+
+		if bestLen < vp8lMinMatch {
+			tokens = append(tokens, vp8lToken{argb: pixels[i]})
+			insert(i)
+			i++
+			continue
+		}
+		tokens = append(tokens, vp8lToken{length: bestLen, distCode: distanceCode(bestDist, xsize)})
+		for j := i; j < i+bestLen; j++ {
+			insert(j)
+		}
+		i += bestLen
+	}
+	return tokens
+}
+
+// distanceCode maps a pixel distance to its distance code: the plane codes
+// for the pixel above (1) and the previous pixel (2), or distance + 120
+func distanceCode(distance, xsize int) int {
+	switch distance {
+	case xsize:
+		return 1
+	case 1:
+		return 2
+	}
+	return distance + vp8lPlaneCodes
+}
+
+// prefixEncode splits a length or distance code (>= 1) into its prefix
+// symbol and extra bits
+func prefixEncode(v int) (code int, extraBits uint, extra int) {
+	d := v - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	high := bits.Len(uint(d)) - 1
+	second := (d >> (high - 1)) & 1
+	extraBits = uint(high - 1)
+	return 2*high + second, extraBits, d & (1<<extraBits - 1)
+}
+
+// writePrefixCode writes the prefix code for the symbol frequencies freq
+// and returns it. One or two symbols below 256 use the simple form.
+func writePrefixCode(w *vp8lWriter, freq []int) *prefixCode {
+	var used []int
+	for s, f := range freq {
+		if f > 0 {
+			used = append(used, s)
+		}
+	}
+	code := &prefixCode{codes: make([]uint32, len(freq)), lengths: make([]uint8, len(freq))}
+
+	if len(used) == 0 {
+		used = []int{0}
+	}
+	if len(used) <= 2 && used[len(used)-1] < 256 {
+		w.write(1, 1) // Simple code
+		w.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			w.write(0, 1)
+			w.write(uint32(used[0]), 1)
+		} else {
+			w.write(1, 1)
+			w.write(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			w.write(uint32(used[1]), 8)
+			code.lengths[used[0]], code.lengths[used[1]] = 1, 1
+			code.codes[used[1]] = 1
+		}
+		return code
+	}
+
+	// A lone symbol still needs a complete two-leaf tree
+	if len(used) == 1 {
+		freq = append([]int(nil), freq...)
+		freq[(used[0]+1)%len(freq)] = 1
+	}
+	code.lengths = huffmanLengths(freq, 15)
+	code.codes = canonicalCodes(code.lengths)
+
+	w.write(0, 1) // Normal code
+	tokens := runLengths(code.lengths)
+	clFreq := make([]int, 19)
+	for _, t := range tokens {
+		clFreq[t[0]]++
+	}
+	if nonZero(clFreq) == 1 {
+		dummy := 0
+		if clFreq[0] > 0 {
+			dummy = 1
+		}
+		clFreq[dummy] = 1
+	}
+	clLengths := huffmanLengths(clFreq, 7)
+	clCodes := canonicalCodes(clLengths)
+
+	count := 4
+	for i := len(codeLengthOrder) - 1; i >= 4; i-- {
+		if clLengths[codeLengthOrder[i]] != 0 {
+			count = i + 1
+			break
+		}
+	}
+	w.write(uint32(count-4), 4)
+	for _, s := range codeLengthOrder[:count] {
+		w.write(uint32(clLengths[s]), 3)
+	}
+	w.write(0, 1) // Lengths for the whole alphabet follow
+	for _, t := range tokens {
+		w.write(clCodes[t[0]], uint(clLengths[t[0]]))
+		switch t[0] {
+		case 16:
+			w.write(uint32(t[1]-3), 2)
+		case 17:
+			w.write(uint32(t[1]-3), 3)
+		case 18:
+			w.write(uint32(t[1]-11), 7)
+		}
+	}
+	return code
+}
+
+// runLengths encodes code lengths with the repeat codes: 16 repeats the
+// previous length 3-6 times, 17 and 18 write 3-10 and 11-138 zeros. Each
+// token is {symbol, repeat count}.
+func runLengths(lengths []uint8) [][2]int {
+	var tokens [][2]int
+	for i := 0; i < len(lengths); {
+		l := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == l {
+			run++
+		}
+		i += run
+
+		if l == 0 {
+			for run >= 11 {
+				n := min(run, 138)
+				tokens = append(tokens, [2]int{18, n})
+				run -= n
+			}
+			if run >= 3 {
+				tokens = append(tokens, [2]int{17, run})
+				run = 0
+			}
+			for ; run > 0; run-- {
+				tokens = append(tokens, [2]int{0, 0})
+			}
+			continue
+		}
+
+		tokens = append(tokens, [2]int{int(l), 0})
+		run--
+		for run >= 3 {
+			n := min(run, 6)
+			tokens = append(tokens, [2]int{16, n})
+			run -= n
+		}
+		for ; run > 0; run-- {
+			tokens = append(tokens, [2]int{int(l), 0})
+		}
+	}
+	return tokens
+}
+
+func nonZero(freq []int) int {
+	n := 0
+	for _, f := range freq {
+		if f > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// huffmanLengths returns Huffman code lengths for freq no longer than
+// limit, halving the frequencies until the tree is shallow enough
+func huffmanLengths(freq []int, limit int) []uint8 {
+	freq = append([]int(nil), freq...)
+	for {
+		lengths, depth := buildHuffman(freq)
+		if depth <= limit {
+			return lengths
+		}
+		for i, f := range freq {
+			if f > 0 {
+				freq[i] = (f + 1) / 2
+			}
+		}
+	}
+}
+
+// buildHuffman returns the Huffman code lengths of freq (at least two
+// non-zero entries) and the longest of them
+func buildHuffman(freq []int) ([]uint8, int) {
+	type node struct {
+		weight int
+		parent int
+	}
+	var nodes []node
+	var leaves []int // Symbol of each leaf node
+	for s, f := range freq {
+		if f > 0 {
+			nodes = append(nodes, node{weight: f, parent: -1})
+			leaves = append(leaves, s)
+		}
+	}
+
+	queue := make([]int, len(nodes))
+	for i := range queue {
+		queue[i] = i
+	}
+	for len(queue) > 1 {
+		sort.SliceStable(queue, func(a, b int) bool { return nodes[queue[a]].weight < nodes[queue[b]].weight })
+		a, b := queue[0], queue[1]
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, parent: -1})
+		nodes[a].parent, nodes[b].parent = len(nodes)-1, len(nodes)-1
+		queue = append(queue[2:], len(nodes)-1)
+	}
+
+	lengths := make([]uint8, len(freq))
+	depth := 0
+	for i, s := range leaves {
+		d := 0
+		for p := nodes[i].parent; p >= 0; p = nodes[p].parent {
+			d++
+		}
+		lengths[s] = uint8(min(d, 255))
+		depth = max(depth, d)
+	}
+	return lengths, depth
+}
+
+// canonicalCodes assigns canonical codes to lengths, bit-reversed for the
+// least-significant-first bit writer
+func canonicalCodes(lengths []uint8) []uint32 {
+	var count [16]uint32
+	for _, l := range lengths {
+		if l > 0 {
+			count[l]++
+		}
+	}
+	var next [16]uint32
+	code := uint32(0)
+	for l := 1; l < 16; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		codes[s] = bits.Reverse32(next[l]) >> (32 - uint(l))
+		next[l]++
+	}
+	return codes
+}
@@ -0,0 +1,60 @@
+package qrgen
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// zplMaxMagnification is the largest ^BQ magnification factor
+const zplMaxMagnification = 10
+
+// GenerateZPL returns a ZPL II label with the QR code centred on a label
+// PaperWidth wide. The native mode uses the ^BQ barcode command; raster
+// mode sends the rendered bitmap as a ^GF graphic field.
+func (g *Generator) GenerateZPL(opts PrinterOptions) ([]byte, error) {
+	layout, err := layoutPrinter(opts, 0, zplMaxMagnification)
+	if err != nil {
+		return nil, err
+	}
+
+	size := layout.size()
+	quiet := layout.quietZone * layout.module
+
+	var sb strings.Builder
+	sb.WriteString("^XA\n")
+	// UTF-8 field data, so tag 64 reaches ^BQ byte for byte and the CRC holds
+	sb.WriteString("^CI28\n")
+	fmt.Fprintf(&sb, "^PW%d\n^LL%d\n^LH0,0\n", layout.width, size)
+
+	if opts.Raster {
+		rows, rowBytes := layout.packRows(layout.width)
+		fmt.Fprintf(&sb, "^FO0,0^GFA,%d,%d,%d,", rowBytes*len(rows), rowBytes*len(rows), rowBytes)
+		for _, row := range rows {
+			sb.WriteString(strings.ToUpper(hex.EncodeToString(row)))
+		}
+		sb.WriteString("^FS\n")
+	} else {
+		x := (layout.width - len(layout.matrix)*layout.module) / 2
+		fmt.Fprintf(&sb, "^FO%d,%d^BQN,2,%d\n", x, quiet, layout.module)
+		// ^FH lets _XX hex escapes carry the ZPL control characters
+		fmt.Fprintf(&sb, "^FH^FD%sA,%s^FS\n", RecoveryLevelName(opts.RecoveryLevel), zplEscape(opts.Content))
+	}
+
+	sb.WriteString("^XZ\n")
+	return []byte(sb.String()), nil
+}
+
+// zplEscape hex-escapes the characters ZPL treats as commands or escapes
+func zplEscape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '^', '~', '_':
+			fmt.Fprintf(&sb, "_%02X", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
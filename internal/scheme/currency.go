@@ -0,0 +1,35 @@
+package scheme
+
+// Currency is an ISO 4217 currency as used in tag 53 and tag 54
+type Currency struct {
+	Code     string `json:"code"`     // Alpha code (e.g., "THB")
+	Numeric  string `json:"numeric"`  // Numeric code carried in tag 53 (e.g., "764")
+	Decimals int    `json:"decimals"` // Digits after the decimal point in tag 54
+}
+
+// Currencies maps alpha codes to the currencies the supported schemes use.
+// VND, KHR and IDR amounts are whole numbers in their QR schemes.
+var Currencies = map[string]*Currency{
+	"VND": {Code: "VND", Numeric: "704", Decimals: 0},
+	"THB": {Code: "THB", Numeric: "764", Decimals: 2},
+	"KHR": {Code: "KHR", Numeric: "116", Decimals: 0},
+	"USD": {Code: "USD", Numeric: "840", Decimals: 2},
+	"SGD": {Code: "SGD", Numeric: "702", Decimals: 2},
+	"MYR": {Code: "MYR", Numeric: "458", Decimals: 2},
+	"IDR": {Code: "IDR", Numeric: "360", Decimals: 0},
+}
+
+// GetCurrency returns the currency with the given alpha code, or nil
+func GetCurrency(code string) *Currency {
+	return Currencies[code]
+}
+
+// GetCurrencyByNumeric returns the currency with the given numeric code, or nil
+func GetCurrencyByNumeric(numeric string) *Currency {
+	for _, c := range Currencies {
+		if c.Numeric == numeric {
+			return c
+		}
+	}
+	return nil
+}
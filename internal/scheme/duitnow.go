@@ -0,0 +1,120 @@
+package scheme
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// DuitNow identifiers and sub-tags (tag 26, PayNet Malaysia)
+const (
+	duitNowGUID           = "A0000006150001"
+	duitNowSubTagAcquirer = "01" // Acquirer (PayNet participant) ID
+	duitNowSubTagAccount  = "02" // Merchant account or proxy ID
+	duitNowMaxAccountID   = 25
+	duitNowDefaultMCC     = "0000"
+	duitNowDefaultCity    = "Kuala Lumpur"
+)
+
+// DuitNow errors
+var (
+	ErrInvalidAcquirer = errors.New("acquirer ID must be 6 digits")
+	ErrInvalidDuitNow  = errors.New("DuitNow account ID must be 1-25 letters and digits")
+)
+
+// duitNowScheme implements Malaysian DuitNow QR codes
+type duitNowScheme struct{}
+
+func (duitNowScheme) ID() string           { return DuitNow }
+func (duitNowScheme) Name() string         { return "DuitNow QR" }
+func (duitNowScheme) Country() string      { return "MY" }
+func (duitNowScheme) Currencies() []string { return []string{"MYR"} }
+
+// Encode builds a DuitNow payload for the account ID in Payment.Account,
+// acquired by the participant whose 6-digit ID is in Payment.Bank
+func (s duitNowScheme) Encode(p Payment) (string, error) {
+	currency, err := resolveCurrency(s, p.Currency)
+	if err != nil {
+		return "", err
+	}
+	amount, err := formatAmount(p.Amount, currency)
+	if err != nil {
+		return "", err
+	}
+
+	if len(p.Bank) != 6 || !isDigits(p.Bank) {
+		return "", &vietqr.FieldError{Field: "bank_code", Tag: "26.01", Err: ErrInvalidAcquirer}
+	}
+	accountID := strings.ReplaceAll(p.Account, " ", "")
+	if accountID == "" || len(accountID) > duitNowMaxAccountID || !isAlphanumericUpper(strings.ToUpper(accountID)) {
+		return "", &vietqr.FieldError{Field: "account_number", Tag: "26.02", Err: ErrInvalidDuitNow}
+	}
+
+	if p.MerchantName == "" {
+		return "", &vietqr.FieldError{Field: "merchant_name", Tag: vietqr.TagMerchantName, Err: vietqr.ErrFieldRequired}
+	}
+	name, err := checkText("merchant_name", vietqr.TagMerchantName, p.MerchantName, 25)
+	if err != nil {
+		return "", err
+	}
+	if p.MerchantCity == "" {
+		p.MerchantCity = duitNowDefaultCity
+	}
+	city, err := checkText("merchant_city", vietqr.TagMerchantCity, p.MerchantCity, 15)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	encodeHeader(&sb, amount != "" && !p.Editable)
+	sb.WriteString(vietqr.BuildTLV("26", vietqr.BuildTLV(vietqr.SubTagGUID, duitNowGUID)+
+		vietqr.BuildTLV(duitNowSubTagAcquirer, p.Bank)+
+		vietqr.BuildTLV(duitNowSubTagAccount, accountID)))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantCategory, duitNowDefaultMCC))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagCurrency, currency.Numeric))
+	if amount != "" {
+		sb.WriteString(vietqr.BuildTLV(vietqr.TagAmount, amount))
+	}
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagCountry, s.Country()))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantName, name))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantCity, city))
+	additional, err := encodeAdditionalData(p)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(additional)
+
+	return vietqr.AppendCRC(sb.String()), nil
+}
+
+// Decode reads a DuitNow payload from whichever tag 26-51 carries its AID
+func (duitNowScheme) Decode(qrString string) (*Decoded, error) {
+	decoded, err := decodeCommon(DuitNow, qrString)
+	if err != nil {
+		return nil, err
+	}
+
+	account := findTemplate(decoded.Tree, duitNowGUID)
+	if account == nil {
+		return nil, ErrNotThisScheme
+	}
+	decoded.Account = account.Get(duitNowSubTagAccount)
+	decoded.Bank = account.Get(duitNowSubTagAcquirer)
+
+	return decoded, nil
+}
+
+// Validate checks the EMVCo structure and the DuitNow merchant account
+func (duitNowScheme) Validate(qrString string) vietqr.ValidationErrors {
+	errs := vietqr.ValidatePayload(qrString, mandatoryTags(vietqr.TagMerchantName))
+	tree, _ := vietqr.ParseTLV(qrString)
+	if findTemplate(tree, duitNowGUID) == nil {
+		errs = append(errs, &vietqr.ValidationError{
+			Code:     vietqr.CodeMissingTag,
+			Path:     "26",
+			Expected: "DuitNow merchant account (AID " + duitNowGUID + ")",
+		})
+	}
+	return errs
+}
@@ -0,0 +1,181 @@
+package scheme
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// KHQR account types (National Bank of Cambodia, Bakong)
+const (
+	KHQRIndividual = "individual" // Tag 29 - individual Bakong account
+	KHQRMerchant   = "merchant"   // Tag 30 - merchant account with merchant ID
+)
+
+// KHQR field limits and defaults
+const (
+	khqrMaxAccountID   = 32
+	khqrDefaultMCC     = "5999"
+	khqrDefaultCity    = "Phnom Penh"
+	khqrTagTimestamp   = "99"
+	khqrSubTagCreation = "00"
+)
+
+// KHQR errors
+var (
+	ErrInvalidBakongID    = errors.New("Bakong account ID must look like name@bank and be at most 32 characters")
+	ErrInvalidAccountType = errors.New("account type must be individual or merchant")
+)
+
+// now is replaced in tests to make timestamps deterministic
+var now = time.Now
+
+// khqrScheme implements Cambodian KHQR (Bakong) QR codes
+type khqrScheme struct{}
+
+func (khqrScheme) ID() string           { return KHQR }
+func (khqrScheme) Name() string         { return "KHQR (Bakong)" }
+func (khqrScheme) Country() string      { return "KH" }
+func (khqrScheme) Currencies() []string { return []string{"KHR", "USD"} }
+
+// Encode builds a KHQR payload for the Bakong account ID in Payment.Account.
+// Merchant accounts (AccountType "merchant") also need MerchantID and Bank
+// (the acquiring bank). Dynamic QRs carry their creation time in tag 99.
+func (s khqrScheme) Encode(p Payment) (string, error) {
+	currency, err := resolveCurrency(s, p.Currency)
+	if err != nil {
+		return "", err
+	}
+	amount, err := formatAmount(p.Amount, currency)
+	if err != nil {
+		return "", err
+	}
+
+	account, err := khqrAccount(p)
+	if err != nil {
+		return "", err
+	}
+
+	if p.MerchantName == "" {
+		return "", &vietqr.FieldError{Field: "merchant_name", Tag: vietqr.TagMerchantName, Err: vietqr.ErrFieldRequired}
+	}
+	name, err := checkText("merchant_name", vietqr.TagMerchantName, p.MerchantName, 25)
+	if err != nil {
+		return "", err
+	}
+	if p.MerchantCity == "" {
+		p.MerchantCity = khqrDefaultCity
+	}
+	city, err := checkText("merchant_city", vietqr.TagMerchantCity, p.MerchantCity, 15)
+	if err != nil {
+		return "", err
+	}
+
+	dynamic := amount != "" && !p.Editable
+
+	var sb strings.Builder
+	encodeHeader(&sb, dynamic)
+	sb.WriteString(account)
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantCategory, khqrDefaultMCC))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagCurrency, currency.Numeric))
+	if amount != "" {
+		sb.WriteString(vietqr.BuildTLV(vietqr.TagAmount, amount))
+	}
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagCountry, s.Country()))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantName, name))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantCity, city))
+	additional, err := encodeAdditionalData(p)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(additional)
+	if dynamic {
+		created := strconv.FormatInt(now().UnixMilli(), 10)
+		sb.WriteString(vietqr.BuildTLV(khqrTagTimestamp, vietqr.BuildTLV(khqrSubTagCreation, created)))
+	}
+
+	return vietqr.AppendCRC(sb.String()), nil
+}
+
+// Decode reads an individual (tag 29) or merchant (tag 30) KHQR payload
+func (khqrScheme) Decode(qrString string) (*Decoded, error) {
+	decoded, err := decodeCommon(KHQR, qrString)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := decoded.Tree
+	switch {
+	case tree.Find("30", "01") != nil && isBakongID(tree.Get("30", "00")):
+		decoded.Account = tree.Get("30", "00")
+		decoded.AccountType = KHQRMerchant
+		decoded.Bank = tree.Get("30", "02")
+	case isBakongID(tree.Get("29", "00")):
+		decoded.Account = tree.Get("29", "00")
+		decoded.AccountType = KHQRIndividual
+		decoded.Bank = tree.Get("29", "02")
+	default:
+		return nil, ErrNotThisScheme
+	}
+
+	return decoded, nil
+}
+
+// Validate checks the EMVCo structure and the KHQR mandatory fields
+func (khqrScheme) Validate(qrString string) vietqr.ValidationErrors {
+	errs := vietqr.ValidatePayload(qrString, mandatoryTags(vietqr.TagMerchantName, vietqr.TagMerchantCity))
+	tree, _ := vietqr.ParseTLV(qrString)
+	if !isBakongID(tree.Get("29", "00")) && !isBakongID(tree.Get("30", "00")) {
+		errs = append(errs, &vietqr.ValidationError{
+			Code:     vietqr.CodeMissingTag,
+			Path:     "29",
+			Expected: "Bakong account (tag 29 or 30)",
+		})
+	}
+	return errs
+}
+
+// khqrAccount builds the tag 29 or tag 30 merchant account template
+func khqrAccount(p Payment) (string, error) {
+	if !isBakongID(p.Account) {
+		return "", &vietqr.FieldError{Field: "account_number", Tag: "29.00", Err: ErrInvalidBakongID}
+	}
+	if len(p.Bank) > khqrMaxAccountID {
+		return "", &vietqr.FieldError{Field: "bank_code", Tag: "29.02", Limit: khqrMaxAccountID, Err: vietqr.ErrFieldTooLong}
+	}
+
+	switch strings.ToLower(p.AccountType) {
+	case "", KHQRIndividual:
+		data := vietqr.BuildTLV("00", p.Account)
+		if p.Bank != "" {
+			data += vietqr.BuildTLV("02", p.Bank)
+		}
+		return vietqr.BuildTLV("29", data), nil
+	case KHQRMerchant:
+		if p.MerchantID == "" {
+			return "", &vietqr.FieldError{Field: "merchant_id", Tag: "30.01", Err: vietqr.ErrFieldRequired}
+		}
+		if len(p.MerchantID) > khqrMaxAccountID {
+			return "", &vietqr.FieldError{Field: "merchant_id", Tag: "30.01", Limit: khqrMaxAccountID, Err: vietqr.ErrFieldTooLong}
+		}
+		if p.Bank == "" {
+			return "", &vietqr.FieldError{Field: "bank_code", Tag: "30.02", Err: vietqr.ErrFieldRequired}
+		}
+		return vietqr.BuildTLV("30", vietqr.BuildTLV("00", p.Account)+
+			vietqr.BuildTLV("01", p.MerchantID)+vietqr.BuildTLV("02", p.Bank)), nil
+	}
+
+	return "", &vietqr.FieldError{Field: "account_type", Tag: "29", Err: ErrInvalidAccountType}
+}
+
+// isBakongID reports whether s is a Bakong account ID (name@bank)
+func isBakongID(s string) bool {
+	at := strings.IndexByte(s, '@')
+	if at <= 0 || at == len(s)-1 || len(s) > khqrMaxAccountID {
+		return false
+	}
+	return !strings.ContainsAny(s, " \t") && strings.Count(s, "@") == 1
+}
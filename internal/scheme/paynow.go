@@ -0,0 +1,223 @@
+package scheme
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// PayNow identifiers and sub-tags (tag 26)
+const (
+	payNowGUID          = "SG.PAYNOW"
+	payNowSubTagType    = "01" // Proxy type
+	payNowSubTagValue   = "02" // Proxy value
+	payNowSubTagEdit    = "03" // Amount editable: "1" yes, "0" no
+	payNowSubTagExpiry  = "04" // Expiry date, YYYYMMDD
+	payNowDefaultName   = "NA"
+	payNowDefaultCity   = "Singapore"
+	payNowExpiryLayout  = "20060102"
+	payNowDecodedLayout = "2006-01-02"
+)
+
+// singapore is Singapore Standard Time (UTC+8, no daylight saving), the
+// zone PayNow expiry dates are in. A fixed zone avoids depending on tzdata.
+var singapore = time.FixedZone("SGT", 8*60*60)
+
+// PayNow proxy types
+const (
+	PayNowMobile = "mobile" // Proxy type 0 - Singapore mobile number
+	PayNowUEN    = "uen"    // Proxy type 2 - Unique Entity Number
+)
+
+// payNowProxyCodes maps proxy types to their tag 26.01 value
+var payNowProxyCodes = map[string]string{
+	PayNowMobile: "0",
+	PayNowUEN:    "2",
+}
+
+// PayNow errors
+var (
+	ErrInvalidPayNowProxy = errors.New("proxy type must be mobile or uen")
+	ErrInvalidMobile      = errors.New("mobile number must be an 8-digit Singapore number starting with 8 or 9")
+	ErrInvalidUEN         = errors.New("UEN must be 9 or 10 letters and digits ending in a letter")
+	ErrExpiryInPast       = errors.New("expiry date is in the past")
+)
+
+// payNowScheme implements Singapore PayNow QR codes
+type payNowScheme struct{}
+
+func (payNowScheme) ID() string           { return PayNow }
+func (payNowScheme) Name() string         { return "PayNow" }
+func (payNowScheme) Country() string      { return "SG" }
+func (payNowScheme) Currencies() []string { return []string{"SGD"} }
+
+// Encode builds a PayNow payload to the mobile number or UEN in
+// Payment.Account. Payment.Editable lets the payer change the amount and
+// Payment.Expiry, when set, is carried as the expiry date.
+func (s payNowScheme) Encode(p Payment) (string, error) {
+	currency, err := resolveCurrency(s, p.Currency)
+	if err != nil {
+		return "", err
+	}
+	amount, err := formatAmount(p.Amount, currency)
+	if err != nil {
+		return "", err
+	}
+
+	proxyType, proxy, err := payNowProxy(p.AccountType, p.Account)
+	if err != nil {
+		return "", err
+	}
+
+	if p.MerchantName == "" {
+		p.MerchantName = payNowDefaultName
+	}
+	name, err := checkText("merchant_name", vietqr.TagMerchantName, p.MerchantName, 25)
+	if err != nil {
+		return "", err
+	}
+	if p.MerchantCity == "" {
+		p.MerchantCity = payNowDefaultCity
+	}
+	city, err := checkText("merchant_city", vietqr.TagMerchantCity, p.MerchantCity, 15)
+	if err != nil {
+		return "", err
+	}
+
+	editable := "0"
+	if p.Editable || amount == "" {
+		editable = "1"
+	}
+	account := vietqr.BuildTLV(vietqr.SubTagGUID, payNowGUID) +
+		vietqr.BuildTLV(payNowSubTagType, proxyType) +
+		vietqr.BuildTLV(payNowSubTagValue, proxy) +
+		vietqr.BuildTLV(payNowSubTagEdit, editable)
+	if !p.Expiry.IsZero() {
+		// Compare calendar dates: the expiry day is valid until it ends in Singapore
+		if p.Expiry.Format(payNowExpiryLayout) < now().In(singapore).Format(payNowExpiryLayout) {
+			return "", &vietqr.FieldError{Field: "expiry", Tag: "26.04", Err: ErrExpiryInPast}
+		}
+		account += vietqr.BuildTLV(payNowSubTagExpiry, p.Expiry.Format(payNowExpiryLayout))
+	}
+
+	var sb strings.Builder
+	encodeHeader(&sb, amount != "" && !p.Editable)
+	sb.WriteString(vietqr.BuildTLV("26", account))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantCategory, "0000"))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagCurrency, currency.Numeric))
+	if amount != "" {
+		sb.WriteString(vietqr.BuildTLV(vietqr.TagAmount, amount))
+	}
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagCountry, s.Country()))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantName, name))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantCity, city))
+	additional, err := encodeAdditionalData(p)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(additional)
+
+	return vietqr.AppendCRC(sb.String()), nil
+}
+
+// Decode reads a PayNow payload from whichever tag 26-51 carries SG.PAYNOW
+func (payNowScheme) Decode(qrString string) (*Decoded, error) {
+	decoded, err := decodeCommon(PayNow, qrString)
+	if err != nil {
+		return nil, err
+	}
+
+	account := findTemplate(decoded.Tree, payNowGUID)
+	if account == nil {
+		return nil, ErrNotThisScheme
+	}
+
+	decoded.Account = account.Get(payNowSubTagValue)
+	decoded.AccountType = account.Get(payNowSubTagType)
+	for kind, code := range payNowProxyCodes {
+		if code == decoded.AccountType {
+			decoded.AccountType = kind
+		}
+	}
+	decoded.Editable = account.Get(payNowSubTagEdit) == "1"
+	if expiry, err := time.Parse(payNowExpiryLayout, account.Get(payNowSubTagExpiry)); err == nil {
+		decoded.Expiry = expiry.Format(payNowDecodedLayout)
+	}
+
+	return decoded, nil
+}
+
+// Validate checks the EMVCo structure and the PayNow merchant account
+func (payNowScheme) Validate(qrString string) vietqr.ValidationErrors {
+	errs := vietqr.ValidatePayload(qrString, mandatoryTags(vietqr.TagMerchantName, vietqr.TagMerchantCity))
+	tree, _ := vietqr.ParseTLV(qrString)
+	if account := findTemplate(tree, payNowGUID); account == nil {
+		errs = append(errs, &vietqr.ValidationError{
+			Code:     vietqr.CodeMissingTag,
+			Path:     "26",
+			Expected: "PayNow merchant account (GUID " + payNowGUID + ")",
+		})
+	} else {
+		for _, subTag := range []string{payNowSubTagType, payNowSubTagValue, payNowSubTagEdit} {
+			if account.Find(subTag) == nil {
+				errs = append(errs, &vietqr.ValidationError{
+					Code:     vietqr.CodeMissingTag,
+					Path:     account.Path + "." + subTag,
+					Offset:   account.Offset,
+					Expected: "tag " + account.Path + "." + subTag,
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// payNowProxy returns the tag 26.01 proxy type and the normalized proxy value
+func payNowProxy(kind, value string) (string, string, error) {
+	value = strings.ToUpper(strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, value))
+
+	if kind == "" {
+		kind = PayNowUEN
+		if d := strings.TrimPrefix(value, "+"); isDigits(d) {
+			kind = PayNowMobile
+		}
+	}
+	kind = strings.ToLower(kind)
+
+	switch kind {
+	case PayNowMobile:
+		digits := strings.TrimPrefix(value, "+")
+		if len(digits) == 10 && strings.HasPrefix(digits, "65") {
+			digits = digits[2:]
+		}
+		if len(digits) != 8 || !isDigits(digits) || (digits[0] != '8' && digits[0] != '9') {
+			return "", "", &vietqr.FieldError{Field: "account_number", Tag: "26.02", Err: ErrInvalidMobile}
+		}
+		return payNowProxyCodes[kind], "+65" + digits, nil
+	case PayNowUEN:
+		if len(value) < 9 || len(value) > 10 || !isAlphanumericUpper(value) || isDigits(value[len(value)-1:]) {
+			return "", "", &vietqr.FieldError{Field: "account_number", Tag: "26.02", Err: ErrInvalidUEN}
+		}
+		return payNowProxyCodes[kind], value, nil
+	}
+
+	return "", "", &vietqr.FieldError{Field: "account_type", Tag: "26.01", Err: ErrInvalidPayNowProxy}
+}
+
+// isAlphanumericUpper reports whether s only contains digits and uppercase letters
+func isAlphanumericUpper(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,196 @@
+package scheme
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// PromptPay identifiers (Bank of Thailand)
+const (
+	promptPayCreditTransfer = "A000000677010111" // Tag 29 - transfer to a PromptPay proxy
+	promptPayBillPayment    = "A000000677010112" // Tag 30 - bill payment to a biller ID
+)
+
+// PromptPay proxy types
+const (
+	PromptPayMobile     = "mobile"      // 29.01 - Thai mobile number
+	PromptPayNationalID = "national_id" // 29.02 - National ID or tax ID (13 digits)
+	PromptPayEWallet    = "ewallet"     // 29.03 - E-wallet ID (15 digits)
+	PromptPayBiller     = "biller"      // 30.01 - Biller ID (decoding only)
+)
+
+// PromptPay errors
+var (
+	ErrInvalidProxyType  = errors.New("proxy type must be mobile, national_id or ewallet")
+	ErrInvalidProxyValue = errors.New("invalid PromptPay proxy")
+)
+
+// promptPayScheme implements Thai PromptPay credit transfer QR codes
+type promptPayScheme struct{}
+
+func (promptPayScheme) ID() string           { return PromptPay }
+func (promptPayScheme) Name() string         { return "PromptPay" }
+func (promptPayScheme) Country() string      { return "TH" }
+func (promptPayScheme) Currencies() []string { return []string{"THB"} }
+
+// Encode builds a PromptPay credit transfer to the proxy in Payment.Account.
+// AccountType selects the proxy; when empty it is inferred from the length.
+func (s promptPayScheme) Encode(p Payment) (string, error) {
+	currency, err := resolveCurrency(s, p.Currency)
+	if err != nil {
+		return "", err
+	}
+	amount, err := formatAmount(p.Amount, currency)
+	if err != nil {
+		return "", err
+	}
+
+	subTag, proxy, err := promptPayProxy(p.AccountType, p.Account)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := checkText("merchant_name", vietqr.TagMerchantName, p.MerchantName, 25)
+	if err != nil {
+		return "", err
+	}
+	city, err := checkText("merchant_city", vietqr.TagMerchantCity, p.MerchantCity, 15)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	encodeHeader(&sb, amount != "" && !p.Editable)
+	sb.WriteString(vietqr.BuildTLV("29",
+		vietqr.BuildTLV(vietqr.SubTagGUID, promptPayCreditTransfer)+vietqr.BuildTLV(subTag, proxy)))
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagCurrency, currency.Numeric))
+	if amount != "" {
+		sb.WriteString(vietqr.BuildTLV(vietqr.TagAmount, amount))
+	}
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagCountry, s.Country()))
+	if name != "" {
+		sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantName, name))
+	}
+	if city != "" {
+		sb.WriteString(vietqr.BuildTLV(vietqr.TagMerchantCity, city))
+	}
+	additional, err := encodeAdditionalData(p)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(additional)
+
+	return vietqr.AppendCRC(sb.String()), nil
+}
+
+// Decode reads a PromptPay credit transfer (tag 29) or bill payment (tag 30)
+func (promptPayScheme) Decode(qrString string) (*Decoded, error) {
+	decoded, err := decodeCommon(PromptPay, qrString)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := decoded.Tree
+	switch {
+	case strings.EqualFold(tree.Get("29", vietqr.SubTagGUID), promptPayCreditTransfer):
+		for _, proxy := range []struct{ subTag, kind string }{
+			{"01", PromptPayMobile}, {"02", PromptPayNationalID}, {"03", PromptPayEWallet},
+		} {
+			if value := tree.Get("29", proxy.subTag); value != "" {
+				decoded.Account, decoded.AccountType = value, proxy.kind
+				break
+			}
+		}
+		if decoded.AccountType == PromptPayMobile && len(decoded.Account) == 13 && strings.HasPrefix(decoded.Account, "0066") {
+			decoded.Account = "0" + decoded.Account[4:]
+		}
+	case strings.EqualFold(tree.Get("30", vietqr.SubTagGUID), promptPayBillPayment):
+		decoded.Account = tree.Get("30", "01")
+		decoded.AccountType = PromptPayBiller
+		decoded.BillNumber = tree.Get("30", "02")
+	default:
+		return nil, ErrNotThisScheme
+	}
+
+	return decoded, nil
+}
+
+// Validate checks the EMVCo structure and the PromptPay merchant account
+func (promptPayScheme) Validate(qrString string) vietqr.ValidationErrors {
+	errs := vietqr.ValidatePayload(qrString, mandatoryTags())
+	tree, _ := vietqr.ParseTLV(qrString)
+	if tree.Find("29", vietqr.SubTagGUID) == nil && tree.Find("30", vietqr.SubTagGUID) == nil {
+		errs = append(errs, &vietqr.ValidationError{
+			Code:     vietqr.CodeMissingTag,
+			Path:     "29",
+			Expected: "PromptPay merchant account (tag 29 or 30)",
+		})
+	}
+	return errs
+}
+
+// promptPayProxy returns the tag 29 sub-tag and encoded value of a proxy
+func promptPayProxy(kind, value string) (string, string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		if r == ' ' || r == '-' || r == '+' {
+			return -1
+		}
+		return 'x'
+	}, value)
+	invalid := &vietqr.FieldError{Field: "account_number", Tag: "29", Err: ErrInvalidProxyValue}
+
+	if kind == "" {
+		switch len(digits) {
+		case 13:
+			kind = PromptPayNationalID
+		case 15:
+			kind = PromptPayEWallet
+		default:
+			kind = PromptPayMobile
+		}
+	}
+
+	switch strings.ToLower(kind) {
+	case PromptPayMobile:
+		// Mobile numbers are carried as 0066 followed by the 9-digit national number
+		switch {
+		case len(digits) == 10 && digits[0] == '0':
+			digits = digits[1:]
+		case len(digits) == 11 && strings.HasPrefix(digits, "66"):
+			digits = digits[2:]
+		}
+		if len(digits) != 9 || !isDigits(digits) {
+			return "", "", invalid
+		}
+		return "01", "0066" + digits, nil
+	case PromptPayNationalID:
+		if len(digits) != 13 || !thaiIDChecksumValid(digits) {
+			return "", "", invalid
+		}
+		return "02", digits, nil
+	case PromptPayEWallet:
+		if len(digits) != 15 || !isDigits(digits) {
+			return "", "", invalid
+		}
+		return "03", digits, nil
+	}
+
+	return "", "", &vietqr.FieldError{Field: "account_type", Tag: "29", Err: ErrInvalidProxyType}
+}
+
+// thaiIDChecksumValid verifies the mod-11 check digit of a Thai national or tax ID
+func thaiIDChecksumValid(id string) bool {
+	if !isDigits(id) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(id[i]-'0') * (13 - i)
+	}
+	return int(id[12]-'0') == (11-sum%11)%10
+}
@@ -0,0 +1,210 @@
+package scheme
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// QRIS identifiers and sub-tags (Bank Indonesia)
+const (
+	qrisGUID             = "ID.CO.QRIS.WWW"
+	qrisTagNational      = "51" // National merchant template (NMID)
+	qrisSubTagPAN        = "01" // Acquirer template: merchant PAN
+	qrisSubTagMerchantID = "02" // Acquirer template: merchant ID; tag 51: NMID
+	qrisSubTagCriteria   = "03" // Merchant criteria
+	qrisCurrency         = "360"
+	qrisCountry          = "ID"
+)
+
+// QRISCriteria maps merchant criteria codes to their meaning
+var QRISCriteria = map[string]string{
+	"UMI": "micro",
+	"UKE": "small",
+	"UME": "medium",
+	"UBE": "large",
+	"URE": "regular",
+}
+
+// ErrEncodeNotSupported is returned by schemes that only decode
+var ErrEncodeNotSupported = errors.New("this scheme only supports decoding and validation")
+
+// QRISInfo holds the QRIS-specific fields of a payload
+type QRISInfo struct {
+	NMID       string         `json:"nmid"`                  // 51.02 - National Merchant ID
+	Criteria   string         `json:"criteria"`              // 51.03 - UMI, UKE, UME, UBE or URE
+	MCC        string         `json:"mcc"`                   // 52
+	PostalCode string         `json:"postal_code,omitempty"` // 61
+	Acquirers  []QRISAcquirer `json:"acquirers"`             // Tags 26-45
+}
+
+// QRISAcquirer is one domestic merchant account template (tags 26-45)
+type QRISAcquirer struct {
+	Tag         string `json:"tag"`
+	GUID        string `json:"guid"`                  // Reverse domain of the acquirer (e.g., ID.CO.BANKMANDIRI.WWW)
+	MerchantPAN string `json:"merchant_pan"`          // 01
+	MerchantID  string `json:"merchant_id,omitempty"` // 02
+	Criteria    string `json:"criteria,omitempty"`    // 03
+}
+
+// qrisScheme decodes and validates Indonesian QRIS payloads
+type qrisScheme struct{}
+
+func (qrisScheme) ID() string           { return QRIS }
+func (qrisScheme) Name() string         { return "QRIS" }
+func (qrisScheme) Country() string      { return qrisCountry }
+func (qrisScheme) Currencies() []string { return []string{"IDR"} }
+
+// Encode is not supported: QRIS payloads are issued by licensed acquirers
+func (qrisScheme) Encode(Payment) (string, error) {
+	return "", ErrEncodeNotSupported
+}
+
+// Decode reads the national merchant template, the acquirer templates and
+// the merchant fields of a QRIS payload
+func (qrisScheme) Decode(qrString string) (*Decoded, error) {
+	decoded, err := decodeCommon(QRIS, qrString)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := decoded.Tree
+	national := tree.Find(qrisTagNational)
+	if national == nil || !strings.EqualFold(national.Get(vietqr.SubTagGUID), qrisGUID) {
+		return nil, ErrNotThisScheme
+	}
+
+	info := &QRISInfo{
+		NMID:       national.Get(qrisSubTagMerchantID),
+		Criteria:   national.Get(qrisSubTagCriteria),
+		MCC:        tree.Get(vietqr.TagMerchantCategory),
+		PostalCode: tree.Get(vietqr.TagPostalCode),
+	}
+	for _, node := range qrisAcquirerNodes(tree) {
+		info.Acquirers = append(info.Acquirers, QRISAcquirer{
+			Tag:         node.Tag,
+			GUID:        node.Get(vietqr.SubTagGUID),
+			MerchantPAN: node.Get(qrisSubTagPAN),
+			MerchantID:  node.Get(qrisSubTagMerchantID),
+			Criteria:    node.Get(qrisSubTagCriteria),
+		})
+	}
+
+	decoded.Account = info.NMID
+	decoded.AccountType = "nmid"
+	if len(info.Acquirers) > 0 {
+		decoded.Bank = info.Acquirers[0].GUID
+	}
+	decoded.QRIS = info
+
+	return decoded, nil
+}
+
+// Validate checks the EMVCo structure and the QRIS rules: national
+// template with a well-formed NMID, merchant criteria, at least one
+// acquirer template, MCC, IDR currency and Indonesian country code
+func (qrisScheme) Validate(qrString string) vietqr.ValidationErrors {
+	errs := vietqr.ValidatePayload(qrString, mandatoryTags(
+		qrisTagNational,
+		qrisTagNational+"."+vietqr.SubTagGUID,
+		qrisTagNational+"."+qrisSubTagMerchantID,
+		qrisTagNational+"."+qrisSubTagCriteria,
+		vietqr.TagMerchantCategory,
+		vietqr.TagMerchantName,
+		vietqr.TagMerchantCity,
+	))
+	tree, _ := vietqr.ParseTLV(qrString)
+
+	invalid := func(node *vietqr.TLVNode, expected string) {
+		errs = append(errs, &vietqr.ValidationError{
+			Code:     vietqr.CodeInvalidValue,
+			Path:     node.Path,
+			Offset:   node.Offset,
+			Expected: expected,
+			Actual:   node.Value,
+		})
+	}
+
+	if node := tree.Find(qrisTagNational, vietqr.SubTagGUID); node != nil && !strings.EqualFold(node.Value, qrisGUID) {
+		invalid(node, qrisGUID)
+	}
+	if node := tree.Find(qrisTagNational, qrisSubTagMerchantID); node != nil && !isNMID(node.Value) {
+		invalid(node, "NMID: ID followed by 13 digits")
+	}
+	if node := tree.Find(qrisTagNational, qrisSubTagCriteria); node != nil && QRISCriteria[node.Value] == "" {
+		invalid(node, "merchant criteria UMI, UKE, UME, UBE or URE")
+	}
+
+	acquirers := qrisAcquirerNodes(tree)
+	if len(acquirers) == 0 {
+		errs = append(errs, &vietqr.ValidationError{
+			Code:     vietqr.CodeMissingTag,
+			Path:     "26",
+			Expected: "at least one acquirer template (tags 26-45)",
+		})
+	}
+	for _, acquirer := range acquirers {
+		if acquirer.Find(vietqr.SubTagGUID) == nil {
+			errs = append(errs, &vietqr.ValidationError{
+				Code:     vietqr.CodeMissingTag,
+				Path:     acquirer.Path + "." + vietqr.SubTagGUID,
+				Offset:   acquirer.Offset,
+				Expected: "acquirer GUID",
+			})
+		}
+		if pan := acquirer.Find(qrisSubTagPAN); pan == nil {
+			errs = append(errs, &vietqr.ValidationError{
+				Code:     vietqr.CodeMissingTag,
+				Path:     acquirer.Path + "." + qrisSubTagPAN,
+				Offset:   acquirer.Offset,
+				Expected: "merchant PAN",
+			})
+		} else if len(pan.Value) < 16 || len(pan.Value) > 19 || !isDigits(pan.Value) {
+			invalid(pan, "merchant PAN of 16-19 digits")
+		}
+		if criteria := acquirer.Find(qrisSubTagCriteria); criteria != nil && QRISCriteria[criteria.Value] == "" {
+			invalid(criteria, "merchant criteria UMI, UKE, UME, UBE or URE")
+		}
+	}
+
+	if node := tree.Find(vietqr.TagMerchantCategory); node != nil && !vietqr.IsValidMCC(node.Value) {
+		invalid(node, "ISO 18245 merchant category code")
+	}
+	if node := tree.Find(vietqr.TagCurrency); node != nil && node.Value != qrisCurrency {
+		invalid(node, qrisCurrency+" (IDR)")
+	}
+	if node := tree.Find(vietqr.TagCountry); node != nil && node.Value != qrisCountry {
+		invalid(node, qrisCountry)
+	}
+	if node := tree.Find(vietqr.TagAmount); node != nil {
+		if amount, err := strconv.ParseFloat(node.Value, 64); err != nil || amount <= 0 {
+			invalid(node, "positive decimal amount")
+		}
+	}
+	if node := tree.Find(vietqr.TagPostalCode); node != nil && (len(node.Value) != 5 || !isDigits(node.Value)) {
+		invalid(node, "5-digit postal code")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// qrisAcquirerNodes returns the merchant account templates in tags 26-45
+func qrisAcquirerNodes(tree *vietqr.TLVTree) []*vietqr.TLVNode {
+	var nodes []*vietqr.TLVNode
+	for _, node := range tree.Nodes {
+		if node.Tag >= "26" && node.Tag <= "45" && node.IsTemplate() {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// isNMID reports whether s is a National Merchant ID ("ID" and 13 digits)
+func isNMID(s string) bool {
+	return len(s) == 15 && strings.HasPrefix(s, "ID") && isDigits(s[2:])
+}
@@ -0,0 +1,294 @@
+// Package scheme generates and parses merchant-presented QR codes for the
+// EMVCo-based national payment schemes the API supports. Each scheme shares
+// the TLV and CRC primitives of the vietqr package.
+package scheme
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// Scheme IDs
+const (
+	VietQR    = "vietqr"
+	PromptPay = "promptpay"
+	KHQR      = "khqr"
+	PayNow    = "paynow"
+	DuitNow   = "duitnow"
+	QRIS      = "qris"
+)
+
+// Scheme errors
+var (
+	ErrUnknownScheme       = errors.New("unknown payment scheme")
+	ErrUnsupportedCurrency = errors.New("currency is not supported by this scheme")
+	ErrNotThisScheme       = errors.New("payload does not belong to this scheme")
+)
+
+// Scheme is a QR payment scheme
+type Scheme interface {
+	ID() string                                       // Stable identifier (e.g., "promptpay")
+	Name() string                                     // Display name
+	Country() string                                  // ISO 3166-1 alpha-2 country code
+	Currencies() []string                             // ISO 4217 alpha codes, default first
+	Encode(p Payment) (string, error)                 // Strictly validated payload, or a *vietqr.FieldError
+	Decode(qrString string) (*Decoded, error)         // Lenient decoding of a payload of this scheme
+	Validate(qrString string) vietqr.ValidationErrors // Structural problems, or nil
+}
+
+// Payment is a scheme-neutral payment request
+type Payment struct {
+	Account      string // Account number, proxy value or payment address
+	AccountType  string // Scheme-specific account kind (e.g., "mobile" for PromptPay)
+	Bank         string // Bank BIN (VietQR) or acquiring bank name
+	Amount       string // Amount in major units as a decimal ("12.50"); "" or zero for an open amount
+	Currency     string // ISO 4217 alpha code ("" for the scheme default)
+	MerchantName string
+	MerchantCity string
+	MerchantID   string    // Merchant ID for schemes with merchant accounts (KHQR)
+	Message      string    // Purpose of transaction (tag 62.08)
+	BillNumber   string    // Tag 62.01
+	Editable     bool      // Let the payer change the amount
+	Expiry       time.Time // Last day the QR can be paid (PayNow); zero for none
+}
+
+// Decoded is a scheme-neutral view of a decoded payload
+type Decoded struct {
+	Scheme       string          `json:"scheme"`
+	Account      string          `json:"account"`
+	AccountType  string          `json:"account_type,omitempty"`
+	Bank         string          `json:"bank,omitempty"`
+	Amount       float64         `json:"amount"`
+	Currency     string          `json:"currency"`
+	Country      string          `json:"country"`
+	MerchantName string          `json:"merchant_name,omitempty"`
+	MerchantCity string          `json:"merchant_city,omitempty"`
+	Message      string          `json:"message,omitempty"`
+	BillNumber   string          `json:"bill_number,omitempty"`
+	Dynamic      bool            `json:"dynamic"`
+	Editable     bool            `json:"editable,omitempty"` // Payer may change the amount (PayNow)
+	Expiry       string          `json:"expiry,omitempty"`   // Expiry date, YYYY-MM-DD (PayNow)
+	QRIS         *QRISInfo       `json:"qris,omitempty"`     // NMID, criteria and acquirers (QRIS)
+	IsValid      bool            `json:"is_valid"`           // CRC check
+	Tree         *vietqr.TLVTree `json:"tlv"`
+}
+
+// schemes is the registry of supported schemes keyed by ID
+var schemes = map[string]Scheme{
+	VietQR:    vietQRScheme{},
+	PromptPay: promptPayScheme{},
+	KHQR:      khqrScheme{},
+	PayNow:    payNowScheme{},
+	DuitNow:   duitNowScheme{},
+	QRIS:      qrisScheme{},
+}
+
+// Get returns the scheme with the given ID ("" means VietQR)
+func Get(id string) (Scheme, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == "" {
+		id = VietQR
+	}
+	if s, ok := schemes[id]; ok {
+		return s, nil
+	}
+	return nil, ErrUnknownScheme
+}
+
+// Detect returns the ID of the scheme a payload belongs to, judged by its
+// merchant account templates. Payloads no other scheme claims are VietQR.
+func Detect(qrString string) string {
+	tree, _ := vietqr.ParseTLV(qrString)
+
+	switch {
+	case findTemplate(tree, vietqr.NAPAS_GUID) != nil:
+		return VietQR
+	case strings.EqualFold(tree.Get(qrisTagNational, vietqr.SubTagGUID), qrisGUID):
+		return QRIS
+	case findTemplate(tree, payNowGUID) != nil:
+		return PayNow
+	case findTemplate(tree, duitNowGUID) != nil:
+		return DuitNow
+	case findTemplate(tree, promptPayCreditTransfer) != nil, findTemplate(tree, promptPayBillPayment) != nil:
+		return PromptPay
+	case isBakongID(tree.Get("29", "00")), isBakongID(tree.Get("30", "00")):
+		return KHQR
+	}
+
+	return VietQR
+}
+
+// All returns every registered scheme sorted by ID
+func All() []Scheme {
+	all := make([]Scheme, 0, len(schemes))
+	for _, s := range schemes {
+		all = append(all, s)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID() < all[j].ID() })
+	return all
+}
+
+// resolveCurrency returns the currency to encode, checking it against the
+// scheme's supported list
+func resolveCurrency(s Scheme, code string) (*Currency, error) {
+	supported := s.Currencies()
+	if code == "" {
+		code = supported[0]
+	}
+	code = strings.ToUpper(code)
+	for _, c := range supported {
+		if c == code {
+			return GetCurrency(code), nil
+		}
+	}
+	return nil, &vietqr.FieldError{Field: "currency", Tag: vietqr.TagCurrency, Err: ErrUnsupportedCurrency}
+}
+
+// formatAmount renders a decimal amount in major units (e.g. "12.5") with
+// the currency's minor-unit digits, working on the digits so no precision is
+// lost to floating point. It returns "" for an open amount ("" or zero) and
+// rejects amounts that are not plain non-negative decimals, more precise
+// than the currency allows or longer than 13 bytes.
+func formatAmount(amount string, currency *Currency) (string, error) {
+	invalid := &vietqr.FieldError{Field: "amount", Tag: vietqr.TagAmount, Err: vietqr.ErrInvalidAmount}
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return "", nil
+	}
+
+	whole, frac, decimal := strings.Cut(amount, ".")
+	if !isDigits(whole) || (decimal && !isDigits(frac)) {
+		return "", invalid
+	}
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > currency.Decimals {
+		return "", invalid
+	}
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" && frac == "" {
+		return "", nil
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	value := whole
+	if currency.Decimals > 0 {
+		value += "." + frac + strings.Repeat("0", currency.Decimals-len(frac))
+	}
+	if len(value) > 13 {
+		return "", invalid
+	}
+	return value, nil
+}
+
+// encodeHeader starts a payload with tags 00 and 01
+func encodeHeader(sb *strings.Builder, dynamic bool) {
+	sb.WriteString(vietqr.BuildTLV(vietqr.TagPayloadFormat, vietqr.PayloadFormatEMV))
+	if dynamic {
+		sb.WriteString(vietqr.BuildTLV(vietqr.TagInitiationMethod, vietqr.InitiationDynamic))
+	} else {
+		sb.WriteString(vietqr.BuildTLV(vietqr.TagInitiationMethod, vietqr.InitiationStatic))
+	}
+}
+
+// checkText validates a free-text field the way EncodeStrict does
+func checkText(name, tag, value string, maxLen int) (string, error) {
+	normalized, err := vietqr.NormalizeText(value, vietqr.TextReject)
+	if err != nil {
+		return "", &vietqr.FieldError{Field: name, Tag: tag, Err: err}
+	}
+	if len(normalized) > maxLen {
+		return "", &vietqr.FieldError{Field: name, Tag: tag, Limit: maxLen, Err: vietqr.ErrFieldTooLong}
+	}
+	return normalized, nil
+}
+
+// decodeCommon fills the fields every EMVCo payload shares
+func decodeCommon(id, qrString string) (*Decoded, error) {
+	if len(qrString) < 8 {
+		return nil, vietqr.ErrInvalidQRString
+	}
+	tree, _ := vietqr.ParseTLV(qrString)
+
+	decoded := &Decoded{
+		Scheme:       id,
+		Country:      tree.Get(vietqr.TagCountry),
+		MerchantName: tree.Get(vietqr.TagMerchantName),
+		MerchantCity: tree.Get(vietqr.TagMerchantCity),
+		Message:      tree.Get(vietqr.TagAdditionalData, vietqr.SubTagPurpose),
+		BillNumber:   tree.Get(vietqr.TagAdditionalData, vietqr.SubTagBillNumber),
+		Dynamic:      tree.Get(vietqr.TagInitiationMethod) == vietqr.InitiationDynamic,
+		IsValid:      vietqr.ValidateCRC(qrString),
+		Tree:         tree,
+	}
+
+	decoded.Currency = tree.Get(vietqr.TagCurrency)
+	if c := GetCurrencyByNumeric(decoded.Currency); c != nil {
+		decoded.Currency = c.Code
+	}
+	if amount, err := strconv.ParseFloat(tree.Get(vietqr.TagAmount), 64); err == nil {
+		decoded.Amount = amount
+	}
+
+	return decoded, nil
+}
+
+// findTemplate returns the merchant account template (tags 26-51) whose
+// GUID matches guid, or nil
+func findTemplate(tree *vietqr.TLVTree, guid string) *vietqr.TLVNode {
+	for _, node := range tree.Nodes {
+		if node.Tag < "26" || node.Tag > "51" || !node.IsTemplate() {
+			continue
+		}
+		if strings.EqualFold(node.Get(vietqr.SubTagGUID), guid) {
+			return node
+		}
+	}
+	return nil
+}
+
+// mandatoryTags returns the tags every scheme requires plus the given extras
+func mandatoryTags(extra ...string) []string {
+	tags := []string{vietqr.TagPayloadFormat, vietqr.TagInitiationMethod, vietqr.TagCurrency, vietqr.TagCountry, vietqr.TagCRC}
+	return append(tags, extra...)
+}
+
+// encodeAdditionalData builds tag 62 with the bill number and purpose
+func encodeAdditionalData(p Payment) (string, error) {
+	bill, err := checkText("bill_number", "62.01", p.BillNumber, 25)
+	if err != nil {
+		return "", err
+	}
+	message, err := checkText("message", "62.08", p.Message, 25)
+	if err != nil {
+		return "", err
+	}
+	if bill == "" && message == "" {
+		return "", nil
+	}
+
+	var data string
+	if bill != "" {
+		data += vietqr.BuildTLV(vietqr.SubTagBillNumber, bill)
+	}
+	if message != "" {
+		data += vietqr.BuildTLV(vietqr.SubTagPurpose, message)
+	}
+	return vietqr.BuildTLV(vietqr.TagAdditionalData, data), nil
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,225 @@
+package scheme
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+func TestSchemeRoundTrip(t *testing.T) {
+	now = func() time.Time { return time.UnixMilli(1700000000000) }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		scheme  string
+		payment Payment
+		want    Decoded
+	}{
+		{VietQR, Payment{Bank: "970436", Account: "1234567890", Amount: "50000", Message: "Don 42"},
+			Decoded{Account: "1234567890", AccountType: "account", Bank: "970436", Amount: 50000, Currency: "VND", Country: "VN", MerchantCity: "Ha Noi", Message: "Don 42", Dynamic: true}},
+		{PromptPay, Payment{Account: "081-234-5678", Amount: "100.5"},
+			Decoded{Account: "0812345678", AccountType: PromptPayMobile, Amount: 100.5, Currency: "THB", Country: "TH", Dynamic: true}},
+		{PromptPay, Payment{Account: "1101700230708"},
+			Decoded{Account: "1101700230708", AccountType: PromptPayNationalID, Currency: "THB", Country: "TH"}},
+		{KHQR, Payment{Account: "coffee@aclb", Amount: "2.25", Currency: "USD", MerchantName: "Coffee Shop"},
+			Decoded{Account: "coffee@aclb", AccountType: KHQRIndividual, Amount: 2.25, Currency: "USD", Country: "KH", MerchantName: "Coffee Shop", MerchantCity: "Phnom Penh", Dynamic: true}},
+		{KHQR, Payment{Account: "shop@abaa", AccountType: KHQRMerchant, MerchantID: "M123", Bank: "ABA Bank", MerchantName: "Shop", Amount: "5000"},
+			Decoded{Account: "shop@abaa", AccountType: KHQRMerchant, Bank: "ABA Bank", Amount: 5000, Currency: "KHR", Country: "KH", MerchantName: "Shop", MerchantCity: "Phnom Penh", Dynamic: true}},
+		{PayNow, Payment{Account: "+65 9123 4567", Amount: "12.5", Expiry: time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC)},
+			Decoded{Account: "+6591234567", AccountType: PayNowMobile, Amount: 12.5, Currency: "SGD", Country: "SG", MerchantName: "NA", MerchantCity: "Singapore", Dynamic: true, Expiry: "2030-12-31"}},
+		{PayNow, Payment{Account: "201403121w", MerchantName: "ACME PTE LTD"},
+			Decoded{Account: "201403121W", AccountType: PayNowUEN, Currency: "SGD", Country: "SG", MerchantName: "ACME PTE LTD", MerchantCity: "Singapore", Editable: true}},
+		{DuitNow, Payment{Account: "M0012345", Bank: "890053", MerchantName: "Kedai Runcit", Amount: "8.9", BillNumber: "INV7"},
+			Decoded{Account: "M0012345", Bank: "890053", Amount: 8.9, Currency: "MYR", Country: "MY", MerchantName: "Kedai Runcit", MerchantCity: "Kuala Lumpur", BillNumber: "INV7", Dynamic: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scheme, func(t *testing.T) {
+			s, err := Get(tt.scheme)
+			if err != nil {
+				t.Fatal(err)
+			}
+			qr, err := s.Encode(tt.payment)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if errs := s.Validate(qr); errs != nil {
+				t.Fatalf("Validate(%s) = %v", qr, errs)
+			}
+
+			got, err := s.Decode(qr)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			tt.want.Scheme = tt.scheme
+			tt.want.IsValid = true
+			got.Tree = nil
+			if *got != tt.want {
+				t.Errorf("Decode() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemeErrors(t *testing.T) {
+	tests := []struct {
+		scheme  string
+		payment Payment
+		field   string
+		want    error
+	}{
+		{PromptPay, Payment{Account: "0812345678", Currency: "USD"}, "currency", ErrUnsupportedCurrency},
+		{PromptPay, Payment{Account: "0812345678", Amount: "1.005"}, "amount", vietqr.ErrInvalidAmount},
+		{PromptPay, Payment{Account: "1101700230709"}, "account_number", ErrInvalidProxyValue},
+		{KHQR, Payment{Account: "not-an-id", MerchantName: "Shop"}, "account_number", ErrInvalidBakongID},
+		{KHQR, Payment{Account: "shop@abaa"}, "merchant_name", vietqr.ErrFieldRequired},
+		{KHQR, Payment{Account: "shop@abaa", AccountType: KHQRMerchant, MerchantName: "Shop"}, "merchant_id", vietqr.ErrFieldRequired},
+		{VietQR, Payment{Bank: "970436", Account: "1234567890", Amount: "10.5"}, "amount", vietqr.ErrInvalidAmount},
+		{PayNow, Payment{Account: "61234567"}, "account_number", ErrInvalidMobile},
+		{PayNow, Payment{Account: "12345678Z9"}, "account_number", ErrInvalidUEN},
+		{PayNow, Payment{Account: "91234567", Expiry: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}, "expiry", ErrExpiryInPast},
+		{DuitNow, Payment{Account: "M001", Bank: "89", MerchantName: "Shop"}, "bank_code", ErrInvalidAcquirer},
+	}
+
+	for _, tt := range tests {
+		s, _ := Get(tt.scheme)
+		_, err := s.Encode(tt.payment)
+		var fieldErr *vietqr.FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != tt.field || !errors.Is(err, tt.want) {
+			t.Errorf("%s Encode(%+v) error = %v, want %v on %s", tt.scheme, tt.payment, err, tt.want, tt.field)
+		}
+	}
+
+	if _, err := Get("swish"); !errors.Is(err, ErrUnknownScheme) {
+		t.Errorf("Get() error = %v, want ErrUnknownScheme", err)
+	}
+}
+
+func TestPayNowExpirySingaporeDate(t *testing.T) {
+	defer func() { now = time.Now }()
+
+	day := func(d int) time.Time { return time.Date(2030, 6, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		now    time.Time
+		expiry time.Time
+		ok     bool
+	}{
+		{time.Date(2030, 6, 1, 15, 59, 0, 0, time.UTC), day(1), true}, // 23:59 on 1 June in Singapore
+		{time.Date(2030, 6, 1, 16, 0, 0, 0, time.UTC), day(1), false}, // Already 2 June in Singapore
+		{time.Date(2030, 6, 1, 16, 0, 0, 0, time.UTC), day(2), true},
+		{time.Date(2030, 5, 31, 20, 0, 0, 0, time.UTC), day(1), true},
+	}
+
+	for _, tt := range tests {
+		now = func() time.Time { return tt.now }
+		_, err := schemes[PayNow].Encode(Payment{Account: "91234567", Expiry: tt.expiry})
+		if (err == nil) != tt.ok {
+			t.Errorf("now %v, expiry %s: error = %v, want ok %v", tt.now, tt.expiry.Format("2006-01-02"), err, tt.ok)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	thb, vnd := GetCurrency("THB"), GetCurrency("VND")
+	tests := []struct {
+		amount   string
+		currency *Currency
+		want     string
+		ok       bool
+	}{
+		{"", thb, "", true},
+		{"0.00", thb, "", true},
+		{"100.5", thb, "100.50", true},
+		{"007.10", thb, "7.10", true},
+		{".5", thb, "", false},
+		{"0.1", thb, "0.10", true},
+		{"50000", vnd, "50000", true},
+		{"50000.00", vnd, "50000", true},
+		{"1.005", thb, "", false},
+		{"10.5", vnd, "", false},
+		{"abc", thb, "", false},
+		{"-1", thb, "", false},
+		{"1e3", thb, "", false},
+		{"12.", thb, "", false},
+		{"12345678901.5", thb, "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := formatAmount(tt.amount, tt.currency)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("formatAmount(%q, %s) = %q, %v; want %q, ok %v", tt.amount, tt.currency.Code, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+// qrisPayload builds a static QRIS payload around the given national template
+func qrisPayload(national string) string {
+	tlv := vietqr.BuildTLV
+	return vietqr.AppendCRC(tlv("00", "01") + tlv("01", "11") +
+		tlv("26", tlv("00", "ID.CO.BANKMANDIRI.WWW")+tlv("01", "936000080000012345")+tlv("02", "000195")+tlv("03", "UMI")) +
+		tlv("51", national) +
+		tlv("52", "5812") + tlv("53", "360") + tlv("58", "ID") +
+		tlv("59", "WARUNG MAKAN") + tlv("60", "JAKARTA") + tlv("61", "10110"))
+}
+
+func TestQRIS(t *testing.T) {
+	tlv := vietqr.BuildTLV
+	qr := qrisPayload(tlv("00", "ID.CO.QRIS.WWW") + tlv("02", "ID1020021181745") + tlv("03", "UMI"))
+
+	s, _ := Get(QRIS)
+	if errs := s.Validate(qr); errs != nil {
+		t.Fatalf("Validate() = %v", errs)
+	}
+	got, err := s.Decode(qr)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.Account != "ID1020021181745" || got.Bank != "ID.CO.BANKMANDIRI.WWW" || got.Currency != "IDR" || got.MerchantName != "WARUNG MAKAN" {
+		t.Errorf("Decode() = %+v", got)
+	}
+	info := got.QRIS
+	if info.Criteria != "UMI" || info.MCC != "5812" || info.PostalCode != "10110" ||
+		len(info.Acquirers) != 1 || info.Acquirers[0].MerchantPAN != "936000080000012345" || info.Acquirers[0].MerchantID != "000195" {
+		t.Errorf("Decode().QRIS = %+v", info)
+	}
+
+	if _, err := s.Encode(Payment{}); !errors.Is(err, ErrEncodeNotSupported) {
+		t.Errorf("Encode() error = %v, want ErrEncodeNotSupported", err)
+	}
+
+	bad := qrisPayload(tlv("00", "ID.CO.QRIS.WWW") + tlv("02", "ID12345") + tlv("03", "XYZ"))
+	errs := s.Validate(bad)
+	paths := map[string]bool{}
+	for _, e := range errs {
+		if e.Code == vietqr.CodeInvalidValue {
+			paths[e.Path] = true
+		}
+	}
+	if !paths["51.02"] || !paths["51.03"] {
+		t.Errorf("Validate() = %v, want invalid_value at 51.02 and 51.03", errs)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tlv := vietqr.BuildTLV
+	khqr, _ := schemes[KHQR].Encode(Payment{Account: "coffee@aclb", MerchantName: "Coffee Shop"})
+	paynow, _ := schemes[PayNow].Encode(Payment{Account: "91234567"})
+	promptpay, _ := schemes[PromptPay].Encode(Payment{Account: "0812345678"})
+	vietqrPayload, _ := schemes[VietQR].Encode(Payment{Bank: "970436", Account: "1234567890"})
+
+	tests := map[string]string{
+		vietqrPayload: VietQR,
+		promptpay:     PromptPay,
+		khqr:          KHQR,
+		paynow:        PayNow,
+		qrisPayload(tlv("00", "ID.CO.QRIS.WWW") + tlv("02", "ID1020021181745") + tlv("03", "UMI")): QRIS,
+		"not a payload": VietQR,
+	}
+	for qr, want := range tests {
+		if got := Detect(qr); got != want {
+			t.Errorf("Detect(%q) = %s, want %s", qr, got, want)
+		}
+	}
+}
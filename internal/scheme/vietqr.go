@@ -0,0 +1,87 @@
+package scheme
+
+import (
+	"strconv"
+
+	"github.com/maxqr-api/internal/vietqr"
+)
+
+// vietQRScheme adapts the vietqr package (NAPAS 247, Vietnam)
+type vietQRScheme struct{}
+
+func (vietQRScheme) ID() string           { return VietQR }
+func (vietQRScheme) Name() string         { return "VietQR (NAPAS 247)" }
+func (vietQRScheme) Country() string      { return vietqr.CountryVN }
+func (vietQRScheme) Currencies() []string { return []string{"VND"} }
+
+// Encode builds a VietQR transfer to Payment.Bank (BIN) and Payment.Account.
+// AccountType "card" encodes a NAPAS card transfer.
+func (s vietQRScheme) Encode(p Payment) (string, error) {
+	currency, err := resolveCurrency(s, p.Currency)
+	if err != nil {
+		return "", err
+	}
+	// VND has no minor unit, so the formatted amount is a whole number
+	value, err := formatAmount(p.Amount, currency)
+	if err != nil {
+		return "", err
+	}
+	var amount int64
+	if value != "" {
+		if amount, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return "", &vietqr.FieldError{Field: "amount", Tag: vietqr.TagAmount, Err: vietqr.ErrInvalidAmount}
+		}
+	}
+	method, err := vietqr.ParseTransferMethod(p.AccountType)
+	if err != nil {
+		return "", &vietqr.FieldError{Field: "account_type", Tag: "38.02", Err: err}
+	}
+
+	return vietqr.EncodeStrict(vietqr.TransferInfo{
+		BankBin:        p.Bank,
+		AccountNumber:  p.Account,
+		TransferMethod: method,
+		Amount:         amount,
+		Message:        p.Message,
+		BillNumber:     p.BillNumber,
+		MerchantName:   p.MerchantName,
+		MerchantCity:   p.MerchantCity,
+		IsDynamic:      amount > 0 && !p.Editable,
+		Editable:       p.Editable,
+	})
+}
+
+// Decode maps vietqr.Decode onto the scheme-neutral result
+func (vietQRScheme) Decode(qrString string) (*Decoded, error) {
+	info, err := vietqr.Decode(qrString)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := info.Currency
+	if c := GetCurrencyByNumeric(currency); c != nil {
+		currency = c.Code
+	}
+
+	return &Decoded{
+		Scheme:       VietQR,
+		Account:      info.AccountNumber,
+		AccountType:  vietqr.TransferMethodName(info.TransferMethod),
+		Bank:         info.BankBin,
+		Amount:       float64(info.Amount),
+		Currency:     currency,
+		Country:      info.Country,
+		MerchantName: info.MerchantName,
+		MerchantCity: info.MerchantCity,
+		Message:      info.Message,
+		BillNumber:   info.BillNumber,
+		Dynamic:      info.InitiationMethod == vietqr.InitiationDynamic,
+		IsValid:      info.IsValid,
+		Tree:         info.Tree,
+	}, nil
+}
+
+// Validate applies the NAPAS structure rules
+func (vietQRScheme) Validate(qrString string) vietqr.ValidationErrors {
+	return vietqr.Validate(qrString)
+}
@@ -0,0 +1,166 @@
+package vietqr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Account number rule violations
+var (
+	ErrAccountLength      = errors.New("account number has an invalid length")
+	ErrAccountCharset     = errors.New("account number contains invalid characters")
+	ErrInvalidPhoneNumber = errors.New("account must be a Vietnamese mobile number")
+)
+
+// AccountRule describes the account numbers a bank or e-wallet issues
+type AccountRule struct {
+	MinLength       int      // Shortest regular account number
+	MaxLength       int      // Longest regular account number
+	Alphanumeric    bool     // Letters allowed (digits only otherwise)
+	Phone           bool     // Accounts are mobile phone numbers (e-wallets)
+	VirtualPrefixes []string // Virtual-account prefixes exempt from the length range
+}
+
+// AccountRules maps a bank BIN to its account number rule.
+// Lengths cover both standard accounts and the "nice number" or phone-number
+// aliases banks sell; BINs without an entry only get the NAPAS limit of
+// 19 alphanumeric characters.
+var AccountRules = map[string]*AccountRule{
+	"970436": {MinLength: 9, MaxLength: 13},                                     // Vietcombank
+	"970415": {MinLength: 9, MaxLength: 12},                                     // VietinBank
+	"970418": {MinLength: 8, MaxLength: 14, VirtualPrefixes: []string{"96247"}}, // BIDV
+	"970405": {MinLength: 10, MaxLength: 13},                                    // Agribank
+	"970407": {MinLength: 6, MaxLength: 14},                                     // Techcombank
+	"970422": {MinLength: 6, MaxLength: 15, VirtualPrefixes: []string{"VQR"}},   // MBBank
+	"970416": {MinLength: 6, MaxLength: 14},                                     // ACB
+	"970432": {MinLength: 6, MaxLength: 15},                                     // VPBank
+	"970423": {MinLength: 6, MaxLength: 15},                                     // TPBank
+	"970403": {MinLength: 9, MaxLength: 13},                                     // Sacombank
+	"970437": {MinLength: 6, MaxLength: 15},                                     // HDBank
+	"970441": {MinLength: 6, MaxLength: 16},                                     // VIB
+	"970443": {MinLength: 6, MaxLength: 15},                                     // SHB
+	"970448": {MinLength: 6, MaxLength: 15},                                     // OCB
+	"970426": {MinLength: 6, MaxLength: 14},                                     // MSB
+	"970440": {MinLength: 6, MaxLength: 15},                                     // SeABank
+	"970449": {MinLength: 6, MaxLength: 15},                                     // LPBank
+	"963388": {MinLength: 6, MaxLength: 15},                                     // Timo
+	"546034": {MinLength: 6, MaxLength: 15},                                     // CAKE
+	"546035": {MinLength: 6, MaxLength: 15},                                     // Ubank
+
+	"971025": {Phone: true}, // MoMo
+	"971005": {Phone: true}, // ViettelMoney
+	"971011": {Phone: true}, // VNPTMoney
+}
+
+// AccountError explains why an account number does not match its bank's rule
+type AccountError struct {
+	Bank   string       // Bank short name, or the BIN when unknown
+	Rule   *AccountRule // Rule that was violated
+	Length int          // Length of the rejected account number
+	Err    error        // ErrAccountLength, ErrAccountCharset or ErrInvalidPhoneNumber
+}
+
+// Error implements the error interface
+func (e *AccountError) Error() string {
+	switch e.Err {
+	case ErrInvalidPhoneNumber:
+		return e.Bank + " accounts must be a 10-digit Vietnamese mobile number (e.g., 0912345678)"
+	case ErrAccountCharset:
+		return e.Bank + " account numbers must only contain digits"
+	case ErrAccountLength:
+		unit := " digits"
+		if e.Rule.Alphanumeric {
+			unit = " characters"
+		}
+		want := strconv.Itoa(e.Rule.MinLength) + " to " + strconv.Itoa(e.Rule.MaxLength)
+		if e.Rule.MinLength == e.Rule.MaxLength {
+			want = "exactly " + strconv.Itoa(e.Rule.MinLength)
+		}
+		return e.Bank + " account numbers must have " + want + unit + ", got " + strconv.Itoa(e.Length)
+	}
+	return e.Bank + ": " + e.Err.Error()
+}
+
+// Unwrap returns the rule violation
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// GetAccountRule returns the account number rule for a BIN, or nil
+func GetAccountRule(bin string) *AccountRule {
+	return AccountRules[bin]
+}
+
+// NormalizeAccountNumber removes the separators people type or paste into
+// account numbers (spaces, dots, hyphens). For e-wallets the +84/84 country
+// prefix is replaced with the domestic leading 0.
+func NormalizeAccountNumber(bin, accountNumber string) string {
+	accountNumber = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '.' || r == '-' || r == '\t' {
+			return -1
+		}
+		return r
+	}, accountNumber)
+
+	if rule := GetAccountRule(bin); rule != nil && rule.Phone {
+		accountNumber = strings.TrimPrefix(accountNumber, "+")
+		if len(accountNumber) == 11 && strings.HasPrefix(accountNumber, "84") {
+			accountNumber = "0" + accountNumber[2:]
+		}
+	}
+
+	return accountNumber
+}
+
+// ValidateAccountNumber checks an account number against the rule of the
+// bank identified by bin. It returns an *AccountError, or nil when the
+// number matches or the bank has no rule.
+func ValidateAccountNumber(bin, accountNumber string) error {
+	rule := GetAccountRule(bin)
+	if rule == nil {
+		return nil
+	}
+
+	bankName := bin
+	if bank := GetBankByBIN(bin); bank != nil {
+		bankName = bank.ShortName
+	}
+
+	if rule.Phone {
+		if !IsMobileNumber(accountNumber) {
+			return &AccountError{Bank: bankName, Rule: rule, Length: len(accountNumber), Err: ErrInvalidPhoneNumber}
+		}
+		return nil
+	}
+
+	// Virtual accounts follow the collection service's format, which may
+	// contain letters and exceed the regular length range
+	for _, prefix := range rule.VirtualPrefixes {
+		if strings.HasPrefix(accountNumber, prefix) {
+			return nil
+		}
+	}
+
+	if !rule.Alphanumeric && !isDigits(accountNumber) {
+		return &AccountError{Bank: bankName, Rule: rule, Length: len(accountNumber), Err: ErrAccountCharset}
+	}
+	if len(accountNumber) < rule.MinLength || len(accountNumber) > rule.MaxLength {
+		return &AccountError{Bank: bankName, Rule: rule, Length: len(accountNumber), Err: ErrAccountLength}
+	}
+
+	return nil
+}
+
+// IsMobileNumber reports whether s is a 10-digit Vietnamese mobile number
+// (03x, 05x, 07x, 08x or 09x)
+func IsMobileNumber(s string) bool {
+	if len(s) != 10 || s[0] != '0' || !isDigits(s) {
+		return false
+	}
+	switch s[1] {
+	case '3', '5', '7', '8', '9':
+		return true
+	}
+	return false
+}
@@ -1,5 +1,7 @@
 package vietqr
 
+import "fmt"
+
 // Bank represents a Vietnamese bank with its BIN code and information
 type Bank struct {
 	BIN       string `json:"bin"`
@@ -7,6 +9,7 @@ type Bank struct {
 	ShortName string `json:"short_name"` // Short name (e.g., "Vietcombank")
 	Name      string `json:"name"`       // Full Vietnamese name
 	Logo      string `json:"logo"`       // Logo URL
+	Monogram  string `json:"monogram"`   // Placeholder monogram URL (not the official logo)
 	SwiftCode string `json:"swift_code"` // SWIFT/BIC code
 }
 
@@ -86,6 +89,9 @@ func init() {
 	for _, bank := range Banks {
 		BanksByBIN[bank.BIN] = bank
 		BanksByShortName[bank.ShortName] = bank
+		if hasMonogram(bank.BIN) {
+			bank.Monogram = fmt.Sprintf(MonogramPath, bank.BIN)
+		}
 	}
 }
 
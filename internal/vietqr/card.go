@@ -0,0 +1,123 @@
+package vietqr
+
+import (
+	"errors"
+	"strings"
+)
+
+// Card number errors
+var (
+	ErrInvalidCardNumber = errors.New("card number must be 16-19 digits")
+	ErrCardChecksum      = errors.New("card number failed Luhn check")
+	ErrInvalidMethod     = errors.New("transfer method must be account or card")
+)
+
+// ParseTransferMethod maps a user-facing method name ("account", "card") or
+// a raw service code to the NAPAS service code. Empty input means account.
+func ParseTransferMethod(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "account", "acc", strings.ToLower(TransferToAccount):
+		return TransferToAccount, nil
+	case "card", strings.ToLower(TransferToCard):
+		return TransferToCard, nil
+	}
+	return "", ErrInvalidMethod
+}
+
+// TransferMethodName returns the user-facing name of a service code
+func TransferMethodName(serviceCode string) string {
+	switch serviceCode {
+	case TransferToCard:
+		return "card"
+	case TransferToAccount, "":
+		return "account"
+	}
+	return serviceCode
+}
+
+// NormalizeCardNumber strips the spaces and dashes users type between digit groups
+func NormalizeCardNumber(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(s)
+}
+
+// ValidateCardNumber checks length, charset and the Luhn checksum of a
+// NAPAS card number (already normalized)
+func ValidateCardNumber(cardNumber string) error {
+	if len(cardNumber) < 16 || len(cardNumber) > 19 {
+		return ErrInvalidCardNumber
+	}
+	for i := 0; i < len(cardNumber); i++ {
+		if cardNumber[i] < '0' || cardNumber[i] > '9' {
+			return ErrInvalidCardNumber
+		}
+	}
+	if !LuhnValid(cardNumber) {
+		return ErrCardChecksum
+	}
+	return nil
+}
+
+// LuhnValid reports whether a digit string passes the Luhn (mod 10) check
+func LuhnValid(digits string) bool {
+	if digits == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		c := digits[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// MaskCardNumber keeps the first 6 (BIN) and last 4 digits of a card number
+// and masks the rest, e.g. "970436******1234"
+func MaskCardNumber(cardNumber string) string {
+	if len(cardNumber) <= 10 {
+		return strings.Repeat("*", len(cardNumber))
+	}
+	return cardNumber[:6] + strings.Repeat("*", len(cardNumber)-10) + cardNumber[len(cardNumber)-4:]
+}
+
+// MaskCard masks the card number of a card transfer (QRIBFTTC) wherever
+// info exposes it: AccountNumber, sub-tag 38.01.01 of the TLV tree, the raw
+// values of the templates enclosing it and the tag 38 merchant account. The
+// masked number keeps its length, so node offsets stay valid. It does
+// nothing for account transfers.
+func (info *DecodedInfo) MaskCard() {
+	if info.TransferMethod != TransferToCard {
+		return
+	}
+	info.AccountNumber = MaskCardNumber(info.AccountNumber)
+
+	account := info.Tree.Find(TagMerchantAccount)
+	if account == nil {
+		return
+	}
+	if beneficiary := account.Find(SubTagBeneficiaryOrg); beneficiary != nil {
+		if card := beneficiary.Find(SubTagConsumerID); card != nil {
+			card.Value = MaskCardNumber(card.Value)
+			beneficiary.Value = serializeNodes(beneficiary.Children)
+			account.Value = serializeNodes(account.Children)
+		}
+	}
+	for i := range info.MerchantAccounts {
+		if info.MerchantAccounts[i].Tag == TagMerchantAccount {
+			info.MerchantAccounts[i] = classifyMerchantAccount(account)
+		}
+	}
+}
@@ -42,6 +42,11 @@ func CRC16StringWithTag(data string) string {
 	return sprintf("%04X", crc)
 }
 
+// AppendCRC terminates a payload with tag 63 and its CRC
+func AppendCRC(data string) string {
+	return data + "6304" + CRC16StringWithTag(data)
+}
+
 // sprintf is a minimal implementation to avoid fmt package overhead
 func sprintf(format string, v uint16) string {
 	const hexDigits = "0123456789ABCDEF"
@@ -17,172 +17,192 @@ var (
 
 // DecodedInfo contains parsed VietQR information
 type DecodedInfo struct {
-	PayloadFormat    string // Should be "01"
-	InitiationMethod string // "11" (static) or "12" (dynamic)
-	BankBin          string // 6-digit bank BIN
-	AccountNumber    string // Recipient's account number
-	Amount           int64  // Transfer amount (0 if not specified)
-	Currency         string // Currency code (usually "704" for VND)
-	Country          string // Country code (usually "VN")
-	MerchantName     string // Optional merchant name
-	MerchantCity     string // Optional city
-	Message          string // Transfer description
-	CRC              string // CRC checksum
-	IsValid          bool   // Whether CRC validation passed
+	PayloadFormat      string            // Should be "01"
+	InitiationMethod   string            // "11" (static) or "12" (dynamic)
+	BankBin            string            // 6-digit bank BIN
+	AccountNumber      string            // Recipient's account number (or card number for QRIBFTTC)
+	TransferMethod     string            // Service code from tag 38.02 (QRIBFTTA or QRIBFTTC)
+	Amount             int64             // Transfer amount (0 if not specified)
+	Currency           string            // Currency code (usually "704" for VND)
+	Country            string            // Country code (usually "VN")
+	MerchantName       string            // Optional merchant name
+	MerchantCity       string            // Optional city
+	Message            string            // Transfer description
+	BillNumber         string            // 62.01
+	MobileNumber       string            // 62.02
+	StoreLabel         string            // 62.03
+	LoyaltyNumber      string            // 62.04
+	ReferenceLabel     string            // 62.05
+	CustomerLabel      string            // 62.06
+	TerminalLabel      string            // 62.07
+	Merchant           *MerchantPayment  // Tags 52, 55-57, 61 (nil for a plain transfer QR)
+	LanguagePreference string            // 64.00 - Alternate language code
+	MerchantNameAlt    string            // 64.01 - Merchant name with diacritics
+	MerchantCityAlt    string            // 64.02 - Merchant city with diacritics
+	Network            string            // Payment network ID ("napas", "vnpay"...; "unsupported" if none is known)
+	NetworkName        string            // Display name of Network
+	MerchantAccounts   []MerchantAccount // Every merchant account field (tags 02-51), classified by network
+	CRC                string            // CRC checksum
+	IsValid            bool              // Whether CRC validation passed and the payload parsed cleanly
+	Tree               *TLVTree          // Full TLV tree, including tags not mapped above
+	StructureError     *ValidationError  // First malformed field; Tree and the fields above then cover only the prefix before it
 }
 
 // Decoder parses VietQR strings
 type Decoder struct {
-	data   string
-	pos    int
-	length int
+	data string
 }
 
 // NewDecoder creates a new VietQR decoder
 func NewDecoder(qrString string) *Decoder {
 	return &Decoder{
-		data:   qrString,
-		pos:    0,
-		length: len(qrString),
+		data: qrString,
 	}
 }
 
 // Decode parses a VietQR string and returns decoded information
 func (d *Decoder) Decode() (*DecodedInfo, error) {
-	if d.length < 8 {
+	if len(d.data) < 8 {
 		return nil, ErrInvalidQRString
 	}
 
-	info := &DecodedInfo{}
+	// A malformed field stops parsing but the valid prefix is still decoded,
+	// so callers can show what was readable; the fault is reported in
+	// StructureError and the payload is never marked valid
+	tree, err := ParseTLV(d.data)
 
-	// Validate CRC first
-	if d.length >= 8 {
-		crcPos := d.length - 4
-		if d.data[crcPos-4:crcPos] == "6304" {
-			expectedCRC := CRC16String(d.data[:crcPos])
-			actualCRC := d.data[crcPos:]
-			info.CRC = actualCRC
-			info.IsValid = strings.EqualFold(expectedCRC, actualCRC)
-		}
+	info := &DecodedInfo{Tree: tree}
+	if err != nil {
+		info.StructureError = err.(*ValidationError)
 	}
 
-	// Parse all TLV fields
-	for d.pos < d.length {
-		tag, value, err := d.readTLV()
-		if err != nil {
-			break
-		}
+	// Validate CRC first
+	crcPos := len(d.data) - 4
+	if d.data[crcPos-4:crcPos] == "6304" {
+		expectedCRC := CRC16String(d.data[:crcPos])
+		actualCRC := d.data[crcPos:]
+		info.CRC = actualCRC
+		info.IsValid = strings.EqualFold(expectedCRC, actualCRC) && info.StructureError == nil
+	}
 
-		switch tag {
+	for _, node := range tree.Nodes {
+		switch node.Tag {
 		case TagPayloadFormat:
-			info.PayloadFormat = value
+			info.PayloadFormat = node.Value
 		case TagInitiationMethod:
-			info.InitiationMethod = value
+			info.InitiationMethod = node.Value
 		case TagMerchantAccount:
-			d.parseMerchantAccount(value, info)
+			d.parseMerchantAccount(node, info)
+		case TagMerchantCategory:
+			d.merchant(info).CategoryCode = node.Value
+		case TagTipIndicator:
+			d.merchant(info).TipIndicator = node.Value
+		case TagConvenienceFixed:
+			if fee, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
+				d.merchant(info).ConvenienceFee = fee
+			}
+		case TagConveniencePercent:
+			if pct, err := strconv.ParseFloat(node.Value, 64); err == nil {
+				d.merchant(info).ConveniencePercent = pct
+			}
+		case TagPostalCode:
+			d.merchant(info).PostalCode = node.Value
 		case TagCurrency:
-			info.Currency = value
+			info.Currency = node.Value
 		case TagAmount:
-			if amt, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if amt, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
 				info.Amount = amt
 			}
 		case TagCountry:
-			info.Country = value
+			info.Country = node.Value
 		case TagMerchantName:
-			info.MerchantName = value
+			info.MerchantName = node.Value
 		case TagMerchantCity:
-			info.MerchantCity = value
+			info.MerchantCity = node.Value
 		case TagAdditionalData:
-			d.parseAdditionalData(value, info)
+			d.parseAdditionalData(node, info)
+		case TagLanguageTemplate:
+			d.parseLanguageTemplate(node, info)
 		case TagCRC:
-			info.CRC = value
+			info.CRC = node.Value
 		}
-	}
-
-	return info, nil
-}
-
-// readTLV reads a single Tag-Length-Value triplet
-func (d *Decoder) readTLV() (tag, value string, err error) {
-	if d.pos+4 > d.length {
-		return "", "", ErrInvalidQRString
-	}
-
-	tag = d.data[d.pos : d.pos+2]
-	d.pos += 2
-
-	lengthStr := d.data[d.pos : d.pos+2]
-	d.pos += 2
-
-	length, err := strconv.Atoi(lengthStr)
-	if err != nil {
-		return "", "", ErrInvalidLength
-	}
 
-	if d.pos+length > d.length {
-		return "", "", ErrInvalidLength
+		if isMerchantAccountTag(node.Tag) {
+			info.MerchantAccounts = append(info.MerchantAccounts, classifyMerchantAccount(node))
+		}
 	}
 
-	value = d.data[d.pos : d.pos+length]
-	d.pos += length
+	d.classifyNetwork(info)
 
-	return tag, value, nil
+	return info, nil
 }
 
-// parseMerchantAccount parses nested TLV in tag 38
-func (d *Decoder) parseMerchantAccount(data string, info *DecodedInfo) {
-	decoder := NewDecoder(data)
-	decoder.length = len(data)
-
-	for decoder.pos < decoder.length {
-		tag, value, err := decoder.readTLV()
-		if err != nil {
+// classifyNetwork picks the payment network the QR belongs to: NAPAS when
+// present, otherwise the first known network in tag order
+func (d *Decoder) classifyNetwork(info *DecodedInfo) {
+	var first *MerchantAccount
+	for i := range info.MerchantAccounts {
+		account := &info.MerchantAccounts[i]
+		if account.Network == NetworkNAPAS {
+			first = account
 			break
 		}
-
-		switch tag {
-		case "01": // Beneficiary Organization
-			d.parseBeneficiaryOrg(value, info)
+		if first == nil && account.Network != NetworkUnsupported {
+			first = account
 		}
 	}
-}
 
-// parseBeneficiaryOrg parses the beneficiary organization structure
-func (d *Decoder) parseBeneficiaryOrg(data string, info *DecodedInfo) {
-	decoder := NewDecoder(data)
-	decoder.length = len(data)
-
-	for decoder.pos < decoder.length {
-		tag, value, err := decoder.readTLV()
-		if err != nil {
-			break
-		}
+	switch {
+	case first != nil:
+		info.Network = first.Network
+		info.NetworkName = first.NetworkName
+	case len(info.MerchantAccounts) > 0:
+		info.Network = NetworkUnsupported
+	}
+}
 
-		switch tag {
-		case "00": // Bank BIN
-			info.BankBin = value
-		case "01": // Account Number
-			info.AccountNumber = value
-		}
+// merchant returns info.Merchant, allocating it on first use
+func (d *Decoder) merchant(info *DecodedInfo) *MerchantPayment {
+	if info.Merchant == nil {
+		info.Merchant = &MerchantPayment{}
 	}
+	return info.Merchant
 }
 
-// parseAdditionalData parses nested TLV in tag 62
-func (d *Decoder) parseAdditionalData(data string, info *DecodedInfo) {
-	decoder := NewDecoder(data)
-	decoder.length = len(data)
+// parseMerchantAccount extracts beneficiary info from tag 38
+func (d *Decoder) parseMerchantAccount(node *TLVNode, info *DecodedInfo) {
+	info.BankBin = node.Get(SubTagBeneficiaryOrg, SubTagAcquirerID)
+	info.AccountNumber = node.Get(SubTagBeneficiaryOrg, SubTagConsumerID)
+	info.TransferMethod = node.Get(SubTagServiceCode)
+}
 
-	for decoder.pos < decoder.length {
-		tag, value, err := decoder.readTLV()
-		if err != nil {
-			break
-		}
+// parseAdditionalData extracts known sub-tags from tag 62
+func (d *Decoder) parseAdditionalData(node *TLVNode, info *DecodedInfo) {
+	info.BillNumber = node.Get(SubTagBillNumber)
+	info.MobileNumber = node.Get(SubTagMobileNumber)
+	info.StoreLabel = node.Get(SubTagStoreLabel)
+	info.LoyaltyNumber = node.Get(SubTagLoyaltyNumber)
+	info.ReferenceLabel = node.Get(SubTagReferenceLabel)
+	info.CustomerLabel = node.Get(SubTagCustomerLabel)
+	info.TerminalLabel = node.Get(SubTagTerminalLabel)
+	info.Message = node.Get(SubTagPurpose)
+}
 
-		switch tag {
-		case SubTagPurpose: // Purpose of Transaction (Message)
-			info.Message = value
-		}
+// DecodeStrict validates the payload before decoding it. Unlike Decode it
+// fails on any structural problem, returning ValidationErrors that list
+// each issue with its tag path and byte offset.
+func (d *Decoder) DecodeStrict() (*DecodedInfo, error) {
+	if errs := Validate(d.data); errs != nil {
+		return nil, errs
 	}
+	return d.Decode()
+}
+
+// parseLanguageTemplate extracts the alternate-language values from tag 64
+func (d *Decoder) parseLanguageTemplate(node *TLVNode, info *DecodedInfo) {
+	info.LanguagePreference = node.Get(SubTagLanguagePreference)
+	info.MerchantNameAlt = node.Get(SubTagMerchantNameAlt)
+	info.MerchantCityAlt = node.Get(SubTagMerchantCityAlt)
 }
 
 // Decode is a package-level convenience function
@@ -190,6 +210,11 @@ func Decode(qrString string) (*DecodedInfo, error) {
 	return NewDecoder(qrString).Decode()
 }
 
+// DecodeStrict is a package-level convenience function for strict decoding
+func DecodeStrict(qrString string) (*DecodedInfo, error) {
+	return NewDecoder(qrString).DecodeStrict()
+}
+
 // ValidateCRC checks if the QR string has a valid CRC
 func ValidateCRC(qrString string) bool {
 	if len(qrString) < 8 {
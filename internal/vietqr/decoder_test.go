@@ -0,0 +1,229 @@
+package vietqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeStrictValid(t *testing.T) {
+	qr := Encode(TransferInfo{
+		BankBin:       "970436",
+		AccountNumber: "1234567890",
+		Amount:        100000,
+		Message:       "Thanh toan",
+	})
+
+	if errs := Validate(qr); errs != nil {
+		t.Fatalf("Validate() = %v, want no errors", errs)
+	}
+	if _, err := DecodeStrict(qr); err != nil {
+		t.Fatalf("DecodeStrict() error = %v", err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	merchantAccount := tlv("00", NAPAS_GUID) + tlv("01", tlv("00", "970436")+tlv("01", "1234567890")) + tlv("02", TransferToAccount)
+	head := tlv("00", "01") + tlv("01", "11") + tlv("38", merchantAccount)
+	withCRC := func(body string) string {
+		return body + "6304" + CRC16StringWithTag(body)
+	}
+
+	tests := []struct {
+		name   string
+		qr     string
+		code   ErrorCode
+		path   string
+		offset int
+	}{
+		{
+			name:   "missing currency",
+			qr:     withCRC(head + tlv("58", "VN")),
+			code:   CodeMissingTag,
+			path:   "53",
+			offset: -1,
+		},
+		{
+			name: "missing account number",
+			qr: withCRC(tlv("00", "01") + tlv("01", "11") +
+				tlv("38", tlv("00", NAPAS_GUID)+tlv("01", tlv("00", "970436"))) + tlv("53", "704") + tlv("58", "VN")),
+			code:   CodeMissingTag,
+			path:   "38.01.01",
+			offset: -1,
+		},
+		{
+			name:   "duplicate amount",
+			qr:     withCRC(head + tlv("53", "704") + tlv("54", "1000") + tlv("54", "2000") + tlv("58", "VN")),
+			code:   CodeDuplicateTag,
+			path:   "54",
+			offset: len(head) + 15,
+		},
+		{
+			name:   "non-numeric length",
+			qr:     withCRC(head + "53X3704" + tlv("58", "VN")),
+			code:   CodeInvalidLength,
+			path:   "53",
+			offset: len(head) + 2,
+		},
+		{
+			name:   "length overrun",
+			qr:     head + "5399704",
+			code:   CodeLengthOverrun,
+			path:   "53",
+			offset: len(head) + 2,
+		},
+		{
+			name:   "crc not last",
+			qr:     withCRC(head+tlv("53", "704")+tlv("58", "VN")) + tlv("59", "SHOP"),
+			code:   CodeCRCNotLast,
+			path:   "63",
+			offset: len(head) + 13,
+		},
+		{
+			name:   "crc mismatch",
+			qr:     head + tlv("53", "704") + tlv("58", "VN") + "6304FFFF",
+			code:   CodeCRCMismatch,
+			path:   "63",
+			offset: len(head) + 17,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate(tt.qr)
+
+			var found *ValidationError
+			for _, e := range errs {
+				if e.Code == tt.code {
+					found = e
+					break
+				}
+			}
+			if found == nil {
+				t.Fatalf("Validate() = %v, want a %s error", errs, tt.code)
+			}
+			if found.Path != tt.path {
+				t.Errorf("path = %q, want %q", found.Path, tt.path)
+			}
+			if tt.offset >= 0 && found.Offset != tt.offset {
+				t.Errorf("offset = %d, want %d", found.Offset, tt.offset)
+			}
+
+			if _, err := DecodeStrict(tt.qr); err == nil {
+				t.Error("DecodeStrict() should fail")
+			}
+			if _, err := Decode(tt.qr); err != nil {
+				t.Errorf("lenient Decode() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestValidationErrorUnwrap(t *testing.T) {
+	_, err := ParseTLV("000201015011")
+	if !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("ParseTLV() error = %v, want ErrInvalidLength", err)
+	}
+}
+
+func TestDecodeNetworks(t *testing.T) {
+	withCRC := func(body string) string {
+		return body + "6304" + CRC16StringWithTag(body)
+	}
+	head := tlv("00", "01") + tlv("01", "11")
+	tail := tlv("53", "704") + tlv("58", "VN")
+
+	tests := []struct {
+		name    string
+		qr      string
+		network string
+		field   string
+		value   string
+	}{
+		{"napas", Encode(TransferInfo{BankBin: "970436", AccountNumber: "1234567890"}), NetworkNAPAS, "", ""},
+		{"vnpay", withCRC(head + tlv("26", tlv("00", "A000000775")+tlv("01", "0105314388")) + tail), "vnpay", "merchant_id", "0105314388"},
+		{"card scheme", withCRC(head + tlv("02", "4111111111111111") + tail), "visa", "merchant_pan", "4111111111111111"},
+		{"unknown guid", withCRC(head + tlv("27", tlv("00", "XX.UNKNOWN")+tlv("05", "M42")) + tail), NetworkUnsupported, "sub_tag_05", "M42"},
+		{"no merchant account", withCRC(head + tail), "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Decode(tt.qr)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if info.Network != tt.network {
+				t.Errorf("Network = %q, want %q", info.Network, tt.network)
+			}
+			if tt.field != "" && info.MerchantAccounts[0].Fields[tt.field] != tt.value {
+				t.Errorf("Fields[%s] = %q, want %q", tt.field, info.MerchantAccounts[0].Fields[tt.field], tt.value)
+			}
+		})
+	}
+}
+
+func TestMaskCard(t *testing.T) {
+	const card = "9704361234567890"
+	qr := Encode(TransferInfo{BankBin: "970436", AccountNumber: card, TransferMethod: TransferToCard})
+
+	info, err := Decode(qr)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	info.MaskCard()
+
+	masked := MaskCardNumber(card)
+	if info.AccountNumber != masked {
+		t.Errorf("AccountNumber = %q, want %q", info.AccountNumber, masked)
+	}
+	for _, path := range [][]string{{"38"}, {"38", "01"}, {"38", "01", "01"}} {
+		if value := info.Tree.Get(path...); strings.Contains(value, card) {
+			t.Errorf("tree node %v still holds the card number: %q", path, value)
+		}
+	}
+	if got := info.Tree.Get("38", "01", "01"); got != masked {
+		t.Errorf("tree 38.01.01 = %q, want %q", got, masked)
+	}
+	if len(info.Tree.String()) != len(qr) {
+		t.Error("MaskCard() changed the payload length")
+	}
+	for _, account := range info.MerchantAccounts {
+		for name, value := range account.Fields {
+			if strings.Contains(value, card) {
+				t.Errorf("merchant account field %s still holds the card number", name)
+			}
+		}
+	}
+}
+
+func TestDecodeStructureError(t *testing.T) {
+	// Tag 59 declares 20 bytes but only 5 follow; the CRC still covers
+	// the payload, so only the structure check can flag it
+	body := tlv("00", "01") + tlv("01", "11") +
+		tlv("38", tlv("00", NAPAS_GUID)+tlv("01", tlv("00", "970436")+tlv("01", "1234567890"))+tlv("02", TransferToAccount)) +
+		"5920ABCDE"
+	qr := body + "6304" + CRC16StringWithTag(body)
+
+	info, err := Decode(qr)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if info.StructureError == nil || info.StructureError.Code != CodeLengthOverrun || info.StructureError.Path != "59" {
+		t.Errorf("Decode() StructureError = %v, want length_overrun at tag 59", info.StructureError)
+	}
+	if info.IsValid {
+		t.Error("Decode() marked a malformed payload valid")
+	}
+	if info.BankBin != "970436" || info.AccountNumber != "1234567890" {
+		t.Errorf("Decode() lost the valid prefix: %+v", info)
+	}
+
+	clean, err := Decode(Encode(TransferInfo{BankBin: "970436", AccountNumber: "1234567890"}))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if clean.StructureError != nil || !clean.IsValid {
+		t.Errorf("Decode() of a clean payload: StructureError = %v, IsValid = %v", clean.StructureError, clean.IsValid)
+	}
+}
@@ -3,6 +3,7 @@ package vietqr
 import (
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // EMVCo Tag IDs
@@ -14,12 +15,15 @@ const (
 	TagCurrency           = "53" // Transaction Currency
 	TagAmount             = "54" // Transaction Amount
 	TagTipIndicator       = "55" // Tip or Convenience Indicator
+	TagConvenienceFixed   = "56" // Value of Convenience Fee Fixed
+	TagConveniencePercent = "57" // Value of Convenience Fee Percentage
 	TagCountry            = "58" // Country Code
 	TagMerchantName       = "59" // Merchant Name
 	TagMerchantCity       = "60" // Merchant City
 	TagPostalCode         = "61" // Postal Code
 	TagAdditionalData     = "62" // Additional Data Field Template
 	TagCRC                = "63" // CRC
+	TagLanguageTemplate   = "64" // Merchant Information - Language Template
 )
 
 // Sub-tags within Tag 38 (Merchant Account Information)
@@ -30,6 +34,14 @@ const (
 	SubTagTransferMethod = "03" // Transfer Method (QRIBFTTA/QRIBFTTC - optional, defaults to QRIBFTTA)
 )
 
+// NAPAS layout of Tag 38: 00 GUID, 01 Beneficiary Organization (template), 02 Service Code
+const (
+	SubTagBeneficiaryOrg = "01" // Beneficiary Organization (template)
+	SubTagServiceCode    = "02" // Service Code (QRIBFTTA/QRIBFTTC)
+	SubTagAcquirerID     = "00" // Beneficiary Org: Acquirer ID (Bank BIN)
+	SubTagConsumerID     = "01" // Beneficiary Org: Consumer ID (account or card number)
+)
+
 // Sub-tags within Tag 62 (Additional Data)
 const (
 	SubTagBillNumber     = "01" // Bill Number
@@ -42,6 +54,13 @@ const (
 	SubTagPurpose        = "08" // Purpose of Transaction (Description/Message)
 )
 
+// Sub-tags within Tag 64 (Merchant Information - Language Template)
+const (
+	SubTagLanguagePreference = "00" // ISO 639-1 language code (e.g., "vi")
+	SubTagMerchantNameAlt    = "01" // Merchant Name in the alternate language (UTF-8)
+	SubTagMerchantCityAlt    = "02" // Merchant City in the alternate language (UTF-8)
+)
+
 // Constants
 const (
 	PayloadFormatEMV    = "01"          // EMV QR Code version
@@ -52,23 +71,50 @@ const (
 	NAPAS_GUID          = "A000000727"  // NAPAS provider identifier
 	TransferToAccount   = "QRIBFTTA"    // Transfer to Account
 	TransferToCard      = "QRIBFTTC"    // Transfer to Card
+	LanguageVietnamese  = "vi"          // ISO 639-1 code for tag 64
+	MaxValueLength      = 99            // Longest value a 2-digit length can describe
 )
 
 // TransferInfo contains all information needed to generate a VietQR code
 type TransferInfo struct {
-	BankBin       string // 6-digit bank BIN code (e.g., "970436" for Vietcombank)
-	AccountNumber string // Recipient's bank account number
-	Amount        int64  // Transfer amount in VND (0 for static QR)
-	Message       string // Transfer description/message (max 50 chars)
-	MerchantName  string // Optional: Merchant/recipient name (max 25 chars)
-	MerchantCity  string // Optional: City (default: "Ha Noi")
-	IsDynamic     bool   // true for single-use QR, false for reusable
-	Editable      bool   // true to allow user to edit amount/message when scanning
+	BankBin        string // 6-digit bank BIN code (e.g., "970436" for Vietcombank)
+	AccountNumber  string // Recipient's bank account number (or card number for TransferToCard)
+	Amount         int64  // Transfer amount in VND (0 for static QR)
+	Message        string // Transfer description/message (max 50 chars)
+	MerchantName   string // Optional: Merchant/recipient name (max 25 chars)
+	MerchantCity   string // Optional: City (default: "Ha Noi")
+	IsDynamic      bool   // true for single-use QR, false for reusable
+	Editable       bool   // true to allow user to edit amount/message when scanning
+	TransferMethod string // Service code: TransferToAccount (default) or TransferToCard
+
+	// Additional Data Field Template (tag 62), max 25 chars each
+	BillNumber     string // 62.01 - Invoice/bill number
+	MobileNumber   string // 62.02 - Mobile number
+	StoreLabel     string // 62.03 - Store identifier
+	LoyaltyNumber  string // 62.04 - Loyalty card number
+	ReferenceLabel string // 62.05 - Merchant or acquirer reference
+	CustomerLabel  string // 62.06 - Customer identifier
+	TerminalLabel  string // 62.07 - Terminal identifier
+
+	// Merchant-presented mode (tags 52, 55-57, 61); nil for a plain transfer QR
+	Merchant *MerchantPayment
+
+	// Language Template (tag 64): name and city with diacritics kept, shown by
+	// banking apps that support it alongside the ASCII tag 59/60 values
+	LanguagePreference string // ISO 639-1 code (default: "vi" when an alternate value is set)
+	MerchantNameAlt    string // Merchant name in UTF-8 (max 25 chars)
+	MerchantCityAlt    string // Merchant city in UTF-8 (max 15 chars)
+
+	// TextPolicy decides what happens to characters in tags 59, 60 and 62
+	// that have no ASCII equivalent (emoji, CJK...). Encode always replaces
+	// them; EncodeStrict rejects them unless TextReplace or TextStrip is set.
+	TextPolicy TextPolicy
 }
 
 // Encoder generates VietQR strings following EMVCo standard
 type Encoder struct {
-	builder strings.Builder
+	builder   strings.Builder
+	truncated []string // Tag paths cut or dropped by the last Encode
 }
 
 // NewEncoder creates a new VietQR encoder
@@ -81,6 +127,7 @@ func NewEncoder() *Encoder {
 // Encode generates a complete VietQR string from transfer information
 func (e *Encoder) Encode(info TransferInfo) string {
 	e.builder.Reset()
+	e.truncated = nil
 	e.builder.Grow(256) // Pre-allocate for performance
 
 	// 00 - Payload Format Indicator (mandatory)
@@ -99,11 +146,14 @@ func (e *Encoder) Encode(info TransferInfo) string {
 	}
 
 	// 38 - Merchant Account Information (VietQR/NAPAS specific)
-	merchantAccount := e.buildMerchantAccount(info.BankBin, info.AccountNumber)
+	merchantAccount := e.buildMerchantAccount(info.BankBin, info.AccountNumber, info.TransferMethod)
 	e.appendTLV(TagMerchantAccount, merchantAccount)
 
-	// 52 - Merchant Category Code (optional, using default)
+	// 52 - Merchant Category Code (merchant mode only)
 	// Not included for basic transfer QR
+	if info.Merchant != nil && info.Merchant.CategoryCode != "" {
+		e.appendTLV(TagMerchantCategory, info.Merchant.CategoryCode)
+	}
 
 	// 53 - Transaction Currency (mandatory)
 	e.appendTLV(TagCurrency, CurrencyVND)
@@ -113,12 +163,17 @@ func (e *Encoder) Encode(info TransferInfo) string {
 		e.appendTLV(TagAmount, strconv.FormatInt(info.Amount, 10))
 	}
 
+	// 55-57 - Tip or Convenience Indicator and fee value (merchant mode only)
+	if info.Merchant != nil {
+		e.appendTipIndicator(info.Merchant)
+	}
+
 	// 58 - Country Code (mandatory)
 	e.appendTLV(TagCountry, CountryVN)
 
 	// 59 - Merchant Name (optional)
 	if info.MerchantName != "" {
-		name := truncateString(removeVietnameseDiacritics(info.MerchantName), 25)
+		name := truncateString(toASCII(info.MerchantName, info.TextPolicy), 25)
 		e.appendTLV(TagMerchantName, name)
 	}
 
@@ -127,15 +182,24 @@ func (e *Encoder) Encode(info TransferInfo) string {
 	if city == "" {
 		city = "Ha Noi"
 	}
-	city = truncateString(removeVietnameseDiacritics(city), 15)
+	city = truncateString(toASCII(city, info.TextPolicy), 15)
 	e.appendTLV(TagMerchantCity, city)
 
-	// 62 - Additional Data Field (conditional - for message/description)
-	if info.Message != "" {
-		additionalData := e.buildAdditionalData(info.Message)
+	// 61 - Postal Code (merchant mode only)
+	if info.Merchant != nil && info.Merchant.PostalCode != "" {
+		e.appendTLV(TagPostalCode, truncateString(info.Merchant.PostalCode, 10))
+	}
+
+	// 62 - Additional Data Field (conditional - bill, store, terminal, message...)
+	if additionalData := e.buildAdditionalData(&info); additionalData != "" {
 		e.appendTLV(TagAdditionalData, additionalData)
 	}
 
+	// 64 - Merchant Information Language Template (optional - UTF-8 name/city)
+	if info.MerchantNameAlt != "" || info.MerchantCityAlt != "" {
+		e.appendTLV(TagLanguageTemplate, e.buildLanguageTemplate(&info))
+	}
+
 	// 63 - CRC (mandatory - calculated last)
 	qrWithoutCRC := e.builder.String()
 	crc := CRC16StringWithTag(qrWithoutCRC)
@@ -146,8 +210,22 @@ func (e *Encoder) Encode(info TransferInfo) string {
 	return e.builder.String()
 }
 
+// appendTipIndicator writes tag 55 and, for fee modes, the matching 56/57 value
+func (e *Encoder) appendTipIndicator(m *MerchantPayment) {
+	switch m.TipIndicator {
+	case TipPrompt:
+		e.appendTLV(TagTipIndicator, TipPrompt)
+	case TipFixedFee:
+		e.appendTLV(TagTipIndicator, TipFixedFee)
+		e.appendTLV(TagConvenienceFixed, strconv.FormatInt(m.ConvenienceFee, 10))
+	case TipPercentageFee:
+		e.appendTLV(TagTipIndicator, TipPercentageFee)
+		e.appendTLV(TagConveniencePercent, formatPercentage(m.ConveniencePercent))
+	}
+}
+
 // buildMerchantAccount creates the nested TLV structure for tag 38
-func (e *Encoder) buildMerchantAccount(bankBin, accountNumber string) string {
+func (e *Encoder) buildMerchantAccount(bankBin, accountNumber, method string) string {
 	var sb strings.Builder
 	sb.Grow(64)
 
@@ -156,10 +234,13 @@ func (e *Encoder) buildMerchantAccount(bankBin, accountNumber string) string {
 
 	// 01 - Beneficiary Organization (contains bank BIN and account)
 	beneficiary := e.buildBeneficiaryOrg(bankBin, accountNumber)
-	appendTLVTo(&sb, "01", beneficiary)
+	e.appendSubTLV(&sb, TagMerchantAccount+"."+SubTagBeneficiaryOrg, beneficiary)
 
-	// 02 - Service Code (QRIBFTTA - Transfer to Account)
-	appendTLVTo(&sb, "02", TransferToAccount)
+	// 02 - Service Code (QRIBFTTA - Transfer to Account, QRIBFTTC - Transfer to Card)
+	if method == "" {
+		method = TransferToAccount
+	}
+	e.appendSubTLV(&sb, TagMerchantAccount+"."+SubTagServiceCode, method)
 
 	return sb.String()
 }
@@ -170,33 +251,96 @@ func (e *Encoder) buildBeneficiaryOrg(bankBin, accountNumber string) string {
 	sb.Grow(32)
 
 	// 00 - Bank BIN (6 digits)
-	appendTLVTo(&sb, "00", bankBin)
+	e.appendTLVTo(&sb, TagMerchantAccount+"."+SubTagBeneficiaryOrg+"."+SubTagAcquirerID, bankBin)
 
-	// 01 - Account Number
-	appendTLVTo(&sb, "01", accountNumber)
+	// 01 - Account Number or Card Number
+	e.appendSubTLV(&sb, TagMerchantAccount+"."+SubTagBeneficiaryOrg+"."+SubTagConsumerID, accountNumber)
 
 	return sb.String()
 }
 
 // buildAdditionalData creates the nested TLV structure for tag 62
-func (e *Encoder) buildAdditionalData(message string) string {
+// Sub-tags are emitted in tag order; empty fields are skipped
+func (e *Encoder) buildAdditionalData(info *TransferInfo) string {
 	var sb strings.Builder
 	sb.Grow(64)
 
-	// 08 - Purpose of Transaction (Description)
-	msg := truncateString(removeVietnameseDiacritics(message), 50)
-	appendTLVTo(&sb, SubTagPurpose, msg)
+	for _, field := range additionalDataFields(info) {
+		if field.value == "" {
+			continue
+		}
+		value := truncateString(toASCII(field.value, info.TextPolicy), field.maxLen)
+		e.appendSubTLV(&sb, TagAdditionalData+"."+field.subTag, value)
+	}
+
+	return sb.String()
+}
+
+// buildLanguageTemplate creates the nested TLV structure for tag 64
+// Values keep their Vietnamese diacritics (NFC); lengths are UTF-8 byte counts
+func (e *Encoder) buildLanguageTemplate(info *TransferInfo) string {
+	var sb strings.Builder
+	sb.Grow(64)
+
+	// 00 - Language Preference (mandatory within the template)
+	lang := info.LanguagePreference
+	if lang == "" {
+		lang = LanguageVietnamese
+	}
+	e.appendTLVTo(&sb, TagLanguageTemplate+"."+SubTagLanguagePreference, lang)
+
+	// 01 - Merchant Name - Alternate Language
+	if info.MerchantNameAlt != "" {
+		e.appendSubTLV(&sb, TagLanguageTemplate+"."+SubTagMerchantNameAlt, truncateString(NormalizeUnicode(info.MerchantNameAlt), 25))
+	}
+
+	// 02 - Merchant City - Alternate Language
+	if info.MerchantCityAlt != "" {
+		e.appendSubTLV(&sb, TagLanguageTemplate+"."+SubTagMerchantCityAlt, truncateString(NormalizeUnicode(info.MerchantCityAlt), 15))
+	}
 
 	return sb.String()
 }
 
 // appendTLV adds a Tag-Length-Value triplet to the encoder's builder
 func (e *Encoder) appendTLV(tag, value string) {
-	appendTLVTo(&e.builder, tag, value)
+	e.appendTLVTo(&e.builder, tag, value)
+}
+
+// appendTLVTo adds the field at path (e.g. "38.01.00") to sb, recording
+// the path when its value is cut to 99 bytes
+func (e *Encoder) appendTLVTo(sb *strings.Builder, path, value string) {
+	if len(value) > MaxValueLength {
+		e.truncated = append(e.truncated, path)
+	}
+	_, tag := splitPath(path)
+	appendTLVTo(sb, tag, value)
+}
+
+// appendSubTLV adds the field at path to the template being built in sb.
+// A field that would push the template past 99 bytes is dropped whole,
+// keeping the template structurally valid, and its path is recorded.
+func (e *Encoder) appendSubTLV(sb *strings.Builder, path, value string) {
+	if sb.Len()+4+len(value) > MaxValueLength {
+		e.truncated = append(e.truncated, path)
+		return
+	}
+	e.appendTLVTo(sb, path, value)
+}
+
+// Truncated returns the tag paths whose values the last Encode cut to 99
+// bytes or dropped to keep their template within 99 bytes. It is nil when
+// every value was encoded in full; a non-nil result means the payload no
+// longer carries what was asked for and should not be served. EncodeStrict
+// rejects such input instead.
+func (e *Encoder) Truncated() []string {
+	return e.truncated
 }
 
 // appendTLVTo adds a TLV triplet to any strings.Builder
+// Values over 99 bytes cannot be described by a 2-digit length and are cut
 func appendTLVTo(sb *strings.Builder, tag, value string) {
+	value = truncateBytes(value, MaxValueLength)
 	sb.WriteString(tag)
 	sb.WriteString(formatLength(len(value)))
 	sb.WriteString(value)
@@ -219,58 +363,26 @@ func truncateString(s string, maxLen int) string {
 	return string(runes[:maxLen])
 }
 
-// removeVietnameseDiacritics converts Vietnamese characters to ASCII
-// This is required because QR code readers may not support Unicode properly
-func removeVietnameseDiacritics(s string) string {
-	var result strings.Builder
-	result.Grow(len(s))
-
-	for _, r := range s {
-		if mapped, ok := vietnameseToASCII[r]; ok {
-			result.WriteRune(mapped)
-		} else if r < 128 {
-			result.WriteRune(r)
-		} else {
-			result.WriteRune(' ') // Replace unknown Unicode with space
-		}
+// truncateBytes truncates a string to at most maxBytes bytes without
+// splitting a UTF-8 sequence
+func truncateBytes(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
 	}
-
-	return result.String()
+	for maxBytes > 0 && !utf8.RuneStart(s[maxBytes]) {
+		maxBytes--
+	}
+	return s[:maxBytes]
 }
 
-// vietnameseToASCII maps Vietnamese characters to their ASCII equivalents
-var vietnameseToASCII = map[rune]rune{
-	// Lowercase
-	'á': 'a', 'à': 'a', 'ả': 'a', 'ã': 'a', 'ạ': 'a',
-	'ă': 'a', 'ắ': 'a', 'ằ': 'a', 'ẳ': 'a', 'ẵ': 'a', 'ặ': 'a',
-	'â': 'a', 'ấ': 'a', 'ầ': 'a', 'ẩ': 'a', 'ẫ': 'a', 'ậ': 'a',
-	'é': 'e', 'è': 'e', 'ẻ': 'e', 'ẽ': 'e', 'ẹ': 'e',
-	'ê': 'e', 'ế': 'e', 'ề': 'e', 'ể': 'e', 'ễ': 'e', 'ệ': 'e',
-	'í': 'i', 'ì': 'i', 'ỉ': 'i', 'ĩ': 'i', 'ị': 'i',
-	'ó': 'o', 'ò': 'o', 'ỏ': 'o', 'õ': 'o', 'ọ': 'o',
-	'ô': 'o', 'ố': 'o', 'ồ': 'o', 'ổ': 'o', 'ỗ': 'o', 'ộ': 'o',
-	'ơ': 'o', 'ớ': 'o', 'ờ': 'o', 'ở': 'o', 'ỡ': 'o', 'ợ': 'o',
-	'ú': 'u', 'ù': 'u', 'ủ': 'u', 'ũ': 'u', 'ụ': 'u',
-	'ư': 'u', 'ứ': 'u', 'ừ': 'u', 'ử': 'u', 'ữ': 'u', 'ự': 'u',
-	'ý': 'y', 'ỳ': 'y', 'ỷ': 'y', 'ỹ': 'y', 'ỵ': 'y',
-	'đ': 'd',
-	// Uppercase
-	'Á': 'A', 'À': 'A', 'Ả': 'A', 'Ã': 'A', 'Ạ': 'A',
-	'Ă': 'A', 'Ắ': 'A', 'Ằ': 'A', 'Ẳ': 'A', 'Ẵ': 'A', 'Ặ': 'A',
-	'Â': 'A', 'Ấ': 'A', 'Ầ': 'A', 'Ẩ': 'A', 'Ẫ': 'A', 'Ậ': 'A',
-	'É': 'E', 'È': 'E', 'Ẻ': 'E', 'Ẽ': 'E', 'Ẹ': 'E',
-	'Ê': 'E', 'Ế': 'E', 'Ề': 'E', 'Ể': 'E', 'Ễ': 'E', 'Ệ': 'E',
-	'Í': 'I', 'Ì': 'I', 'Ỉ': 'I', 'Ĩ': 'I', 'Ị': 'I',
-	'Ó': 'O', 'Ò': 'O', 'Ỏ': 'O', 'Õ': 'O', 'Ọ': 'O',
-	'Ô': 'O', 'Ố': 'O', 'Ồ': 'O', 'Ổ': 'O', 'Ỗ': 'O', 'Ộ': 'O',
-	'Ơ': 'O', 'Ớ': 'O', 'Ờ': 'O', 'Ở': 'O', 'Ỡ': 'O', 'Ợ': 'O',
-	'Ú': 'U', 'Ù': 'U', 'Ủ': 'U', 'Ũ': 'U', 'Ụ': 'U',
-	'Ư': 'U', 'Ứ': 'U', 'Ừ': 'U', 'Ử': 'U', 'Ữ': 'U', 'Ự': 'U',
-	'Ý': 'Y', 'Ỳ': 'Y', 'Ỷ': 'Y', 'Ỹ': 'Y', 'Ỵ': 'Y',
-	'Đ': 'D',
+// removeVietnameseDiacritics converts Vietnamese characters to ASCII
+// This is required because QR code readers may not support Unicode properly
+func removeVietnameseDiacritics(s string) string {
+	return toASCII(s, TextReplace)
 }
 
-// Encode is a package-level convenience function
+// Encode is a package-level convenience function. It cannot report
+// truncated values; use an Encoder and check Truncated, or EncodeStrict.
 func Encode(info TransferInfo) string {
 	return NewEncoder().Encode(info)
 }
@@ -1,6 +1,8 @@
 package vietqr
 
 import (
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -184,3 +186,245 @@ func BenchmarkEncodeParallel(b *testing.B) {
 		}
 	})
 }
+
+func TestEncodeCardTransfer(t *testing.T) {
+	qr := Encode(TransferInfo{
+		BankBin:        "970436",
+		AccountNumber:  "9704366614528730",
+		Amount:         50000,
+		TransferMethod: TransferToCard,
+	})
+
+	info, err := Decode(qr)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if info.TransferMethod != TransferToCard {
+		t.Errorf("TransferMethod = %q, want %q", info.TransferMethod, TransferToCard)
+	}
+	if info.AccountNumber != "9704366614528730" {
+		t.Errorf("AccountNumber = %q", info.AccountNumber)
+	}
+
+	// Default service code stays QRIBFTTA
+	info, _ = Decode(Encode(TransferInfo{BankBin: "970436", AccountNumber: "1234567890"}))
+	if info.TransferMethod != TransferToAccount {
+		t.Errorf("default TransferMethod = %q, want %q", info.TransferMethod, TransferToAccount)
+	}
+}
+
+func TestValidateCardNumber(t *testing.T) {
+	tests := []struct {
+		card string
+		want error
+	}{
+		{"9704366614528730", nil},
+		{"4111111111111111", nil},
+		{"9704366614528735", ErrCardChecksum},
+		{"970436661452873", ErrInvalidCardNumber},
+		{"97043666145287AB", ErrInvalidCardNumber},
+	}
+
+	for _, tt := range tests {
+		if got := ValidateCardNumber(tt.card); got != tt.want {
+			t.Errorf("ValidateCardNumber(%s) = %v, want %v", tt.card, got, tt.want)
+		}
+	}
+
+	if got := MaskCardNumber("9704366614528730"); got != "970436******8730" {
+		t.Errorf("MaskCardNumber() = %s", got)
+	}
+}
+
+func TestEncodeAdditionalData(t *testing.T) {
+	info := TransferInfo{
+		BankBin:        "970436",
+		AccountNumber:  "1234567890",
+		Amount:         120000,
+		Message:        "Don hang 42",
+		BillNumber:     "INV-0042",
+		MobileNumber:   "0912345678",
+		StoreLabel:     "CN Quan 1",
+		LoyaltyNumber:  "***",
+		ReferenceLabel: "REF42",
+		CustomerLabel:  "KH001",
+		TerminalLabel:  "POS07",
+	}
+
+	qr := Encode(info)
+	if !strings.Contains(qr, "0108INV-0042") || !strings.Contains(qr, "0705POS07") {
+		t.Errorf("Encode() = %s, missing tag 62 sub-tags", qr)
+	}
+
+	decoded, err := DecodeStrict(qr)
+	if err != nil {
+		t.Fatalf("DecodeStrict() error = %v", err)
+	}
+	got := TransferInfo{
+		Message:        decoded.Message,
+		BillNumber:     decoded.BillNumber,
+		MobileNumber:   decoded.MobileNumber,
+		StoreLabel:     decoded.StoreLabel,
+		LoyaltyNumber:  decoded.LoyaltyNumber,
+		ReferenceLabel: decoded.ReferenceLabel,
+		CustomerLabel:  decoded.CustomerLabel,
+		TerminalLabel:  decoded.TerminalLabel,
+	}
+	want := info
+	want.BankBin, want.AccountNumber, want.Amount = "", "", 0
+	if got != want {
+		t.Errorf("decoded additional data = %+v, want %+v", got, want)
+	}
+
+	info.StoreLabel = strings.Repeat("S", 26)
+	err = ValidateAdditionalData(info)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "store_label" || fieldErr.Tag != "62.03" {
+		t.Errorf("ValidateAdditionalData() = %v, want store_label length error", err)
+	}
+}
+
+func TestEncoderTruncated(t *testing.T) {
+	info := TransferInfo{
+		BankBin:       "970436",
+		AccountNumber: strings.Repeat("1", 90),
+		BillNumber:    strings.Repeat("B", 25),
+		StoreLabel:    strings.Repeat("S", 25),
+		TerminalLabel: strings.Repeat("T", 25),
+		Message:       "Thanh toan don hang",
+	}
+
+	e := NewEncoder()
+	qr := e.Encode(info)
+	want := []string{"38.01.01", "62.08"}
+	if got := e.Truncated(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Truncated() = %v, want %v", got, want)
+	}
+	if _, err := ParseTLV(qr); err != nil {
+		t.Errorf("dropped fields left a malformed payload: %v", err)
+	}
+
+	e.Encode(TransferInfo{BankBin: "970436", AccountNumber: "1234567890", Message: "Thanh toan don hang"})
+	if got := e.Truncated(); got != nil {
+		t.Errorf("Truncated() after a clean Encode = %v, want nil", got)
+	}
+}
+
+func TestEncodeMerchantMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		merchant MerchantPayment
+		contains []string
+	}{
+		{
+			name:     "tip prompt",
+			merchant: MerchantPayment{CategoryCode: "5812", TipIndicator: TipPrompt, PostalCode: "700000"},
+			contains: []string{"52045812", "550201", "6106700000"},
+		},
+		{
+			name:     "fixed convenience fee",
+			merchant: MerchantPayment{CategoryCode: "5814", TipIndicator: TipFixedFee, ConvenienceFee: 5000},
+			contains: []string{"52045814", "550202", "56045000"},
+		},
+		{
+			name:     "percentage convenience fee",
+			merchant: MerchantPayment{CategoryCode: "5812", TipIndicator: TipPercentageFee, ConveniencePercent: 10.5},
+			contains: []string{"550203", "570410.5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateMerchant(tt.merchant); err != nil {
+				t.Fatalf("ValidateMerchant() error = %v", err)
+			}
+
+			merchant := tt.merchant
+			qr := Encode(TransferInfo{
+				BankBin:       "970436",
+				AccountNumber: "1234567890",
+				Amount:        150000,
+				Merchant:      &merchant,
+			})
+			for _, substr := range tt.contains {
+				if !strings.Contains(qr, substr) {
+					t.Errorf("Encode() = %s, should contain %s", qr, substr)
+				}
+			}
+
+			decoded, err := DecodeStrict(qr)
+			if err != nil {
+				t.Fatalf("DecodeStrict() error = %v", err)
+			}
+			if decoded.Merchant == nil || *decoded.Merchant != tt.merchant {
+				t.Errorf("decoded merchant = %+v, want %+v", decoded.Merchant, tt.merchant)
+			}
+		})
+	}
+}
+
+func TestValidateMerchant(t *testing.T) {
+	tests := []struct {
+		merchant MerchantPayment
+		want     error
+	}{
+		{MerchantPayment{CategoryCode: "1234"}, ErrInvalidMCC},
+		{MerchantPayment{CategoryCode: "5812", TipIndicator: TipFixedFee}, ErrInvalidFee},
+		{MerchantPayment{CategoryCode: "5812", TipIndicator: TipPercentageFee, ConveniencePercent: 100}, ErrInvalidFee},
+		{MerchantPayment{CategoryCode: "5812", TipIndicator: TipPercentageFee, ConveniencePercent: 1.005}, ErrInvalidFee},
+		{MerchantPayment{CategoryCode: "5812", TipIndicator: "04"}, ErrInvalidTipIndicator},
+		{MerchantPayment{CategoryCode: "5812", PostalCode: "70-000"}, ErrInvalidPostalCode},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateMerchant(tt.merchant); !errors.Is(err, tt.want) {
+			t.Errorf("ValidateMerchant(%+v) = %v, want %v", tt.merchant, err, tt.want)
+		}
+	}
+}
+
+func TestValidateMerchantFeeField(t *testing.T) {
+	tests := []struct {
+		merchant MerchantPayment
+		field    string
+	}{
+		{MerchantPayment{CategoryCode: "5812", TipIndicator: TipFixedFee}, "fee_fixed"},
+		{MerchantPayment{CategoryCode: "5812", TipIndicator: TipFixedFee, ConvenienceFee: 5000, ConveniencePercent: 2}, "fee_percent"},
+		{MerchantPayment{CategoryCode: "5812", TipIndicator: TipPercentageFee, ConveniencePercent: 2, ConvenienceFee: 5000}, "fee_fixed"},
+		{MerchantPayment{CategoryCode: "5812", TipIndicator: TipPercentageFee}, "fee_percent"},
+		{MerchantPayment{CategoryCode: "5812", ConvenienceFee: 5000}, "tip"},
+	}
+
+	for _, tt := range tests {
+		var fe *FieldError
+		if err := ValidateMerchant(tt.merchant); !errors.As(err, &fe) || fe.Field != tt.field {
+			t.Errorf("ValidateMerchant(%+v) = %v, want error on %s", tt.merchant, err, tt.field)
+		}
+	}
+}
+
+func TestEncodeLanguageTemplate(t *testing.T) {
+	qr := Encode(TransferInfo{
+		BankBin:         "970436",
+		AccountNumber:   "1234567890",
+		MerchantName:    "Cà phê Đà Lạt",
+		MerchantCity:    "Đà Lạt",
+		MerchantNameAlt: "Cà phê Đà Lạt",
+		MerchantCityAlt: "Đà Lạt",
+	})
+
+	if !strings.Contains(qr, "5913Ca phe Da Lat") {
+		t.Errorf("Encode() = %s, tag 59 should stay ASCII", qr)
+	}
+
+	decoded, err := DecodeStrict(qr)
+	if err != nil {
+		t.Fatalf("DecodeStrict() error = %v", err)
+	}
+	if decoded.LanguagePreference != "vi" || decoded.MerchantNameAlt != "Cà phê Đà Lạt" || decoded.MerchantCityAlt != "Đà Lạt" {
+		t.Errorf("decoded tag 64 = %q %q %q", decoded.LanguagePreference, decoded.MerchantNameAlt, decoded.MerchantCityAlt)
+	}
+	if decoded.MerchantName != "Ca phe Da Lat" {
+		t.Errorf("MerchantName = %q", decoded.MerchantName)
+	}
+}
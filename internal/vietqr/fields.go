@@ -0,0 +1,77 @@
+package vietqr
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Field validation errors
+var (
+	ErrFieldTooLong    = errors.New("field exceeds maximum length")
+	ErrFieldRequired   = errors.New("field is required")
+	ErrInvalidCharset  = errors.New("contains characters that cannot be encoded")
+	ErrInvalidFormat   = errors.New("invalid format")
+	ErrInvalidAmount   = errors.New("amount must be between 0 and 9999999999999")
+	ErrTemplateTooLong = errors.New("encoded template exceeds 99 bytes")
+)
+
+// FieldError reports a TransferInfo field that violates an EMVCo/NAPAS rule
+type FieldError struct {
+	Field string // API field name (e.g., "store_label")
+	Tag   string // EMVCo tag path (e.g., "62.03")
+	Limit int    // Maximum length in characters, when relevant
+	Err   error  // Underlying rule violation
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	if e.Err == ErrFieldTooLong {
+		return e.Field + " must not exceed " + strconv.Itoa(e.Limit) + " characters"
+	}
+	return e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying rule violation
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// additionalDataField describes one sub-tag of tag 62
+type additionalDataField struct {
+	subTag string
+	name   string
+	maxLen int
+	value  string
+}
+
+// additionalDataFields lists the tag 62 sub-tags of info in tag order.
+// EMVCo caps every sub-tag at 25 characters; the purpose field follows
+// the 50-character limit NAPAS banks accept.
+func additionalDataFields(info *TransferInfo) []additionalDataField {
+	return []additionalDataField{
+		{SubTagBillNumber, "bill_number", 25, info.BillNumber},
+		{SubTagMobileNumber, "mobile_number", 25, info.MobileNumber},
+		{SubTagStoreLabel, "store_label", 25, info.StoreLabel},
+		{SubTagLoyaltyNumber, "loyalty_number", 25, info.LoyaltyNumber},
+		{SubTagReferenceLabel, "reference_label", 25, info.ReferenceLabel},
+		{SubTagCustomerLabel, "customer_label", 25, info.CustomerLabel},
+		{SubTagTerminalLabel, "terminal_label", 25, info.TerminalLabel},
+		{SubTagPurpose, "message", 50, info.Message},
+	}
+}
+
+// ValidateAdditionalData checks the tag 62 fields of info against their
+// length limits and returns a *FieldError for the first violation
+func ValidateAdditionalData(info TransferInfo) error {
+	for _, field := range additionalDataFields(&info) {
+		if len(toASCII(field.value, info.TextPolicy)) > field.maxLen {
+			return &FieldError{
+				Field: field.name,
+				Tag:   TagAdditionalData + "." + field.subTag,
+				Limit: field.maxLen,
+				Err:   ErrFieldTooLong,
+			}
+		}
+	}
+	return nil
+}
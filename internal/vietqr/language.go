@@ -0,0 +1,39 @@
+package vietqr
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// Language template errors
+var (
+	ErrInvalidLanguage = errors.New("language preference must be a 2-letter ISO 639-1 code")
+)
+
+// HasDiacritics reports whether s contains characters that the ASCII
+// tag 59/60 values cannot carry, i.e. whether tag 64 would preserve more
+func HasDiacritics(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateLanguageTemplate checks the tag 64 fields of info and returns a
+// *FieldError for the first violation
+func ValidateLanguageTemplate(info TransferInfo) error {
+	if lang := info.LanguagePreference; lang != "" {
+		if len(lang) != 2 || lang[0] < 'a' || lang[0] > 'z' || lang[1] < 'a' || lang[1] > 'z' {
+			return &FieldError{Field: "language", Tag: TagLanguageTemplate + "." + SubTagLanguagePreference, Err: ErrInvalidLanguage}
+		}
+	}
+	if utf8.RuneCountInString(NormalizeUnicode(info.MerchantNameAlt)) > 25 {
+		return &FieldError{Field: "merchant_name_alt", Tag: TagLanguageTemplate + "." + SubTagMerchantNameAlt, Limit: 25, Err: ErrFieldTooLong}
+	}
+	if utf8.RuneCountInString(NormalizeUnicode(info.MerchantCityAlt)) > 15 {
+		return &FieldError{Field: "merchant_city_alt", Tag: TagLanguageTemplate + "." + SubTagMerchantCityAlt, Limit: 15, Err: ErrFieldTooLong}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package vietqr
+
+// MerchantCategories maps ISO 18245 merchant category codes to their
+// descriptions. The table covers the categories Vietnamese acquirers
+// accept for merchant-presented QR.
+var MerchantCategories = map[string]string{
+	// Agricultural and contracted services
+	"0742": "Veterinary Services",
+	"0763": "Agricultural Cooperatives",
+	"0780": "Landscaping and Horticultural Services",
+	"1520": "General Contractors - Residential and Commercial",
+	"1711": "Heating, Plumbing and Air Conditioning Contractors",
+	"1731": "Electrical Contractors",
+	"1750": "Carpentry Contractors",
+	"1799": "Special Trade Contractors",
+
+	// Transportation
+	"4111": "Local and Suburban Commuter Passenger Transportation",
+	"4121": "Taxicabs and Limousines",
+	"4131": "Bus Lines",
+	"4214": "Motor Freight Carriers and Trucking",
+	"4215": "Courier Services",
+	"4411": "Steamship and Cruise Lines",
+	"4511": "Airlines and Air Carriers",
+	"4722": "Travel Agencies and Tour Operators",
+	"4784": "Tolls and Bridge Fees",
+	"4789": "Transportation Services",
+
+	// Utilities and telecommunication
+	"4812": "Telecommunication Equipment and Telephone Sales",
+	"4814": "Telecommunication Services",
+	"4816": "Computer Network and Information Services",
+	"4899": "Cable, Satellite and Other Pay Television Services",
+	"4900": "Utilities - Electric, Gas, Water and Sanitary",
+
+	// Retail outlets
+	"5013": "Motor Vehicle Supplies and New Parts",
+	"5021": "Office and Commercial Furniture",
+	"5039": "Construction Materials",
+	"5044": "Photographic, Photocopy and Microfilm Equipment",
+	"5045": "Computers and Computer Peripheral Equipment",
+	"5047": "Medical, Dental and Hospital Equipment",
+	"5065": "Electrical Parts and Equipment",
+	"5072": "Hardware, Equipment and Supplies",
+	"5094": "Precious Stones, Metals, Watches and Jewelry",
+	"5111": "Stationery, Office Supplies and Printing Paper",
+	"5122": "Drugs, Drug Proprietaries and Druggist Sundries",
+	"5137": "Men's, Women's and Children's Uniforms",
+	"5192": "Books, Periodicals and Newspapers",
+	"5200": "Home Supply Warehouse Stores",
+	"5211": "Lumber and Building Materials Stores",
+	"5251": "Hardware Stores",
+	"5261": "Nurseries and Lawn and Garden Supply Stores",
+	"5300": "Wholesale Clubs",
+	"5311": "Department Stores",
+	"5331": "Variety Stores",
+	"5399": "Miscellaneous General Merchandise",
+	"5411": "Grocery Stores and Supermarkets",
+	"5422": "Freezer and Locker Meat Provisioners",
+	"5441": "Candy, Nut and Confectionery Stores",
+	"5451": "Dairy Products Stores",
+	"5462": "Bakeries",
+	"5499": "Miscellaneous Food Stores - Convenience Stores",
+	"5511": "Car and Truck Dealers (New and Used)",
+	"5521": "Car and Truck Dealers (Used Only)",
+	"5532": "Automotive Tire Stores",
+	"5533": "Automotive Parts and Accessories Stores",
+	"5541": "Service Stations",
+	"5542": "Automated Fuel Dispensers",
+	"5571": "Motorcycle Shops and Dealers",
+	"5611": "Men's and Boys' Clothing and Accessories Stores",
+	"5621": "Women's Ready-to-Wear Stores",
+	"5631": "Women's Accessory and Specialty Shops",
+	"5641": "Children's and Infants' Wear Stores",
+	"5651": "Family Clothing Stores",
+	"5655": "Sports and Riding Apparel Stores",
+	"5661": "Shoe Stores",
+	"5691": "Men's and Women's Clothing Stores",
+	"5699": "Miscellaneous Apparel and Accessory Shops",
+	"5712": "Furniture, Home Furnishings and Equipment Stores",
+	"5719": "Miscellaneous Home Furnishing Specialty Stores",
+	"5722": "Household Appliance Stores",
+	"5732": "Electronics Stores",
+	"5734": "Computer Software Stores",
+	"5735": "Record Stores",
+
+	// Eating places and entertainment
+	"5811": "Caterers",
+	"5812": "Eating Places and Restaurants",
+	"5813": "Drinking Places - Bars, Taverns, Nightclubs",
+	"5814": "Fast Food Restaurants",
+	"5815": "Digital Goods - Media",
+	"5816": "Digital Goods - Games",
+	"5817": "Digital Goods - Applications",
+	"5818": "Digital Goods - Large Digital Goods Merchant",
+
+	// Miscellaneous retail
+	"5912": "Drug Stores and Pharmacies",
+	"5921": "Package Stores - Beer, Wine and Liquor",
+	"5931": "Used Merchandise and Secondhand Stores",
+	"5941": "Sporting Goods Stores",
+	"5942": "Book Stores",
+	"5943": "Stationery, Office and School Supply Stores",
+	"5944": "Jewelry, Watch, Clock and Silverware Stores",
+	"5945": "Hobby, Toy and Game Shops",
+	"5946": "Camera and Photographic Supply Stores",
+	"5947": "Gift, Card, Novelty and Souvenir Shops",
+	"5948": "Luggage and Leather Goods Stores",
+	"5949": "Sewing, Needlework, Fabric and Piece Goods Stores",
+	"5950": "Glassware and Crystal Stores",
+	"5964": "Direct Marketing - Catalog Merchant",
+	"5965": "Direct Marketing - Combination Catalog and Retail Merchant",
+	"5968": "Direct Marketing - Continuity/Subscription Merchant",
+	"5969": "Direct Marketing - Other Direct Marketers",
+	"5970": "Artist's Supply and Craft Shops",
+	"5977": "Cosmetic Stores",
+	"5992": "Florists",
+	"5993": "Cigar Stores and Stands",
+	"5994": "News Dealers and Newsstands",
+	"5995": "Pet Shops, Pet Food and Supplies",
+	"5999": "Miscellaneous and Specialty Retail Stores",
+
+	// Financial services
+	"6010": "Financial Institutions - Manual Cash Disbursements",
+	"6011": "Financial Institutions - Automated Cash Disbursements",
+	"6012": "Financial Institutions - Merchandise and Services",
+	"6051": "Non-Financial Institutions - Foreign Currency, Money Orders",
+	"6211": "Security Brokers and Dealers",
+	"6300": "Insurance Sales, Underwriting and Premiums",
+	"6513": "Real Estate Agents and Managers - Rentals",
+	"6540": "Non-Financial Institutions - Stored Value Card Purchase/Load",
+
+	// Lodging
+	"7011": "Lodging - Hotels, Motels and Resorts",
+	"7012": "Timeshares",
+	"7032": "Sporting and Recreational Camps",
+	"7033": "Trailer Parks and Campgrounds",
+
+	// Personal and business services
+	"7210": "Laundry, Cleaning and Garment Services",
+	"7211": "Laundry Services - Family and Commercial",
+	"7216": "Dry Cleaners",
+	"7221": "Photographic Studios",
+	"7230": "Beauty and Barber Shops",
+	"7251": "Shoe Repair Shops, Shoe Shine Parlors and Hat Cleaning Shops",
+	"7261": "Funeral Services and Crematories",
+	"7273": "Dating and Escort Services",
+	"7297": "Massage Parlors",
+	"7298": "Health and Beauty Spas",
+	"7299": "Miscellaneous Personal Services",
+	"7311": "Advertising Services",
+	"7333": "Commercial Photography, Art and Graphics",
+	"7338": "Quick Copy, Reproduction and Blueprinting Services",
+	"7342": "Exterminating and Disinfecting Services",
+	"7349": "Cleaning, Maintenance and Janitorial Services",
+	"7372": "Computer Programming, Data Processing and Integrated Systems Design",
+	"7392": "Management, Consulting and Public Relations Services",
+	"7394": "Equipment, Tool, Furniture and Appliance Rental and Leasing",
+	"7399": "Business Services",
+	"7512": "Automobile Rental Agency",
+	"7523": "Parking Lots and Garages",
+	"7531": "Automotive Body Repair Shops",
+	"7538": "Automotive Service Shops",
+	"7542": "Car Washes",
+	"7622": "Electronics Repair Shops",
+	"7629": "Electrical and Small Appliance Repair Shops",
+	"7699": "Miscellaneous Repair Shops and Related Services",
+
+	// Amusement and entertainment
+	"7832": "Motion Picture Theaters",
+	"7841": "Video Tape Rental Stores",
+	"7911": "Dance Halls, Studios and Schools",
+	"7922": "Theatrical Producers and Ticket Agencies",
+	"7929": "Bands, Orchestras and Miscellaneous Entertainers",
+	"7932": "Billiard and Pool Establishments",
+	"7933": "Bowling Alleys",
+	"7941": "Commercial Sports, Professional Sports Clubs and Athletic Fields",
+	"7991": "Tourist Attractions and Exhibits",
+	"7992": "Public Golf Courses",
+	"7994": "Video Game Arcades and Establishments",
+	"7996": "Amusement Parks, Circuses, Carnivals and Fortune Tellers",
+	"7997": "Membership Clubs - Sports, Recreation, Athletic",
+	"7998": "Aquariums, Seaquariums and Dolphinariums",
+	"7999": "Recreation Services",
+
+	// Professional services and membership organizations
+	"8011": "Doctors",
+	"8021": "Dentists and Orthodontists",
+	"8031": "Osteopaths",
+	"8041": "Chiropractors",
+	"8042": "Optometrists and Ophthalmologists",
+	"8043": "Opticians, Optical Goods and Eyeglasses",
+	"8049": "Podiatrists and Chiropodists",
+	"8050": "Nursing and Personal Care Facilities",
+	"8062": "Hospitals",
+	"8071": "Medical and Dental Laboratories",
+	"8099": "Medical Services and Health Practitioners",
+	"8111": "Legal Services and Attorneys",
+	"8211": "Elementary and Secondary Schools",
+	"8220": "Colleges, Universities and Professional Schools",
+	"8241": "Correspondence Schools",
+	"8244": "Business and Secretarial Schools",
+	"8249": "Vocational and Trade Schools",
+	"8299": "Schools and Educational Services",
+	"8351": "Child Care Services",
+	"8398": "Charitable and Social Service Organizations",
+	"8641": "Civic, Social and Fraternal Associations",
+	"8651": "Political Organizations",
+	"8661": "Religious Organizations",
+	"8675": "Automobile Associations",
+	"8699": "Membership Organizations",
+	"8734": "Testing Laboratories (Non-Medical)",
+	"8911": "Architectural, Engineering and Surveying Services",
+	"8931": "Accounting, Auditing and Bookkeeping Services",
+	"8999": "Professional Services",
+
+	// Government services
+	"9211": "Court Costs, Including Alimony and Child Support",
+	"9222": "Fines",
+	"9311": "Tax Payments",
+	"9399": "Government Services",
+	"9402": "Postal Services - Government Only",
+}
+
+// IsValidMCC checks if a merchant category code is in the ISO 18245 table
+func IsValidMCC(code string) bool {
+	_, exists := MerchantCategories[code]
+	return exists
+}
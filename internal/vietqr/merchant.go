@@ -0,0 +1,108 @@
+package vietqr
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Tip or Convenience Indicator values (tag 55)
+const (
+	TipPrompt        = "01" // Consumer is prompted to enter a tip
+	TipFixedFee      = "02" // Fixed convenience fee in tag 56
+	TipPercentageFee = "03" // Percentage convenience fee in tag 57
+)
+
+// Merchant mode errors
+var (
+	ErrInvalidMCC          = errors.New("unknown ISO 18245 merchant category code")
+	ErrInvalidTipIndicator = errors.New("tip indicator must be prompt, fixed or percentage")
+	ErrInvalidFee          = errors.New("invalid convenience fee")
+	ErrInvalidPostalCode   = errors.New("postal code must be 1-10 alphanumeric characters")
+)
+
+// MerchantPayment holds the merchant-presented QR fields that a plain
+// transfer QR leaves out
+type MerchantPayment struct {
+	CategoryCode       string  `json:"mcc"`                     // 52 - ISO 18245 MCC (e.g., "5812" restaurants)
+	TipIndicator       string  `json:"tip_indicator,omitempty"` // 55 - TipPrompt, TipFixedFee or TipPercentageFee ("" for none)
+	ConvenienceFee     int64   `json:"fee_fixed,omitempty"`     // 56 - Fixed fee in VND (TipFixedFee)
+	ConveniencePercent float64 `json:"fee_percent,omitempty"`   // 57 - Fee percentage, 0.01-99.99 (TipPercentageFee)
+	PostalCode         string  `json:"postal_code,omitempty"`   // 61 - Postal code
+}
+
+// ParseTipIndicator maps a user-facing tip mode ("prompt", "fixed",
+// "percentage") or a raw tag 55 value to the tag 55 value
+func ParseTipIndicator(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return "", nil
+	case "prompt", "tip", TipPrompt:
+		return TipPrompt, nil
+	case "fixed", "fixed_fee", TipFixedFee:
+		return TipFixedFee, nil
+	case "percentage", "percent", "percentage_fee", TipPercentageFee:
+		return TipPercentageFee, nil
+	}
+	return "", ErrInvalidTipIndicator
+}
+
+// ValidateMerchant checks the merchant-mode fields and returns a *FieldError
+// for the first violation
+func ValidateMerchant(m MerchantPayment) error {
+	if !IsValidMCC(m.CategoryCode) {
+		return &FieldError{Field: "mcc", Tag: TagMerchantCategory, Err: ErrInvalidMCC}
+	}
+
+	switch m.TipIndicator {
+	case "", TipPrompt:
+		if m.ConvenienceFee != 0 || m.ConveniencePercent != 0 {
+			return &FieldError{Field: "tip", Tag: TagTipIndicator, Err: ErrInvalidFee}
+		}
+	case TipFixedFee:
+		if m.ConvenienceFee <= 0 {
+			return &FieldError{Field: "fee_fixed", Tag: TagConvenienceFixed, Err: ErrInvalidFee}
+		}
+		if m.ConveniencePercent != 0 {
+			return &FieldError{Field: "fee_percent", Tag: TagConveniencePercent, Err: ErrInvalidFee}
+		}
+		if len(strconv.FormatInt(m.ConvenienceFee, 10)) > 13 {
+			return &FieldError{Field: "fee_fixed", Tag: TagConvenienceFixed, Limit: 13, Err: ErrFieldTooLong}
+		}
+	case TipPercentageFee:
+		p := m.ConveniencePercent
+		if p < 0.01 || p > 99.99 || math.Abs(math.Round(p*100)-p*100) > 1e-9 {
+			return &FieldError{Field: "fee_percent", Tag: TagConveniencePercent, Err: ErrInvalidFee}
+		}
+		if m.ConvenienceFee != 0 {
+			return &FieldError{Field: "fee_fixed", Tag: TagConvenienceFixed, Err: ErrInvalidFee}
+		}
+	default:
+		return &FieldError{Field: "tip", Tag: TagTipIndicator, Err: ErrInvalidTipIndicator}
+	}
+
+	if m.PostalCode != "" {
+		if len(m.PostalCode) > 10 || !isAlphanumeric(m.PostalCode) {
+			return &FieldError{Field: "postal_code", Tag: TagPostalCode, Err: ErrInvalidPostalCode}
+		}
+	}
+
+	return nil
+}
+
+// formatPercentage renders a tag 57 value with at most two decimals
+func formatPercentage(p float64) string {
+	return strconv.FormatFloat(math.Round(p*100)/100, 'f', -1, 64)
+}
+
+// isAlphanumeric reports whether s only contains ASCII letters and digits
+func isAlphanumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+			return false
+		}
+	}
+	return true
+}
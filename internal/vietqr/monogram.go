@@ -0,0 +1,32 @@
+package vietqr
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// monogramFS holds one PNG per bank, named after its BIN: the bank's
+// abbreviation on a brand-like colour. They are placeholders, not the banks'
+// official logos, which are not bundled.
+//
+//go:embed monograms/*.png
+var monogramFS embed.FS
+
+// MonogramPath is the API path a bank's monogram is served from (Bank.Monogram)
+const MonogramPath = "/api/v1/banks/%s/monogram"
+
+// BankMonogram returns the embedded PNG monogram of the bank with the given
+// BIN, or nil when there is none
+func BankMonogram(bin string) []byte {
+	data, err := monogramFS.ReadFile("monograms/" + bin + ".png")
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// hasMonogram reports whether an embedded monogram exists for the BIN
+func hasMonogram(bin string) bool {
+	_, err := fs.Stat(monogramFS, "monograms/"+bin+".png")
+	return err == nil
+}
@@ -0,0 +1,36 @@
+package vietqr
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"testing"
+)
+
+func TestBankMonograms(t *testing.T) {
+	seen := make(map[string]string) // Monogram bytes to the bank using them
+	for code, bank := range Banks {
+		data := BankMonogram(bank.BIN)
+		if data == nil {
+			t.Errorf("%s: no embedded monogram for BIN %s", code, bank.BIN)
+			continue
+		}
+		if other, ok := seen[string(data)]; ok {
+			t.Errorf("%s: monogram is identical to %s's", code, other)
+		}
+		seen[string(data)] = code
+		if _, err := png.DecodeConfig(bytes.NewReader(data)); err != nil {
+			t.Errorf("%s: monogram is not a PNG: %v", code, err)
+		}
+		if want := fmt.Sprintf(MonogramPath, bank.BIN); bank.Monogram != want {
+			t.Errorf("%s: Monogram = %q, want %q", code, bank.Monogram, want)
+		}
+		if bank.Logo != "" {
+			t.Errorf("%s: Logo = %q, but no official logos are bundled", code, bank.Logo)
+		}
+	}
+
+	if BankMonogram("000000") != nil {
+		t.Error("BankMonogram(000000) should be nil")
+	}
+}
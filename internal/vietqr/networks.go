@@ -0,0 +1,136 @@
+package vietqr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Payment network IDs reported in DecodedInfo.Network
+const (
+	NetworkNAPAS       = "napas"
+	NetworkUnsupported = "unsupported" // Merchant account data present, but no known network
+)
+
+// PaymentNetwork describes a merchant account template a QR can carry.
+// Card schemes use the primitive tags EMVCo reserves for them (02-25);
+// every other network is identified by the GUID/AID in sub-tag 00 of a
+// template in tags 26-51.
+type PaymentNetwork struct {
+	ID      string            `json:"id"`                 // Stable identifier (e.g., "vnpay")
+	Name    string            `json:"name"`               // Display name
+	GUIDs   []string          `json:"guids,omitempty"`    // Sub-tag 00 values, compared case-insensitively
+	Tags    []string          `json:"tags,omitempty"`     // Reserved primitive tags (card schemes)
+	SubTags map[string]string `json:"sub_tags,omitempty"` // Sub-tag meanings inside the template
+}
+
+// PaymentNetworks is the registry of known merchant account templates
+var PaymentNetworks = []*PaymentNetwork{
+	{ID: NetworkNAPAS, Name: "NAPAS 247 (VietQR)", GUIDs: []string{NAPAS_GUID},
+		SubTags: map[string]string{"01": "beneficiary_org", "02": "service_code"}},
+	{ID: "vnpay", Name: "VNPAY-QR", GUIDs: []string{"A000000775"},
+		SubTags: map[string]string{"01": "merchant_id"}},
+	{ID: "momo", Name: "MoMo", GUIDs: []string{"VN.MOMO", "COM.MOMO", "VN.COM.MOMO"},
+		SubTags: map[string]string{"01": "merchant_id", "02": "store_id"}},
+	{ID: "zalopay", Name: "ZaloPay", GUIDs: []string{"VN.ZALOPAY", "COM.ZALOPAY", "VN.COM.ZALOPAY"},
+		SubTags: map[string]string{"01": "merchant_id", "02": "store_id"}},
+	{ID: "shopeepay", Name: "ShopeePay", GUIDs: []string{"VN.AIRPAY", "COM.AIRPAY", "VN.SHOPEEPAY", "COM.SHOPEEPAY"},
+		SubTags: map[string]string{"01": "merchant_id", "02": "store_id"}},
+	{ID: "promptpay", Name: "PromptPay (Thailand)", GUIDs: []string{"A000000677010111", "A000000677010112"},
+		SubTags: map[string]string{"01": "mobile_number", "02": "national_id", "03": "ewallet_id"}},
+	{ID: "paynow", Name: "PayNow (Singapore)", GUIDs: []string{"SG.PAYNOW"},
+		SubTags: map[string]string{"01": "proxy_type", "02": "proxy_value", "03": "editable", "04": "expiry"}},
+	{ID: "nets", Name: "NETS (Singapore)", GUIDs: []string{"SG.COM.NETS"},
+		SubTags: map[string]string{"01": "merchant_id"}},
+	{ID: "duitnow", Name: "DuitNow (Malaysia)", GUIDs: []string{"A0000006150001"},
+		SubTags: map[string]string{"01": "merchant_id"}},
+	{ID: "qris", Name: "QRIS (Indonesia)", GUIDs: []string{"ID.CO.QRIS.WWW"},
+		SubTags: map[string]string{"01": "merchant_pan", "02": "merchant_id", "03": "merchant_criteria"}},
+
+	{ID: "visa", Name: "Visa", Tags: []string{"02", "03"}},
+	{ID: "mastercard", Name: "Mastercard", Tags: []string{"04", "05"}},
+	{ID: "discover", Name: "Discover", Tags: []string{"09", "10"}},
+	{ID: "amex", Name: "American Express", Tags: []string{"11", "12"}},
+	{ID: "jcb", Name: "JCB", Tags: []string{"13", "14"}},
+	{ID: "unionpay", Name: "UnionPay", Tags: []string{"15", "16"}},
+}
+
+// MerchantAccount is one merchant account field (tags 02-51) of a payload
+type MerchantAccount struct {
+	Tag         string            `json:"tag"`
+	GUID        string            `json:"guid,omitempty"`         // Sub-tag 00 of a template
+	Network     string            `json:"network"`                // Network ID, or "unsupported"
+	NetworkName string            `json:"network_name,omitempty"` // Display name of a known network
+	Fields      map[string]string `json:"fields,omitempty"`       // Identifiers keyed by sub-tag meaning
+}
+
+// GetNetworkByGUID returns the network whose template uses guid, or nil
+func GetNetworkByGUID(guid string) *PaymentNetwork {
+	for _, network := range PaymentNetworks {
+		for _, known := range network.GUIDs {
+			if strings.EqualFold(known, guid) {
+				return network
+			}
+		}
+	}
+	return nil
+}
+
+// GetNetworkByTag returns the card scheme that reserves a primitive tag, or nil
+func GetNetworkByTag(tag string) *PaymentNetwork {
+	for _, network := range PaymentNetworks {
+		for _, known := range network.Tags {
+			if known == tag {
+				return network
+			}
+		}
+	}
+	return nil
+}
+
+// isMerchantAccountTag reports whether a root tag holds merchant account information
+func isMerchantAccountTag(tag string) bool {
+	n, err := strconv.Atoi(tag)
+	return err == nil && n >= 2 && n <= 51
+}
+
+// classifyMerchantAccount identifies the network of a merchant account field
+// and extracts its identifiers
+func classifyMerchantAccount(node *TLVNode) MerchantAccount {
+	account := MerchantAccount{Tag: node.Tag, Network: NetworkUnsupported}
+
+	if !node.IsTemplate() {
+		// Card scheme tags hold the merchant PAN directly
+		if network := GetNetworkByTag(node.Tag); network != nil {
+			account.Network = network.ID
+			account.NetworkName = network.Name
+			account.Fields = map[string]string{"merchant_pan": node.Value}
+		}
+		return account
+	}
+
+	account.GUID = node.Get(SubTagGUID)
+	network := GetNetworkByGUID(account.GUID)
+	if network != nil {
+		account.Network = network.ID
+		account.NetworkName = network.Name
+	}
+
+	// Unknown sub-tags are reported by number so no identifier is lost
+	for _, child := range node.Children {
+		if child.Tag == SubTagGUID {
+			continue
+		}
+		name := "sub_tag_" + child.Tag
+		if network != nil {
+			if known, ok := network.SubTags[child.Tag]; ok {
+				name = known
+			}
+		}
+		if account.Fields == nil {
+			account.Fields = make(map[string]string, len(node.Children))
+		}
+		account.Fields[name] = child.Value
+	}
+
+	return account
+}
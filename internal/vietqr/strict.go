@@ -0,0 +1,147 @@
+package vietqr
+
+// Field limits (characters) from the NAPAS VietQR and EMVCo specifications
+const (
+	maxAccountNumberLen = 19
+	maxAmountDigits     = 13
+	maxMerchantNameLen  = 25
+	maxMerchantCityLen  = 15
+)
+
+// EncodeStrict generates a VietQR string after checking every field against
+// its NAPAS/EMVCo limits and allowed character set. Unlike Encode it never
+// truncates or replaces characters: the first violation is returned as a
+// *FieldError naming the offending field.
+func (e *Encoder) EncodeStrict(info TransferInfo) (string, error) {
+	if err := ValidateTransferInfo(info); err != nil {
+		return "", err
+	}
+	return e.Encode(info), nil
+}
+
+// EncodeStrict is a package-level convenience function
+func EncodeStrict(info TransferInfo) (string, error) {
+	return NewEncoder().EncodeStrict(info)
+}
+
+// ValidateTransferInfo checks info against the NAPAS/EMVCo field rules and
+// returns a *FieldError for the first violation, or nil
+func ValidateTransferInfo(info TransferInfo) error {
+	// 38.01.00 - Bank BIN: exactly 6 digits
+	if info.BankBin == "" {
+		return &FieldError{Field: "bank_bin", Tag: "38.01.00", Err: ErrFieldRequired}
+	}
+	if len(info.BankBin) != 6 || !isDigits(info.BankBin) {
+		return &FieldError{Field: "bank_bin", Tag: "38.01.00", Err: ErrInvalidFormat}
+	}
+
+	// 38.01.01 - Account number (alphanumeric, per-bank rule) or card number (Luhn)
+	switch info.TransferMethod {
+	case "", TransferToAccount:
+		if err := validateAccountField(info.AccountNumber); err != nil {
+			return err
+		}
+		if err := ValidateAccountNumber(info.BankBin, info.AccountNumber); err != nil {
+			return &FieldError{Field: "account_number", Tag: "38.01.01", Err: err}
+		}
+	case TransferToCard:
+		if err := ValidateCardNumber(info.AccountNumber); err != nil {
+			return &FieldError{Field: "card_number", Tag: "38.01.01", Err: err}
+		}
+	default:
+		return &FieldError{Field: "transfer_method", Tag: "38.02", Err: ErrInvalidMethod}
+	}
+
+	// 54 - Amount: non-negative, at most 13 digits
+	if info.Amount < 0 || info.Amount > 9999999999999 {
+		return &FieldError{Field: "amount", Tag: TagAmount, Limit: maxAmountDigits, Err: ErrInvalidAmount}
+	}
+
+	// 59/60 - Merchant name and city: ASCII after removing diacritics
+	if _, err := validateTextField("merchant_name", TagMerchantName, info.MerchantName, maxMerchantNameLen, info.TextPolicy); err != nil {
+		return err
+	}
+	if _, err := validateTextField("merchant_city", TagMerchantCity, info.MerchantCity, maxMerchantCityLen, info.TextPolicy); err != nil {
+		return err
+	}
+
+	// 52, 55-57, 61 - Merchant mode
+	if info.Merchant != nil {
+		if err := ValidateMerchant(*info.Merchant); err != nil {
+			return err
+		}
+	}
+
+	// 62 - Additional data: per sub-tag limits, then the template as a whole
+	templateLen := 0
+	for _, field := range additionalDataFields(&info) {
+		tag := TagAdditionalData + "." + field.subTag
+		value, err := validateTextField(field.name, tag, field.value, field.maxLen, info.TextPolicy)
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			templateLen += 4 + len(value)
+		}
+	}
+	if templateLen > MaxValueLength {
+		return &FieldError{Field: "additional_data", Tag: TagAdditionalData, Limit: MaxValueLength, Err: ErrTemplateTooLong}
+	}
+
+	// 64 - Language template: UTF-8 values, measured in bytes once encoded
+	if err := ValidateLanguageTemplate(info); err != nil {
+		return err
+	}
+	if info.MerchantNameAlt != "" || info.MerchantCityAlt != "" {
+		templateLen = 6 // 00 - Language Preference
+		if info.MerchantNameAlt != "" {
+			templateLen += 4 + len(NormalizeUnicode(info.MerchantNameAlt))
+		}
+		if info.MerchantCityAlt != "" {
+			templateLen += 4 + len(NormalizeUnicode(info.MerchantCityAlt))
+		}
+		if templateLen > MaxValueLength {
+			return &FieldError{Field: "language_template", Tag: TagLanguageTemplate, Limit: MaxValueLength, Err: ErrTemplateTooLong}
+		}
+	}
+
+	return nil
+}
+
+// validateAccountField checks a bank account number: 1-19 letters or digits
+func validateAccountField(accountNumber string) error {
+	if accountNumber == "" {
+		return &FieldError{Field: "account_number", Tag: "38.01.01", Err: ErrFieldRequired}
+	}
+	if len(accountNumber) > maxAccountNumberLen {
+		return &FieldError{Field: "account_number", Tag: "38.01.01", Limit: maxAccountNumberLen, Err: ErrFieldTooLong}
+	}
+	if !isAlphanumeric(accountNumber) {
+		return &FieldError{Field: "account_number", Tag: "38.01.01", Err: ErrInvalidCharset}
+	}
+	return nil
+}
+
+// validateTextField normalizes a free-text field the way the encoder will
+// and checks the result's length and character set. It returns the
+// normalized value.
+func validateTextField(name, tag, value string, maxLen int, policy TextPolicy) (string, error) {
+	normalized, err := NormalizeText(value, policy)
+	if err != nil {
+		return "", &FieldError{Field: name, Tag: tag, Err: err}
+	}
+	if len(normalized) > maxLen {
+		return "", &FieldError{Field: name, Tag: tag, Limit: maxLen, Err: ErrFieldTooLong}
+	}
+	return normalized, nil
+}
+
+// isDigits reports whether s only contains ASCII digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,113 @@
+package vietqr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeStrict(t *testing.T) {
+	valid := TransferInfo{
+		BankBin:       "970436",
+		AccountNumber: "1234567890",
+		Amount:        100000,
+		Message:       "Thanh toán đơn hàng số 12345 tại cửa hàng Quận 1", // 48 characters, 61 bytes
+	}
+
+	qr, err := EncodeStrict(valid)
+	if err != nil {
+		t.Fatalf("EncodeStrict() error = %v", err)
+	}
+	if errs := Validate(qr); errs != nil {
+		t.Errorf("EncodeStrict() produced invalid payload: %v", errs)
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(*TransferInfo)
+		field string
+		want  error
+	}{
+		{"missing BIN", func(i *TransferInfo) { i.BankBin = "" }, "bank_bin", ErrFieldRequired},
+		{"short BIN", func(i *TransferInfo) { i.BankBin = "97043" }, "bank_bin", ErrInvalidFormat},
+		{"account too long", func(i *TransferInfo) { i.AccountNumber = strings.Repeat("1", 20) }, "account_number", ErrFieldTooLong},
+		{"account charset", func(i *TransferInfo) { i.AccountNumber = "1234 5678" }, "account_number", ErrInvalidCharset},
+		{"card checksum", func(i *TransferInfo) {
+			i.TransferMethod, i.AccountNumber = TransferToCard, "9704366614528731"
+		}, "card_number", ErrCardChecksum},
+		{"negative amount", func(i *TransferInfo) { i.Amount = -1 }, "amount", ErrInvalidAmount},
+		{"message too long", func(i *TransferInfo) { i.Message = strings.Repeat("a", 51) }, "message", ErrFieldTooLong},
+		{"message emoji", func(i *TransferInfo) { i.Message = "Cảm ơn 🙏" }, "message", ErrInvalidCharset},
+		{"name too long", func(i *TransferInfo) { i.MerchantName = strings.Repeat("N", 26) }, "merchant_name", ErrFieldTooLong},
+		{"tag 62 over 99 bytes", func(i *TransferInfo) {
+			i.BillNumber = strings.Repeat("B", 25)
+			i.StoreLabel = strings.Repeat("S", 25)
+			i.TerminalLabel = strings.Repeat("T", 25)
+		}, "additional_data", ErrTemplateTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := valid
+			tt.edit(&info)
+
+			_, err := EncodeStrict(info)
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("EncodeStrict() error = %v, want *FieldError", err)
+			}
+			if fieldErr.Field != tt.field || !errors.Is(err, tt.want) {
+				t.Errorf("EncodeStrict() error = %v (field %s), want %v on %s", err, fieldErr.Field, tt.want, tt.field)
+			}
+		})
+	}
+}
+
+func TestEncodeNeverExceedsTwoDigitLength(t *testing.T) {
+	qr := Encode(TransferInfo{
+		BankBin:        "970436",
+		AccountNumber:  "1234567890",
+		Message:        strings.Repeat("M", 50),
+		BillNumber:     strings.Repeat("B", 25),
+		StoreLabel:     strings.Repeat("S", 25),
+		TerminalLabel:  strings.Repeat("T", 25),
+		ReferenceLabel: strings.Repeat("R", 25),
+	})
+
+	if errs := Validate(qr); errs != nil {
+		t.Errorf("Encode() produced invalid payload: %v", errs)
+	}
+}
+
+func TestValidateAccountNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		bin     string
+		account string
+		want    error
+	}{
+		{"vietcombank", "970436", "1234567890123", nil},
+		{"vietcombank too long", "970436", "12345678901234", ErrAccountLength},
+		{"vietcombank letters", "970436", "12345ABC90", ErrAccountCharset},
+		{"bidv virtual account", "970418", "96247ABC12345678", nil},
+		{"momo phone", "971025", "0912345678", nil},
+		{"momo landline", "971025", "0241234567", ErrInvalidPhoneNumber},
+		{"viettelmoney short", "971005", "091234567", ErrInvalidPhoneNumber},
+		{"unknown bank", "970400", "ABC123", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateAccountNumber(tt.bin, tt.account); !errors.Is(err, tt.want) {
+				t.Errorf("ValidateAccountNumber(%s, %s) = %v, want %v", tt.bin, tt.account, err, tt.want)
+			}
+		})
+	}
+
+	if got := NormalizeAccountNumber("971025", "+84 912 345 678"); got != "0912345678" {
+		t.Errorf("NormalizeAccountNumber() = %q, want 0912345678", got)
+	}
+	if got := NormalizeAccountNumber("970436", "0123-456 789"); got != "0123456789" {
+		t.Errorf("NormalizeAccountNumber() = %q, want 0123456789", got)
+	}
+}
@@ -0,0 +1,151 @@
+package vietqr
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextPolicy controls how characters without an ASCII equivalent are handled
+// when free-text fields are converted for tags 59, 60 and 62
+type TextPolicy int
+
+const (
+	TextReject  TextPolicy = iota // Fail with ErrInvalidCharset (EncodeStrict only; Encode replaces)
+	TextReplace                   // Replace each unsupported character with a space
+	TextStrip                     // Drop unsupported characters
+)
+
+// ErrInvalidTextPolicy is returned for an unknown policy name
+var ErrInvalidTextPolicy = errors.New("text policy must be reject, replace or strip")
+
+// ParseTextPolicy maps a policy name to a TextPolicy. Empty input means reject.
+func ParseTextPolicy(s string) (TextPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "reject", "strict":
+		return TextReject, nil
+	case "replace":
+		return TextReplace, nil
+	case "strip", "remove":
+		return TextStrip, nil
+	}
+	return TextReject, ErrInvalidTextPolicy
+}
+
+// String returns the policy name
+func (p TextPolicy) String() string {
+	switch p {
+	case TextReplace:
+		return "replace"
+	case TextStrip:
+		return "strip"
+	}
+	return "reject"
+}
+
+// NormalizeText converts s to the printable ASCII that EMVCo "ans" fields
+// allow. Input in any Unicode normalization form is accepted: precomposed
+// and combining-mark Vietnamese both fold to plain letters, typographic
+// punctuation maps to its ASCII form, and runs of whitespace (including
+// non-breaking and other Unicode spaces) collapse to a single space.
+// Characters with no equivalent are handled according to policy.
+func NormalizeText(s string, policy TextPolicy) (string, error) {
+	var sb strings.Builder
+	sb.Grow(len(s))
+
+	// NFKD splits letters from their diacritics and expands compatibility
+	// forms (full-width letters, ligatures, ellipsis) into plain characters
+	if !isASCII(s) {
+		s = norm.NFKD.String(s)
+	}
+
+	space := false
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf && r > 0x20 && r < 0x7F:
+			// Printable ASCII
+		case unicode.IsSpace(r) || r == 0x20:
+			space = sb.Len() > 0
+			continue
+		case unicode.Is(unicode.Mn, r):
+			// Combining mark left over from decomposition (accents, horn, breve)
+			continue
+		default:
+			if mapped, ok := asciiFallback[r]; ok {
+				if space {
+					sb.WriteByte(' ')
+					space = false
+				}
+				sb.WriteString(mapped)
+				continue
+			}
+			switch policy {
+			case TextStrip:
+				continue
+			case TextReject:
+				return "", ErrInvalidCharset
+			}
+			space = sb.Len() > 0
+			continue
+		}
+
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String(), nil
+}
+
+// NormalizeUnicode prepares a UTF-8 field (tag 64) for encoding: it composes
+// the text to NFC, so decomposed input from macOS/iOS keyboards takes the
+// same bytes as precomposed input, and collapses whitespace
+func NormalizeUnicode(s string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(s)), " ")
+}
+
+// toASCII converts a free-text field for the encoder, which cannot fail:
+// under TextReject unsupported characters are replaced instead
+func toASCII(s string, policy TextPolicy) string {
+	if policy == TextReject {
+		policy = TextReplace
+	}
+	out, _ := NormalizeText(s, policy)
+	return out
+}
+
+// isASCII reports whether s only contains ASCII bytes
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// asciiFallback maps characters that Unicode decomposition leaves intact
+// to their closest ASCII spelling
+var asciiFallback = map[rune]string{
+	// Letters without a canonical decomposition
+	'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D",
+	'ø': "o", 'Ø': "O", 'ł': "l", 'Ł': "L",
+	'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ß': "ss",
+
+	// Quotes and apostrophes
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'",
+	'“': "\"", '”': "\"", '„': "\"", '‟': "\"", '″': "\"",
+	'«': "\"", '»': "\"", '‹': "'", '›': "'",
+
+	// Dashes and hyphens
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '―': "-", '−': "-",
+
+	// Other punctuation and symbols
+	'•': "*", '·': ".", '×': "x", '÷': "/", '№': "No", '°': "o",
+	'€': "EUR", '£': "GBP", '¥': "JPY", '₫': "d",
+}
@@ -0,0 +1,57 @@
+package vietqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		policy TextPolicy
+		want   string
+		err    error
+	}{
+		{"precomposed", "Cà phê Đà Lạt", TextReject, "Ca phe Da Lat", nil},
+		{"decomposed", "Ca\u0300 phe\u0302 Đa\u0300 La\u0323t", TextReject, "Ca phe Da Lat", nil},
+		{"smart quotes", "“Quán Ốc” – Bà Ba’s", TextReject, "\"Quan Oc\" - Ba Ba's", nil},
+		{"unicode spaces", "  Hà Nội  \t", TextReject, "Ha Noi", nil},
+		{"full width", "ＶＩＥＴ　ＱＲ", TextReject, "VIET QR", nil},
+		{"emoji reject", "Cafe ☕ sáng", TextReject, "", ErrInvalidCharset},
+		{"emoji replace", "Cafe ☕ sáng", TextReplace, "Cafe sang", nil},
+		{"emoji strip", "Cafe☕sáng", TextStrip, "Cafesang", nil},
+		{"cjk replace", "Shop 中文", TextReplace, "Shop", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeText(tt.input, tt.policy)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NormalizeText() error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeNormalizesDecomposedInput(t *testing.T) {
+	base := TransferInfo{BankBin: "970436", AccountNumber: "1234567890", Message: "Thanh toán"}
+	decomposed := base
+	decomposed.Message = "Thanh toa\u0301n"
+
+	if Encode(base) != Encode(decomposed) {
+		t.Error("Encode() differs between NFC and NFD input")
+	}
+
+	decomposed.MerchantName = "Cafe ☕"
+	if _, err := EncodeStrict(decomposed); !errors.Is(err, ErrInvalidCharset) {
+		t.Errorf("EncodeStrict() error = %v, want ErrInvalidCharset", err)
+	}
+	decomposed.TextPolicy = TextStrip
+	if _, err := EncodeStrict(decomposed); err != nil {
+		t.Errorf("EncodeStrict() with strip policy error = %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+package vietqr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TLVNode is a single Tag-Length-Value element of an EMVCo payload
+type TLVNode struct {
+	Tag      string     `json:"tag"`                // 2-digit tag ID
+	Path     string     `json:"path"`               // Dotted path from the root (e.g., "38.01.00")
+	Offset   int        `json:"offset"`             // Byte offset of the tag within the root payload
+	Value    string     `json:"value"`              // Raw value as it appeared in the payload
+	Children []*TLVNode `json:"children,omitempty"` // Parsed sub-elements when the node is a template
+}
+
+// TLVTree is the ordered list of top-level nodes of an EMVCo payload
+type TLVTree struct {
+	Nodes []*TLVNode `json:"nodes"`
+}
+
+// ParseTLV parses an EMVCo payload into a TLV tree.
+// Parsing is lenient: on a malformed field it returns the nodes read so far
+// together with a *ValidationError, so callers can still inspect the valid prefix.
+func ParseTLV(payload string) (*TLVTree, error) {
+	nodes, verr := parseTLVNodes(payload, 0, "", nil)
+	if verr != nil {
+		return &TLVTree{Nodes: nodes}, verr
+	}
+	return &TLVTree{Nodes: nodes}, nil
+}
+
+// parseTLVNodes reads consecutive TLV triplets from data.
+// base is the offset of data within the root payload and parent the path
+// of the enclosing template ("" for the root). Errors found inside nested
+// templates are appended to issues when it is non-nil; the first error at
+// this level stops parsing and is returned.
+func parseTLVNodes(data string, base int, parent string, issues *ValidationErrors) ([]*TLVNode, *ValidationError) {
+	var nodes []*TLVNode
+	pos := 0
+
+	for pos < len(data) {
+		if pos+4 > len(data) {
+			return nodes, &ValidationError{
+				Code:     CodeTruncatedField,
+				Path:     parent,
+				Offset:   base + pos,
+				Expected: "4-byte tag and length header",
+				Actual:   strconv.Itoa(len(data)-pos) + " bytes",
+			}
+		}
+
+		tag := data[pos : pos+2]
+		path := joinPath(parent, tag)
+		lengthStr := data[pos+2 : pos+4]
+		length, err := strconv.Atoi(lengthStr)
+		if err != nil || length < 0 || lengthStr[0] == '+' || lengthStr[0] == '-' {
+			return nodes, &ValidationError{
+				Code:     CodeInvalidLength,
+				Path:     path,
+				Offset:   base + pos + 2,
+				Expected: "2-digit numeric length",
+				Actual:   lengthStr,
+			}
+		}
+		if pos+4+length > len(data) {
+			return nodes, &ValidationError{
+				Code:     CodeLengthOverrun,
+				Path:     path,
+				Offset:   base + pos + 2,
+				Expected: "at most " + strconv.Itoa(len(data)-pos-4) + " bytes",
+				Actual:   strconv.Itoa(length) + " bytes",
+			}
+		}
+
+		node := &TLVNode{
+			Tag:    tag,
+			Path:   path,
+			Offset: base + pos,
+			Value:  data[pos+4 : pos+4+length],
+		}
+
+		if isTemplate(node.Path) {
+			// A template that does not parse cleanly is kept as a primitive
+			// so the raw value still round-trips untouched.
+			children, verr := parseTLVNodes(node.Value, node.Offset+4, node.Path, issues)
+			if verr == nil {
+				node.Children = children
+			} else if issues != nil {
+				*issues = append(*issues, verr)
+			}
+		}
+
+		nodes = append(nodes, node)
+		pos += 4 + length
+	}
+
+	return nodes, nil
+}
+
+// BuildTLV returns a single TLV triplet: tag, 2-digit length and value.
+// Values longer than 99 bytes are truncated.
+func BuildTLV(tag, value string) string {
+	var sb strings.Builder
+	appendTLVTo(&sb, tag, value)
+	return sb.String()
+}
+
+// isTemplate reports whether the element at path carries nested TLV data
+func isTemplate(path string) bool {
+	parent, tag := splitPath(path)
+
+	switch parent {
+	case "":
+		n, err := strconv.Atoi(tag)
+		if err != nil {
+			return false
+		}
+		// 26-51 merchant account templates, 62 additional data,
+		// 64 language template, 80-99 unreserved templates
+		return (n >= 26 && n <= 51) || n == 62 || n == 64 || (n >= 80 && n <= 99)
+	case TagMerchantAccount:
+		return tag == SubTagBeneficiaryOrg
+	case TagAdditionalData:
+		// 50-99 payment system specific templates
+		n, err := strconv.Atoi(tag)
+		return err == nil && n >= 50 && n <= 99
+	}
+
+	return false
+}
+
+// joinPath appends tag to a dotted parent path
+func joinPath(parent, tag string) string {
+	if parent == "" {
+		return tag
+	}
+	return parent + "." + tag
+}
+
+// splitPath splits a dotted path into its parent path and last tag
+func splitPath(path string) (parent, tag string) {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return "", path
+}
+
+// IsTemplate reports whether the node was parsed as a nested template
+func (n *TLVNode) IsTemplate() bool {
+	return n.Children != nil
+}
+
+// Find returns the descendant node at the given tag path, or nil
+func (n *TLVNode) Find(tags ...string) *TLVNode {
+	return findNode(n.Children, tags)
+}
+
+// Get returns the value of the descendant node at the given tag path, or ""
+func (n *TLVNode) Get(tags ...string) string {
+	if node := n.Find(tags...); node != nil {
+		return node.Value
+	}
+	return ""
+}
+
+// String re-emits the node as a TLV triplet.
+// Templates are rebuilt from their children so edits to the tree are reflected.
+func (n *TLVNode) String() string {
+	var sb strings.Builder
+	n.writeTo(&sb)
+	return sb.String()
+}
+
+// writeTo serializes the node into sb
+func (n *TLVNode) writeTo(sb *strings.Builder) {
+	value := n.Value
+	if n.Children != nil {
+		value = serializeNodes(n.Children)
+	}
+	appendTLVTo(sb, n.Tag, value)
+}
+
+// Set replaces the value of the direct child with the given tag, or inserts
+// a new primitive child in tag order. It returns the child.
+func (n *TLVNode) Set(tag, value string) *TLVNode {
+	if n.Children == nil {
+		n.Children = []*TLVNode{}
+	}
+	return setNode(&n.Children, n.Path, tag, value)
+}
+
+// Remove deletes the direct child with the given tag and reports whether it existed
+func (n *TLVNode) Remove(tag string) bool {
+	return removeNode(&n.Children, tag)
+}
+
+// Set replaces the value of the top-level node with the given tag, or
+// inserts a new one in tag order (always ahead of the CRC). It returns the node.
+// Offsets of nodes are not updated by edits.
+func (t *TLVTree) Set(tag, value string) *TLVNode {
+	return setNode(&t.Nodes, "", tag, value)
+}
+
+// Remove deletes the top-level node with the given tag and reports whether it existed
+func (t *TLVTree) Remove(tag string) bool {
+	return removeNode(&t.Nodes, tag)
+}
+
+// Find returns the node at the given tag path (e.g., "38", "01", "00"), or nil
+func (t *TLVTree) Find(tags ...string) *TLVNode {
+	return findNode(t.Nodes, tags)
+}
+
+// Get returns the value of the node at the given tag path, or ""
+func (t *TLVTree) Get(tags ...string) string {
+	if node := t.Find(tags...); node != nil {
+		return node.Value
+	}
+	return ""
+}
+
+// String re-emits the whole payload. For a tree produced by ParseTLV from a
+// well-formed payload this returns the original string byte for byte.
+func (t *TLVTree) String() string {
+	return serializeNodes(t.Nodes)
+}
+
+// findNode walks nodes following the tag path
+func findNode(nodes []*TLVNode, tags []string) *TLVNode {
+	var found *TLVNode
+	for _, tag := range tags {
+		found = nil
+		for _, node := range nodes {
+			if node.Tag == tag {
+				found = node
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		nodes = found.Children
+	}
+	return found
+}
+
+// serializeNodes concatenates the TLV encoding of nodes
+func serializeNodes(nodes []*TLVNode) string {
+	var sb strings.Builder
+	sb.Grow(256)
+	for _, node := range nodes {
+		node.writeTo(&sb)
+	}
+	return sb.String()
+}
+
+// setNode updates or inserts the node with the given tag in nodes.
+// New nodes go before the first node with a higher tag, and before tag 63
+// at the root so the CRC stays last.
+func setNode(nodes *[]*TLVNode, parent, tag, value string) *TLVNode {
+	for _, node := range *nodes {
+		if node.Tag == tag {
+			node.Value = value
+			node.Children = nil
+			return node
+		}
+	}
+
+	node := &TLVNode{Tag: tag, Path: joinPath(parent, tag), Value: value}
+	pos := len(*nodes)
+	for i, other := range *nodes {
+		if other.Tag > tag || (parent == "" && other.Tag == TagCRC) {
+			pos = i
+			break
+		}
+	}
+
+	*nodes = append(*nodes, nil)
+	copy((*nodes)[pos+1:], (*nodes)[pos:])
+	(*nodes)[pos] = node
+	return node
+}
+
+// removeNode deletes the node with the given tag from nodes
+func removeNode(nodes *[]*TLVNode, tag string) bool {
+	for i, node := range *nodes {
+		if node.Tag == tag {
+			*nodes = append((*nodes)[:i], (*nodes)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package vietqr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTLVRoundTrip(t *testing.T) {
+	// Payload from a third-party issuer: MCC (52), tip indicator (55),
+	// a proprietary template (89) and an unknown 62 sub-tag (09)
+	body := tlv("00", "01") + tlv("01", "12") +
+		tlv("38", tlv("00", NAPAS_GUID)+tlv("01", tlv("00", "970422")+tlv("01", "VQRQ0001234"))+tlv("02", TransferToAccount)) +
+		tlv("52", "5812") + tlv("53", "704") + tlv("54", "100000") + tlv("55", "01") +
+		tlv("58", "VN") + tlv("59", "CUA HANG") + tlv("60", "HA NOI") +
+		tlv("62", tlv("08", "DON 123")+tlv("09", "ABCD")) +
+		tlv("89", tlv("00", "A000000775"))
+	qr := body + "6304" + CRC16StringWithTag(body)
+
+	tree, err := ParseTLV(qr)
+	if err != nil {
+		t.Fatalf("ParseTLV() error = %v", err)
+	}
+
+	if got := tree.String(); got != qr {
+		t.Errorf("round trip mismatch:\n got  %s\n want %s", got, qr)
+	}
+
+	checks := map[string][]string{
+		"5812":        {"52"},
+		"01":          {"55"},
+		"970422":      {"38", "01", "00"},
+		"VQRQ0001234": {"38", "01", "01"},
+		"QRIBFTTA":    {"38", "02"},
+		"ABCD":        {"62", "09"},
+		"A000000775":  {"89", "00"},
+	}
+	for want, path := range checks {
+		if got := tree.Get(path...); got != want {
+			t.Errorf("Get(%v) = %q, want %q", path, got, want)
+		}
+	}
+
+	node := tree.Find("38", "01", "00")
+	if node == nil || node.Path != "38.01.00" {
+		t.Fatalf("Find(38.01.00) = %+v", node)
+	}
+	if qr[node.Offset:node.Offset+2] != "00" || qr[node.Offset+4:node.Offset+10] != "970422" {
+		t.Errorf("node offset %d does not point at the tag", node.Offset)
+	}
+}
+
+func TestParseTLVMalformed(t *testing.T) {
+	tree, err := ParseTLV("0002010102115303704589")
+	if err == nil {
+		t.Fatal("ParseTLV() should fail on a truncated field")
+	}
+	if len(tree.Nodes) != 3 {
+		t.Errorf("ParseTLV() kept %d nodes, want the 3 valid ones", len(tree.Nodes))
+	}
+}
+
+func TestDecodeUsesTree(t *testing.T) {
+	qr := Encode(TransferInfo{
+		BankBin:       "970436",
+		AccountNumber: "1234567890",
+		Amount:        250000,
+		Message:       "Thanh toan",
+	})
+
+	info, err := Decode(qr)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if info.BankBin != "970436" || info.AccountNumber != "1234567890" ||
+		info.Amount != 250000 || info.Message != "Thanh toan" || !info.IsValid {
+		t.Errorf("Decode() = %+v", info)
+	}
+	if info.Tree.String() != qr {
+		t.Error("Decode() tree should re-emit the original payload")
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	// Static QR from a bank app with a bank-specific template (89) and
+	// an unknown tag 62 sub-field that must survive the edit
+	body := tlv("00", "01") + tlv("01", "11") +
+		tlv("38", tlv("00", NAPAS_GUID)+tlv("01", tlv("00", "970422")+tlv("01", "0123456789"))+tlv("02", TransferToAccount)) +
+		tlv("53", "704") + tlv("58", "VN") +
+		tlv("62", tlv("09", "ABCD")) +
+		tlv("89", tlv("00", "A000000775"))
+	original := body + "6304" + CRC16StringWithTag(body)
+
+	amount := int64(150000)
+	message := "Don hang 42"
+	dynamic := true
+	got, err := Rewrite(original, RewriteOptions{Amount: &amount, Message: &message, Dynamic: &dynamic})
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if errs := Validate(got); errs != nil {
+		t.Fatalf("Rewrite() produced invalid payload: %v", errs)
+	}
+
+	want := tlv("00", "01") + tlv("01", "12") +
+		tlv("38", tlv("00", NAPAS_GUID)+tlv("01", tlv("00", "970422")+tlv("01", "0123456789"))+tlv("02", TransferToAccount)) +
+		tlv("53", "704") + tlv("54", "150000") + tlv("58", "VN") +
+		tlv("62", tlv("08", "Don hang 42")+tlv("09", "ABCD")) +
+		tlv("89", tlv("00", "A000000775"))
+	if got[:len(got)-8] != want {
+		t.Errorf("Rewrite() =\n%s\nwant\n%s", got[:len(got)-8], want)
+	}
+
+	// Clearing the fields restores the original layout
+	zero, empty, static := int64(0), "", false
+	restored, err := Rewrite(got, RewriteOptions{Amount: &zero, Message: &empty, Dynamic: &static})
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if restored != original {
+		t.Errorf("Rewrite() = %s, want %s", restored, original)
+	}
+
+	if _, err := Rewrite(original[:20], RewriteOptions{}); err == nil {
+		t.Error("Rewrite() of a truncated payload succeeded")
+	}
+}
+
+func TestRewriteRejects(t *testing.T) {
+	body := tlv("00", "01") + tlv("01", "11") +
+		tlv("38", tlv("00", NAPAS_GUID)+tlv("01", tlv("00", "970422")+tlv("01", "0123456789"))+tlv("02", TransferToAccount)) +
+		tlv("53", "704") + tlv("58", "VN")
+	promptPay := tlv("00", "01") + tlv("01", "11") + tlv("29", tlv("00", "A000000677010111")+tlv("01", "0066812345678")) +
+		tlv("53", "764") + tlv("58", "TH")
+
+	tests := []struct {
+		name string
+		qr   string
+		want error
+		code ErrorCode
+	}{
+		{"bad CRC", body + "6304ABCD", nil, CodeCRCMismatch},
+		{"missing CRC", body, nil, CodeMissingTag},
+		{"CRC not last", body + "6304" + CRC16StringWithTag(body) + tlv("99", "X"), nil, CodeCRCNotLast},
+		{"not VietQR", promptPay + "6304" + CRC16StringWithTag(promptPay), ErrUnsupportedFormat, ""},
+	}
+
+	amount := int64(1000)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Rewrite(tt.qr, RewriteOptions{Amount: &amount})
+			if err == nil {
+				t.Fatal("Rewrite() succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Rewrite() error = %v, want %v", err, tt.want)
+			}
+			var verr *ValidationError
+			if tt.code != "" && (!errors.As(err, &verr) || verr.Code != tt.code) {
+				t.Errorf("Rewrite() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+// tlv builds a TLV triplet for test payloads
+func tlv(tag, value string) string {
+	return tag + formatLength(len(value)) + value
+}
@@ -0,0 +1,220 @@
+package vietqr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ErrorCode classifies a validation error
+type ErrorCode string
+
+// Validation error codes
+const (
+	CodeTruncatedField ErrorCode = "truncated_field" // Not enough bytes left for a tag/length header
+	CodeInvalidLength  ErrorCode = "invalid_length"  // Length field is not a 2-digit number
+	CodeLengthOverrun  ErrorCode = "length_overrun"  // Declared length runs past the end of the data
+	CodeDuplicateTag   ErrorCode = "duplicate_tag"   // Tag appears more than once at the same level
+	CodeMissingTag     ErrorCode = "missing_tag"     // Mandatory tag is absent
+	CodeCRCNotLast     ErrorCode = "crc_not_last"    // Tag 63 is not the final field
+	CodeCRCMismatch    ErrorCode = "crc_mismatch"    // CRC value does not match the payload
+	CodeInvalidValue   ErrorCode = "invalid_value"   // Value breaks a scheme rule (format, allowed values)
+)
+
+// ValidationError describes a single problem found in a QR payload
+type ValidationError struct {
+	Code     ErrorCode `json:"code"`
+	Path     string    `json:"path"`               // Dotted tag path (e.g., "38.01.00")
+	Offset   int       `json:"offset"`             // Byte offset within the payload
+	Expected string    `json:"expected,omitempty"` // What the standard requires
+	Actual   string    `json:"actual,omitempty"`   // What the payload contains
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(string(e.Code))
+	if e.Path != "" {
+		sb.WriteString(" at tag ")
+		sb.WriteString(e.Path)
+	}
+	sb.WriteString(" (offset ")
+	sb.WriteString(strconv.Itoa(e.Offset))
+	sb.WriteString(")")
+	if e.Expected != "" {
+		sb.WriteString(": expected ")
+		sb.WriteString(e.Expected)
+	}
+	if e.Actual != "" {
+		sb.WriteString(", got ")
+		sb.WriteString(e.Actual)
+	}
+	return sb.String()
+}
+
+// Unwrap maps the error code to the package's sentinel errors
+func (e *ValidationError) Unwrap() error {
+	switch e.Code {
+	case CodeTruncatedField:
+		return ErrInvalidQRString
+	case CodeInvalidLength, CodeLengthOverrun:
+		return ErrInvalidLength
+	case CodeMissingTag:
+		return ErrMissingField
+	case CodeCRCNotLast, CodeCRCMismatch:
+		return ErrInvalidCRC
+	}
+	return nil
+}
+
+// ValidationErrors is the list of problems returned by strict decoding
+type ValidationErrors []*ValidationError
+
+// Error implements the error interface
+func (e ValidationErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no validation errors"
+	case 1:
+		return e[0].Error()
+	}
+	return e[0].Error() + " (and " + strconv.Itoa(len(e)-1) + " more errors)"
+}
+
+// mandatoryTags lists the tag paths every VietQR payload must carry
+var mandatoryTags = []string{
+	TagPayloadFormat,
+	TagInitiationMethod,
+	TagMerchantAccount,
+	TagMerchantAccount + "." + SubTagGUID,
+	TagMerchantAccount + "." + SubTagBeneficiaryOrg,
+	TagMerchantAccount + "." + SubTagBeneficiaryOrg + "." + SubTagAcquirerID,
+	TagMerchantAccount + "." + SubTagBeneficiaryOrg + "." + SubTagConsumerID,
+	TagCurrency,
+	TagCountry,
+	TagCRC,
+}
+
+// Validate checks a QR payload against the EMVCo/NAPAS structure rules and
+// returns every problem found, or nil if the payload is well formed
+func Validate(qrString string) ValidationErrors {
+	return ValidatePayload(qrString, mandatoryTags)
+}
+
+// ValidatePayload checks the EMVCo structure of any merchant-presented QR
+// payload (TLV layout, duplicate tags, CRC) and requires every tag path in
+// mandatory, so other payment schemes can supply their own list
+func ValidatePayload(qrString string, mandatory []string) ValidationErrors {
+	var errs ValidationErrors
+
+	nodes, verr := parseTLVNodes(qrString, 0, "", &errs)
+	if verr != nil {
+		errs = append(errs, verr)
+	}
+	tree := &TLVTree{Nodes: nodes}
+
+	errs = append(errs, checkDuplicates(tree.Nodes)...)
+
+	for _, path := range mandatory {
+		parent, _ := splitPath(path)
+		// Sub-tags are only reported when their template parsed,
+		// so a missing or broken 38 yields one error rather than five
+		if parent != "" {
+			if node := tree.Find(strings.Split(parent, ".")...); node == nil || !node.IsTemplate() {
+				continue
+			}
+		}
+		if tree.Find(strings.Split(path, ".")...) == nil {
+			errs = append(errs, &ValidationError{
+				Code:     CodeMissingTag,
+				Path:     path,
+				Offset:   missingTagOffset(tree, parent),
+				Expected: "tag " + path,
+			})
+		}
+	}
+
+	errs = append(errs, checkCRC(qrString, tree)...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// checkDuplicates reports tags that occur more than once at the same level
+func checkDuplicates(nodes []*TLVNode) ValidationErrors {
+	var errs ValidationErrors
+	seen := make(map[string]*TLVNode, len(nodes))
+
+	for _, node := range nodes {
+		if first, ok := seen[node.Tag]; ok {
+			errs = append(errs, &ValidationError{
+				Code:     CodeDuplicateTag,
+				Path:     node.Path,
+				Offset:   node.Offset,
+				Expected: "single occurrence",
+				Actual:   "already defined at offset " + strconv.Itoa(first.Offset),
+			})
+			continue
+		}
+		seen[node.Tag] = node
+		errs = append(errs, checkDuplicates(node.Children)...)
+	}
+
+	return errs
+}
+
+// checkCRC verifies that tag 63 is the last field and matches the payload
+func checkCRC(qrString string, tree *TLVTree) ValidationErrors {
+	crcNode := tree.Find(TagCRC)
+	if crcNode == nil {
+		return nil
+	}
+
+	last := tree.Nodes[len(tree.Nodes)-1]
+	if last != crcNode {
+		return ValidationErrors{{
+			Code:     CodeCRCNotLast,
+			Path:     TagCRC,
+			Offset:   crcNode.Offset,
+			Expected: "tag 63 as the final field",
+			Actual:   "followed by tag " + last.Tag,
+		}}
+	}
+
+	if len(crcNode.Value) != 4 {
+		return ValidationErrors{{
+			Code:     CodeCRCMismatch,
+			Path:     TagCRC,
+			Offset:   crcNode.Offset + 4,
+			Expected: "4 hex digits",
+			Actual:   crcNode.Value,
+		}}
+	}
+
+	expected := CRC16String(qrString[:crcNode.Offset+4])
+	if !strings.EqualFold(expected, crcNode.Value) {
+		return ValidationErrors{{
+			Code:     CodeCRCMismatch,
+			Path:     TagCRC,
+			Offset:   crcNode.Offset + 4,
+			Expected: expected,
+			Actual:   crcNode.Value,
+		}}
+	}
+
+	return nil
+}
+
+// missingTagOffset points a missing-tag error at the end of its parent
+func missingTagOffset(tree *TLVTree, parent string) int {
+	if parent == "" {
+		if len(tree.Nodes) == 0 {
+			return 0
+		}
+		last := tree.Nodes[len(tree.Nodes)-1]
+		return last.Offset + 4 + len(last.Value)
+	}
+	node := tree.Find(strings.Split(parent, ".")...)
+	return node.Offset + 4 + len(node.Value)
+}